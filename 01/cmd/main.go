@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	day01 "github.com/usedbytes/aoc2021/01"
+	"github.com/usedbytes/aoc2021/input"
+)
+
+func run() error {
+	f, err := input.Open(os.Args[1])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	depths, err := day01.Parse(f)
+	if err != nil {
+		return err
+	}
+
+	// An explicit window size overrides the two parts
+	if len(os.Args) > 2 {
+		windowSize, err := strconv.Atoi(os.Args[2])
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(day01.CountIncreases(depths, windowSize))
+
+		return nil
+	}
+
+	part1, err := day01.Part1(depths)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Part 1:", part1)
+
+	part2, err := day01.Part2(depths)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Part 2:", part2)
+
+	return nil
+}
+
+func main() {
+	err := run()
+	if err != nil {
+		fmt.Println("ERROR:", err)
+		os.Exit(1)
+	}
+}
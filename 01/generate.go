@@ -0,0 +1,29 @@
+package day01
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+)
+
+// Generate writes 'size' depths (2000 by default), which mostly get deeper
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 2000
+	}
+
+	bw := bufio.NewWriter(w)
+
+	depth := 100 + rnd.Intn(100)
+	for i := 0; i < size; i++ {
+		fmt.Fprintln(bw, depth)
+
+		depth += rnd.Intn(21) - 5
+		if depth < 0 {
+			depth = -depth
+		}
+	}
+
+	return bw.Flush()
+}
@@ -1,61 +1,30 @@
-package main
+package day01
 
 import (
-	"bufio"
-	"fmt"
-	"os"
-	"strconv"
-)
-
-func doLines(filename string, do func(line string) error) error {
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
+	"io"
 
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if err := do(line); err != nil {
-			return err
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return err
-	}
+	"github.com/usedbytes/aoc2021/input"
+)
 
-	return nil
+// Parse reads one depth measurement per line
+func Parse(rd io.Reader) ([]int, error) {
+	return input.Ints(rd)
 }
 
-func run() error {
-	var err error
-
+// CountIncreases returns the number of times the sum of a sliding window of
+// 'windowSize' measurements is larger than the previous sum
+func CountIncreases(depths []int, windowSize int) int {
 	lastSum := -1
 	deeper := 0
 
-	windowSize := 1
-	if len(os.Args) > 2 {
-		windowSize, err = strconv.Atoi(os.Args[2])
-		if err != nil {
-			return err
-		}
-	}
-
 	window := make([]int, windowSize)
 	idx := 0
 
-	if err := doLines(os.Args[1], func(line string) error {
-		this, err := strconv.Atoi(line)
-		if err != nil {
-			return err
-		}
-
+	for _, this := range depths {
 		window[idx % windowSize] = this
 		idx++
 		if idx < windowSize {
-			return nil
+			continue
 		}
 
 		thisSum := 0
@@ -67,21 +36,15 @@ func run() error {
 			deeper++
 		}
 		lastSum = thisSum
-
-		return nil
-	}); err != nil {
-		return err
 	}
 
-	fmt.Println(deeper)
+	return deeper
+}
 
-	return nil
+func Part1(depths []int) (int, error) {
+	return CountIncreases(depths, 1), nil
 }
 
-func main() {
-	err := run()
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
+func Part2(depths []int) (int, error) {
+	return CountIncreases(depths, 3), nil
 }
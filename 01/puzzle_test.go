@@ -0,0 +1,19 @@
+package day01
+
+import (
+	"testing"
+
+	"github.com/usedbytes/aoc2021/golden"
+)
+
+func TestGolden(t *testing.T) {
+	golden.Test(t, 1)
+}
+
+func TestGenerated(t *testing.T) {
+	golden.Generated(t, 1, 0, 5)
+}
+
+func BenchmarkInput(b *testing.B) {
+	golden.Benchmark(b, 1)
+}
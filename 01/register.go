@@ -0,0 +1,12 @@
+package day01
+
+import "github.com/usedbytes/aoc2021/solver"
+
+func init() {
+	s := solver.Register(1, Parse, Part1, Part2)
+	solver.AddOption(s, "window", "count increases of a sliding window of this many measurements", 1,
+		func(depths []int, windowSize int) (int, error) {
+			return CountIncreases(depths, windowSize), nil
+		})
+	s.Generate = Generate
+}
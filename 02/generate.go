@@ -0,0 +1,39 @@
+package day02
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+)
+
+// Generate writes 'size' commands (1000 by default). The submarine never
+// goes above the surface.
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 1000
+	}
+
+	bw := bufio.NewWriter(w)
+
+	depth := 0
+	for i := 0; i < size; i++ {
+		n := 1 + rnd.Intn(9)
+
+		dir := []string{"forward", "down", "up"}[rnd.Intn(3)]
+		if dir == "up" && n > depth {
+			dir = "down"
+		}
+
+		switch dir {
+		case "down":
+			depth += n
+		case "up":
+			depth -= n
+		}
+
+		fmt.Fprintln(bw, dir, n)
+	}
+
+	return bw.Flush()
+}
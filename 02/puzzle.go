@@ -1,33 +1,17 @@
-package main
+package day02
 
 import (
-	"bufio"
 	"fmt"
-	"os"
+	"io"
 	"strconv"
 	"strings"
-)
-
-func doLines(filename string, do func(line string) error) error {
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if err := do(line); err != nil {
-			return err
-		}
-	}
 
-	if err := scanner.Err(); err != nil {
-		return err
-	}
+	"github.com/usedbytes/aoc2021/input"
+)
 
-	return nil
+type Command struct {
+	Cmd string
+	Arg int
 }
 
 type State struct {
@@ -59,38 +43,52 @@ func (s *State) MutatePart2(cmd string, arg int) {
 	}
 }
 
-func run() error {
+func Parse(rd io.Reader) ([]Command, error) {
+	var cmds []Command
 
-	var s State
+	if err := input.DoLines(rd, func(line string) error {
+		cmd, val, ok := strings.Cut(line, " ")
+		if !ok {
+			return fmt.Errorf("expected a command and a number, got %q", line)
+		}
 
-	if err := doLines(os.Args[1], func(line string) error {
-		parts := strings.Split(line, " ")
+		switch cmd {
+		case "forward", "up", "down":
+		default:
+			return input.At(1, fmt.Errorf("unknown command %q", cmd))
+		}
 
-		arg, err := strconv.Atoi(parts[1])
+		arg, err := strconv.Atoi(val)
 		if err != nil {
-			return err
+			return input.At(len(cmd)+2, err)
 		}
 
-		if len(os.Args) > 2 {
-			s.MutatePart2(parts[0], arg)
-		} else {
-			s.Mutate(parts[0], arg)
-		}
+		cmds = append(cmds, Command{cmd, arg})
 
 		return nil
 	}); err != nil {
-		return err
+		return nil, err
 	}
 
-	fmt.Println(s.Horizontal * s.Depth)
+	return cmds, nil
+}
+
+func Part1(cmds []Command) (int, error) {
+	var s State
+
+	for _, c := range cmds {
+		s.Mutate(c.Cmd, c.Arg)
+	}
 
-	return nil
+	return s.Horizontal * s.Depth, nil
 }
 
-func main() {
-	err := run()
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
+func Part2(cmds []Command) (int, error) {
+	var s State
+
+	for _, c := range cmds {
+		s.MutatePart2(c.Cmd, c.Arg)
 	}
+
+	return s.Horizontal * s.Depth, nil
 }
@@ -0,0 +1,27 @@
+package day02
+
+import (
+	"testing"
+
+	"github.com/usedbytes/aoc2021/golden"
+)
+
+func TestGolden(t *testing.T) {
+	golden.Test(t, 2)
+}
+
+func TestGenerated(t *testing.T) {
+	golden.Generated(t, 2, 0, 5)
+}
+
+func TestParseErrors(t *testing.T) {
+	golden.ParseErrors(t, 2, []golden.BadInput{
+		{Name: "no number", Input: "forward 5\ndown\n", Line: 2, Column: 0},
+		{Name: "bad command", Input: "forward 5\nback 3\n", Line: 2, Column: 1},
+		{Name: "bad number", Input: "forward 5\ndown x\n", Line: 2, Column: 6},
+	})
+}
+
+func BenchmarkInput(b *testing.B) {
+	golden.Benchmark(b, 2)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	day03 "github.com/usedbytes/aoc2021/03"
+	"github.com/usedbytes/aoc2021/input"
+)
+
+func run() error {
+	f, err := input.Open(os.Args[1])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	in, err := day03.Parse(f)
+	if err != nil {
+		return err
+	}
+
+	part1, err := day03.Part1(in)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Part 1:", part1)
+
+	part2, err := day03.Part2(in)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Part 2:", part2)
+
+	return nil
+}
+
+func main() {
+	err := run()
+	if err != nil {
+		fmt.Println("ERROR:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,39 @@
+package day03
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+)
+
+// Generate writes 'size' distinct binary numbers (1000 by default). They're
+// 12 bits wide, or wider if that isn't enough to make them distinct.
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 1000
+	}
+
+	width := 12
+	for 1<<width < 2*size {
+		width++
+	}
+	if width > 32 {
+		return fmt.Errorf("too many numbers: %d", size)
+	}
+
+	bw := bufio.NewWriter(w)
+
+	seen := make(map[int64]bool)
+	for len(seen) < size {
+		v := rnd.Int63n(1 << width)
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+
+		fmt.Fprintf(bw, "%0*b\n", width, v)
+	}
+
+	return bw.Flush()
+}
@@ -1,37 +1,25 @@
-package main
+package day03
 
 import (
-	"bufio"
 	"fmt"
-	"os"
+	"io"
 	"strconv"
 	"strings"
-)
 
-func doLines(filename string, do func(line string) error) error {
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
+	"github.com/usedbytes/aoc2021/input"
+)
 
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if err := do(line); err != nil {
-			return err
+// Not every line is the same length (one in input.txt is short), so there's
+// a count for each bit of the longest
+func countOnes(lines []string) []int {
+	longest := 0
+	for _, line := range lines {
+		if len(line) > longest {
+			longest = len(line)
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-func countOnes(lines []string) []int {
-	counts := make([]int, len(lines[0]))
+	counts := make([]int, longest)
 
 	for _, line := range lines {
 		for i, c := range line {
@@ -55,7 +43,9 @@ func filter(in []string, prefix string) []string {
 	return out
 }
 
-func decode(lines []string, rule func(current string, ones, zeroes int) string, filter func(lines []string, prefix string) []string) string {
+// Returns an error if filtering leaves no lines, which can happen when some
+// lines are shorter than the others
+func decode(lines []string, rule func(current string, ones, zeroes int) string, filter func(lines []string, prefix string) []string) (string, error) {
 	result := ""
 
 	counts := countOnes(lines)
@@ -68,75 +58,106 @@ func decode(lines []string, rule func(current string, ones, zeroes int) string,
 
 		if filter != nil {
 			lines = filter(lines, result)
-			if len(lines) <= 1 {
-				return lines[0]
+			if len(lines) == 0 {
+				return "", fmt.Errorf("no numbers start with %s", result)
+			} else if len(lines) == 1 {
+				return lines[0], nil
 			}
 			counts = countOnes(lines)
 		}
 	}
 
-	return result
+	return result, nil
 }
 
-func run() error {
+func mostCommonRule(current string, ones, zeroes int) string {
+	if ones >= zeroes {
+		return current + "1"
+	} else {
+		return current + "0"
+	}
+}
+
+// If every line has the same bit, that's the only choice
+func leastCommonRule(current string, ones, zeroes int) string {
+	if (ones >= zeroes && zeroes > 0) || ones == 0 {
+		return current + "0"
+	} else {
+		return current + "1"
+	}
+}
+
+// Each number must fit in 32 bits
+const maxBits = 32
+
+func Parse(rd io.Reader) ([]string, error) {
 	var lines []string
 
-	if err := doLines(os.Args[1], func(line string) error {
-		lines = append(lines, line)
+	if err := input.DoLines(rd, func(line string) error {
+		for i, c := range line {
+			if c != '0' && c != '1' {
+				return input.At(i+1, fmt.Errorf("expected 0 or 1, got %q", c))
+			}
+		}
+
+		if len(line) > maxBits {
+			return input.At(maxBits+1, fmt.Errorf("numbers can be at most %d bits", maxBits))
+		} else if len(line) > 0 {
+			lines = append(lines, line)
+		}
+
 		return nil
 	}); err != nil {
-		return err
+		return nil, err
 	}
 
-	mostCommonRule := func(current string, ones, zeroes int) string {
-		if ones >= zeroes {
-			return current + "1"
-		} else {
-			return current + "0"
-		}
+	if len(lines) == 0 {
+		return nil, &input.ParseError{Line: 1, Err: io.ErrUnexpectedEOF}
 	}
 
-	leastCommonRule := func(current string, ones, zeroes int) string {
-		if ones >= zeroes {
-			return current + "0"
-		} else {
-			return current + "1"
-		}
-	}
+	return lines, nil
+}
 
-	gammaRate := decode(lines, mostCommonRule, nil)
+// Part1 returns the power consumption
+func Part1(lines []string) (int, error) {
+	gammaRate, err := decode(lines, mostCommonRule, nil)
+	if err != nil {
+		return 0, err
+	}
 
 	i64, err := strconv.ParseUint(gammaRate, 2, 32)
 	if err != nil {
-		return err
+		return 0, err
 	}
 	gamma := int(i64)
 	epsilon := (^gamma) & ((1 << len(gammaRate)) - 1)
-	fmt.Println("Power consumption:", gamma * epsilon)
 
-	oxygen := decode(lines, mostCommonRule, filter)
-	i64, err = strconv.ParseUint(oxygen, 2, 32)
+	return gamma * epsilon, nil
+}
+
+// Part2 returns the life support rating
+func Part2(lines []string) (int, error) {
+	oxygen, err := decode(lines, mostCommonRule, filter)
 	if err != nil {
-		return err
+		return 0, err
 	}
-	oxygenRating := int(i64)
 
-	co2 := decode(lines, leastCommonRule, filter)
-	i64, err = strconv.ParseUint(co2, 2, 32)
+	i64, err := strconv.ParseUint(oxygen, 2, 32)
 	if err != nil {
-		return err
+		return 0, err
 	}
-	co2Rating := int(i64)
-
-	fmt.Println("Life Support Rating:", oxygenRating * co2Rating)
+	oxygenRating := int(i64)
 
-	return nil
-}
+	co2, err := decode(lines, leastCommonRule, filter)
+	if err != nil {
+		return 0, err
+	}
 
-func main() {
-	err := run()
+	i64, err = strconv.ParseUint(co2, 2, 32)
 	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
+		return 0, err
 	}
+	co2Rating := int(i64)
+
+	return oxygenRating * co2Rating, nil
 }
@@ -0,0 +1,28 @@
+package day03
+
+import (
+	"testing"
+
+	"github.com/usedbytes/aoc2021/golden"
+)
+
+func TestGolden(t *testing.T) {
+	golden.Test(t, 3)
+}
+
+func TestGenerated(t *testing.T) {
+	golden.Generated(t, 3, 0, 5)
+}
+
+func TestParseErrors(t *testing.T) {
+	golden.ParseErrors(t, 3, []golden.BadInput{
+		{Name: "empty", Input: "", Line: 1, Column: 0},
+		{Name: "not binary", Input: "00100\n11210\n", Line: 2, Column: 3},
+		{Name: "blank lines", Input: "\n\n", Line: 1, Column: 0},
+		{Name: "too long", Input: "000000000000000000000000000000001\n", Line: 1, Column: 33},
+	})
+}
+
+func BenchmarkInput(b *testing.B) {
+	golden.Benchmark(b, 3)
+}
@@ -0,0 +1,37 @@
+package day04
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+)
+
+// Generate writes a game with 'size' boards (100 by default). Every number
+// from 0 to 99 is drawn, so every board wins eventually.
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 100
+	}
+
+	bw := bufio.NewWriter(w)
+
+	var moves []string
+	for _, n := range rnd.Perm(100) {
+		moves = append(moves, fmt.Sprint(n))
+	}
+	fmt.Fprintln(bw, strings.Join(moves, ","))
+
+	for i := 0; i < size; i++ {
+		fmt.Fprintln(bw)
+
+		numbers := rnd.Perm(100)
+		for row := 0; row < 5; row++ {
+			fmt.Fprintf(bw, "%2d %2d %2d %2d %2d\n", numbers[row*5], numbers[row*5+1],
+				numbers[row*5+2], numbers[row*5+3], numbers[row*5+4])
+		}
+	}
+
+	return bw.Flush()
+}
@@ -1,12 +1,12 @@
-package main
+package day04
 
 import (
-	"bufio"
 	"fmt"
 	"io"
-	"os"
 	"strconv"
 	"strings"
+
+	"github.com/usedbytes/aoc2021/input"
 )
 
 type Board struct {
@@ -15,39 +15,32 @@ type Board struct {
 	RowMarked [5]int
 }
 
-func NewBoard(rd *bufio.Reader) (*Board, error) {
-	var b Board
+type Game struct {
+	Moves  []int
+	Boards []*Board
+}
 
-	i := 0
-	for i < 5 {
-		line, err := rd.ReadString('\n')
-		if err != nil {
-			return nil, err
-		}
+func NewBoard(lines []string) (*Board, error) {
+	var b Board
 
-		line = strings.TrimSpace(line)
+	if len(lines) != 5 {
+		return nil, fmt.Errorf("expected 5 rows, got %d", len(lines))
+	}
 
-		if len(line) == 0 {
-			continue
+	for i, line := range lines {
+		strs := strings.Fields(line)
+		if len(strs) != 5 {
+			return nil, input.AtLine(i+1, 0, fmt.Errorf("expected 5 numbers, got %d", len(strs)))
 		}
 
-		strs := strings.Split(line, " ")
-		j := 0
-		for _, s := range strs {
-			if len(s) == 0 {
-				continue
-			}
-
+		for j, s := range strs {
 			n, err := strconv.Atoi(s)
 			if err != nil {
-				return nil, err
+				return nil, input.AtLine(i+1, 0, err)
 			}
 
 			b.Numbers[i][j] = n
-			j++
 		}
-
-		i++
 	}
 
 	return &b, nil
@@ -86,53 +79,48 @@ func (b *Board) Mark(n int) (bool, int) {
 	return false, 0
 }
 
-func run() error {
-	filename := os.Args[1]
-
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	rd := bufio.NewReader(f)
-
-	// Decode moves
-	movesLine, err := rd.ReadString('\n')
-	if err != nil {
-		return err
-	}
-	movesLine = strings.TrimSpace(movesLine)
-	moveStrings := strings.Split(movesLine, ",")
-	moves := make([]int, len(moveStrings))
+func Parse(rd io.Reader) (*Game, error) {
+	var moves []int
+	var boards []*Board
 
-	for i, m := range moveStrings {
-		n, err := strconv.Atoi(m)
-		if err != nil {
+	if err := input.DoBlocks(rd, func(block []string) error {
+		// Decode moves
+		if moves == nil {
+			var err error
+			moves, err = input.SplitInts(block[0], ",")
 			return err
 		}
 
-		moves[i] = n
-	}
-
-	// Decode Boards
-	var boards []*Board
-	var b *Board
-	for {
-		b, err = NewBoard(rd)
+		// Decode Boards
+		b, err := NewBoard(block)
 		if err != nil {
-			break
+			return err
 		}
 		boards = append(boards, b)
+
+		return nil
+	}); err != nil {
+		return nil, err
 	}
 
-	if err != io.EOF {
-		return err
+	return &Game{
+		Moves:  moves,
+		Boards: boards,
+	}, nil
+}
+
+// Play the game on a copy of the boards, returning the final scores of the
+// first and the last boards to win
+func (g *Game) Play() (int, int) {
+	boards := make([]*Board, len(g.Boards))
+	for i, b := range g.Boards {
+		nb := *b
+		boards[i] = &nb
 	}
 
-	// Play the game
+	first, last := 0, 0
 	numWins := 0
-	for _, m := range moves {
+	for _, m := range g.Moves {
 		for i, b := range boards {
 			if b == nil {
 				continue
@@ -143,22 +131,25 @@ func run() error {
 				boards[i] = nil
 				numWins++
 				if numWins == 1 {
-					fmt.Println("Part 1:", score * m)
-				} else if numWins == len(boards) {
-					fmt.Println("Part 2:", score * m)
-					return nil
+					first = score * m
+				}
+				if numWins == len(boards) {
+					last = score * m
+					return first, last
 				}
 			}
 		}
 	}
 
-	return nil
+	return first, last
 }
 
-func main() {
-	err := run()
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
+func Part1(g *Game) (int, error) {
+	first, _ := g.Play()
+	return first, nil
+}
+
+func Part2(g *Game) (int, error) {
+	_, last := g.Play()
+	return last, nil
 }
@@ -0,0 +1,19 @@
+package day04
+
+import (
+	"testing"
+
+	"github.com/usedbytes/aoc2021/golden"
+)
+
+func TestGolden(t *testing.T) {
+	golden.Test(t, 4)
+}
+
+func TestGenerated(t *testing.T) {
+	golden.Generated(t, 4, 0, 5)
+}
+
+func BenchmarkInput(b *testing.B) {
+	golden.Benchmark(b, 4)
+}
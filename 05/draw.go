@@ -0,0 +1,43 @@
+package day05
+
+import (
+	"image/color"
+	"io"
+
+	"github.com/usedbytes/aoc2021/grid"
+	"github.com/usedbytes/aoc2021/visual"
+)
+
+// Draw writes a PNG heatmap of how many lines cross each point, including
+// the diagonal ones. Anything over 5 is the same colour as 5.
+func Draw(w io.Writer, lines []Line, scale int) error {
+	if scale <= 0 {
+		scale = 1
+	}
+
+	chart := Chart(lines, true)
+
+	// The coordinates are never negative
+	var origin, corner grid.Point
+	for c := range chart {
+		if c.X > corner.X {
+			corner.X = c.X
+		}
+		if c.Y > corner.Y {
+			corner.Y = c.Y
+		}
+	}
+
+	palette := append(color.Palette{color.RGBA{0x08, 0x08, 0x18, 0xff}},
+		visual.Gradient(color.RGBA{0x40, 0x20, 0x80, 0xff}, color.RGBA{0xff, 0xe0, 0x40, 0xff}, 5)...)
+
+	img := visual.Canvas(origin, corner, scale, palette)
+	for c, n := range chart {
+		if n > 5 {
+			n = 5
+		}
+		visual.Cell(img, origin, grid.Point{X: c.X, Y: c.Y}, scale, uint8(n))
+	}
+
+	return visual.WritePNG(w, img)
+}
@@ -0,0 +1,57 @@
+package day05
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+)
+
+// Generate writes 'size' lines of vents (500 by default), which are
+// horizontal, vertical or at 45 degrees, in a 1000x1000 area
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 500
+	}
+
+	const area = 1000
+
+	bw := bufio.NewWriter(w)
+
+	for i := 0; i < size; i++ {
+		x1, y1 := rnd.Intn(area), rnd.Intn(area)
+
+		// Pick a direction, and then how far it can go in that direction
+		var dx, dy int
+		switch rnd.Intn(3) {
+		case 0:
+			dx = 1 - 2*rnd.Intn(2)
+		case 1:
+			dy = 1 - 2*rnd.Intn(2)
+		default:
+			dx, dy = 1-2*rnd.Intn(2), 1-2*rnd.Intn(2)
+		}
+
+		room := area - 1
+		if dx > 0 {
+			room = min(room, area-1-x1)
+		} else if dx < 0 {
+			room = min(room, x1)
+		}
+		if dy > 0 {
+			room = min(room, area-1-y1)
+		} else if dy < 0 {
+			room = min(room, y1)
+		}
+
+		if room == 0 {
+			i--
+			continue
+		}
+
+		n := 1 + rnd.Intn(room)
+		fmt.Fprintf(bw, "%d,%d -> %d,%d\n", x1, y1, x1+dx*n, y1+dy*n)
+	}
+
+	return bw.Flush()
+}
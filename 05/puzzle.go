@@ -1,37 +1,20 @@
-package main
+package day05
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-)
-
-func doLines(filename string, do func(line string) error) error {
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if err := do(line); err != nil {
-			return err
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return err
-	}
+	"io"
 
-	return nil
-}
+	"github.com/usedbytes/aoc2021/input"
+)
 
 type Coord struct {
 	X, Y int
 }
 
+type Line struct {
+	From, To Coord
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -58,12 +41,10 @@ func dir(a1, a2 int) int {
 	}
 }
 
-func run() error {
+func Parse(rd io.Reader) ([]Line, error) {
+	var lines []Line
 
-	chart := make(map[Coord]int)
-	numTwo := 0
-
-	if err := doLines(os.Args[1], func(line string) error {
+	if err := input.DoLines(rd, func(line string) error {
 		var x1, y1, x2, y2 int
 
 		n, err := fmt.Sscanf(line, "%d,%d -> %d,%d", &x1, &y1, &x2, &y2)
@@ -71,43 +52,58 @@ func run() error {
 			return err
 		}
 
-		// Only considering horizontal/vertical for Part 1
-		if len(os.Args) < 3 && (x1 != x2) && (y1 != y2) {
-			return nil
+		lines = append(lines, Line{Coord{x1, y1}, Coord{x2, y2}})
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// Chart counts the lines over each point. Diagonal lines are only included
+// if 'diagonals' is set.
+func Chart(lines []Line, diagonals bool) map[Coord]int {
+	chart := make(map[Coord]int)
+
+	for _, l := range lines {
+		x1, y1, x2, y2 := l.From.X, l.From.Y, l.To.X, l.To.Y
+
+		if !diagonals && (x1 != x2) && (y1 != y2) {
+			continue
 		}
 
 		dirX := dir(x1, x2)
 		dirY := dir(y1, y2)
 
 		for x, y := x1, y1; (x != (x2 + dirX)) || (y != (y2 + dirY)); x, y = x + dirX, y + dirY {
-			c := Coord{ x, y }
-			if v, ok := chart[c]; ok {
-				// Already have at least one line at this Coord
-				chart[c] = v + 1
-
-				// Count specifically two or more for Part 1
-				if v == 1 {
-					numTwo++
-				}
-			} else {
-				chart[c] = 1
-			}
+			chart[Coord{ x, y }]++
 		}
+	}
 
-		return nil
-	}); err != nil {
-		return err
+	return chart
+}
+
+// CountOverlaps returns the number of points where at least two lines overlap.
+// Diagonal lines are only considered if 'diagonals' is set.
+func CountOverlaps(lines []Line, diagonals bool) int {
+	numTwo := 0
+	for _, n := range Chart(lines, diagonals) {
+		// Count specifically two or more
+		if n >= 2 {
+			numTwo++
+		}
 	}
 
-	fmt.Println(numTwo)
+	return numTwo
+}
 
-	return nil
+// Part1 only considers horizontal/vertical lines
+func Part1(lines []Line) (int, error) {
+	return CountOverlaps(lines, false), nil
 }
 
-func main() {
-	err := run()
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
+func Part2(lines []Line) (int, error) {
+	return CountOverlaps(lines, true), nil
 }
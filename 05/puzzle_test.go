@@ -0,0 +1,36 @@
+package day05
+
+import (
+	"fmt"
+	"image"
+	"testing"
+
+	"github.com/usedbytes/aoc2021/golden"
+)
+
+func TestGolden(t *testing.T) {
+	golden.Test(t, 5)
+}
+
+func TestGenerated(t *testing.T) {
+	golden.Generated(t, 5, 0, 5)
+}
+
+func TestDraw(t *testing.T) {
+	// Points are coloured by how many lines cross them, up to 5, so
+	// colours 2 and up are part 2's overlaps
+	golden.Drawn(t, 5, func(t *testing.T, c golden.Case, frames []*image.Paletted) {
+		overlaps := 0
+		for n := uint8(2); n <= 5; n++ {
+			overlaps += golden.Count(frames[0], n)
+		}
+
+		if got, want := fmt.Sprint(overlaps), c.Answers["2"]; got != want {
+			t.Errorf("drew %s overlaps, want %s", got, want)
+		}
+	})
+}
+
+func BenchmarkInput(b *testing.B) {
+	golden.Benchmark(b, 5)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	day06 "github.com/usedbytes/aoc2021/06"
+	"github.com/usedbytes/aoc2021/input"
+)
+
+func run() error {
+	f, err := input.Open(os.Args[1])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	timers, err := day06.Parse(f)
+	if err != nil {
+		return err
+	}
+
+	// An explicit number of days overrides the two parts
+	if len(os.Args) > 2 {
+		days, err := strconv.Atoi(os.Args[2])
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("Total fish:", day06.Simulate(timers, days))
+
+		return nil
+	}
+
+	part1, err := day06.Part1(timers)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Part 1:", part1)
+
+	part2, err := day06.Part2(timers)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Part 2:", part2)
+
+	return nil
+}
+
+func main() {
+	err := run()
+	if err != nil {
+		fmt.Println("ERROR:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,23 @@
+package day06
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+)
+
+// Generate writes the timers of 'size' fish (300 by default)
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 300
+	}
+
+	timers := make([]string, size)
+	for i := range timers {
+		timers[i] = fmt.Sprint(1 + rnd.Intn(5))
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(timers, ","))
+	return err
+}
@@ -1,76 +1,25 @@
-package main
+package day06
 
 import (
-	"bufio"
-	"fmt"
-	"os"
-	"strconv"
-	"strings"
-)
-
-func doLines(filename string, do func(line string) error) error {
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
+	"io"
 
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if err := do(line); err != nil {
-			return err
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return err
-	}
+	"github.com/usedbytes/aoc2021/input"
+)
 
-	return nil
+func Parse(rd io.Reader) ([]int, error) {
+	return input.CommaInts(rd)
 }
 
-func run() error {
-	filename := os.Args[1]
-
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	rd := bufio.NewReader(f)
-
-	line, err := rd.ReadString('\n')
-	if err != nil {
-		return err
-	}
-
+// Simulate returns the total number of fish after 'days', starting from
+// fish with the given 'timers'
+func Simulate(timers []int, days int) int {
 	maxTime := 9 // At most we need 9 days
 	fish := make([]int, maxTime)
 
-	line = strings.TrimSpace(line)
-
-	startStrings := strings.Split(line, ",")
-	for _, s := range startStrings {
-		v, err := strconv.Atoi(s)
-		if err != nil {
-			return err
-		}
-
+	for _, v := range timers {
 		fish[v]++
 	}
 
-	days := 80
-	if len(os.Args) > 2 {
-		v, err := strconv.Atoi(os.Args[2])
-		if err != nil {
-			return err
-		}
-
-		days = v
-	}
-
 	for day := 0; day < days; day++ {
 		modDay := day % maxTime
 
@@ -89,15 +38,14 @@ func run() error {
 	for _, v := range fish {
 		total += v
 	}
-	fmt.Println("Total fish:", total)
 
-	return nil
+	return total
 }
 
-func main() {
-	err := run()
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
+func Part1(timers []int) (int, error) {
+	return Simulate(timers, 80), nil
+}
+
+func Part2(timers []int) (int, error) {
+	return Simulate(timers, 256), nil
 }
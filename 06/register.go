@@ -0,0 +1,12 @@
+package day06
+
+import "github.com/usedbytes/aoc2021/solver"
+
+func init() {
+	s := solver.Register(6, Parse, Part1, Part2)
+	solver.AddOption(s, "days", "count the fish after this many days", 0,
+		func(timers []int, days int) (int, error) {
+			return Simulate(timers, days), nil
+		})
+	s.Generate = Generate
+}
@@ -0,0 +1,24 @@
+package day07
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+)
+
+// Generate writes the positions of 'size' crabs (1000 by default). Most of
+// them are near the start, like the real input.
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 1000
+	}
+
+	positions := make([]string, size)
+	for i := range positions {
+		positions[i] = fmt.Sprint(int(rnd.ExpFloat64()*400) % 2000)
+	}
+
+	_, err := fmt.Fprintln(w, strings.Join(positions, ","))
+	return err
+}
@@ -1,11 +1,9 @@
-package main
+package day07
 
 import (
-	"bufio"
-	"fmt"
-	"os"
-	"strconv"
-	"strings"
+	"io"
+
+	"github.com/usedbytes/aoc2021/input"
 )
 
 func abs(x int) int {
@@ -25,35 +23,12 @@ func sumOfCosts(vals []int, x int, cost func(a, b int) int) int {
 	return res
 }
 
-func run() error {
-	filename := os.Args[1]
-
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	rd := bufio.NewReader(f)
-
-	line, err := rd.ReadString('\n')
-	if err != nil {
-		return err
-	}
-
-	line = strings.TrimSpace(line)
-
-	startStrings := strings.Split(line, ",")
-	positions := make([]int, len(startStrings))
-	for i, s := range startStrings {
-		v, err := strconv.Atoi(s)
-		if err != nil {
-			return err
-		}
-
-		positions[i] = v
-	}
+func Parse(rd io.Reader) ([]int, error) {
+	return input.CommaInts(rd)
+}
 
+// Returns the lowest total cost to align all of 'positions'
+func minCost(positions []int, cost func(a, b int) int) int {
 	maxPos := 0
 	minPos := 0x7fffffff
 	for _, p := range positions {
@@ -65,12 +40,28 @@ func run() error {
 		}
 	}
 
-	// Part 1 - Absolute difference
+	best := 0x7fffffff
+	for p := minPos; p <= maxPos; p++ {
+		diff := sumOfCosts(positions, p, cost)
+		if diff < best {
+			best = diff
+		}
+	}
+
+	return best
+}
+
+// Part 1 - Absolute difference
+func Part1(positions []int) (int, error) {
 	part1Cost := func(a, b int) int {
 		return abs(a - b)
 	}
 
-	// Part 2 - Integral
+	return minCost(positions, part1Cost), nil
+}
+
+// Part 2 - Integral
+func Part2(positions []int) (int, error) {
 	costMap := make(map[int]int)
 	costMap[0] = 0
 	var recurseAdd func(int) int
@@ -89,30 +80,5 @@ func run() error {
 		return recurseAdd(diff)
 	}
 
-
-	part1Min := 0x7fffffff
-	part2Min := 0x7fffffff
-	for p := minPos; p <= maxPos; p++ {
-		diff := sumOfCosts(positions, p, part1Cost)
-		if diff < part1Min {
-			part1Min = diff
-		}
-
-		diff = sumOfCosts(positions, p, part2Cost)
-		if diff < part2Min {
-			part2Min = diff
-		}
-	}
-	fmt.Println("Part 1:", part1Min)
-	fmt.Println("Part 2:", part2Min)
-
-	return nil
-}
-
-func main() {
-	err := run()
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
+	return minCost(positions, part2Cost), nil
 }
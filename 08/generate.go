@@ -0,0 +1,46 @@
+package day08
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+)
+
+// Generate writes 'size' entries (200 by default), each with the segments
+// wired up differently
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 200
+	}
+
+	bw := bufio.NewWriter(w)
+
+	for i := 0; i < size; i++ {
+		wiring := rnd.Perm(7)
+
+		// The pattern for digit 'd', with the letters in a random order
+		pattern := func(d int) string {
+			var b []byte
+			for _, c := range properDigits[d] {
+				b = append(b, byte('a'+wiring[c-'a']))
+			}
+			rnd.Shuffle(len(b), func(i, j int) { b[i], b[j] = b[j], b[i] })
+
+			return string(b)
+		}
+
+		var signals, outputs []string
+		for _, d := range rnd.Perm(10) {
+			signals = append(signals, pattern(d))
+		}
+		for j := 0; j < 4; j++ {
+			outputs = append(outputs, pattern(rnd.Intn(10)))
+		}
+
+		fmt.Fprintf(bw, "%s | %s\n", strings.Join(signals, " "), strings.Join(outputs, " "))
+	}
+
+	return bw.Flush()
+}
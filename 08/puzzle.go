@@ -1,11 +1,13 @@
-package main
+package day08
 
 import (
-	"bufio"
 	"fmt"
-	"os"
+	"io"
 	"sort"
 	"strings"
+	"unicode"
+
+	"github.com/usedbytes/aoc2021/input"
 )
 
 var properDigits []string = []string{
@@ -21,28 +23,6 @@ var properDigits []string = []string{
 	"abcdfg",
 };
 
-func doLines(filename string, do func(line string) error) error {
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if err := do(line); err != nil {
-			return err
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return err
-	}
-
-	return nil
-}
-
 func countCommonLetters(a, b string) int {
 	count := 0
 	for _, l := range a {
@@ -60,129 +40,185 @@ func orderString(s string) string {
 	return string(b)
 }
 
-func run() error {
-	// Counts the number of occurrences of specific string lengths
-	part1 := make([]int, 8)
+type Entry struct {
+	Signals []string
+	Digits  []string
+}
+
+// Parses the 'n' space-separated patterns in 's', which starts at 'column'.
+// Each pattern is some of the segments a to g, each used at most once.
+func parsePatterns(s string, column, n int) ([]string, error) {
+	patterns := strings.Split(s, " ")
+	if len(patterns) != n {
+		return nil, input.At(column, fmt.Errorf("expected %d patterns, got %d", n, len(patterns)))
+	}
 
-	// Accumulates all the displays
-	part2 := 0
+	for i, p := range patterns {
+		if len(p) == 0 {
+			return nil, input.At(column, fmt.Errorf("empty pattern"))
+		}
+
+		seen := make(map[rune]bool)
+		for j, c := range p {
+			if c < 'a' || c > 'g' || seen[c] {
+				return nil, input.At(column+j, fmt.Errorf("unexpected %q in pattern %q", c, p))
+			}
+			seen[c] = true
+		}
+
+		patterns[i] = orderString(p)
+		column += len(p) + 1
+	}
+
+	return patterns, nil
+}
 
-	if err := doLines(os.Args[1], func(line string) error {
-		// Parse the input
-		chunks := strings.Split(line, " | ")
-		signals := strings.Split(chunks[0], " ")
-		digits := strings.Split(chunks[1], " ")
+func Parse(rd io.Reader) ([]Entry, error) {
+	var entries []Entry
 
-		for i, d := range digits {
-			d = orderString(strings.TrimSpace(d))
-			digits[i] = d
-			part1[len(d)]++
+	if err := input.DoLines(rd, func(line string) error {
+		line = strings.TrimRightFunc(line, unicode.IsSpace)
+
+		left, right, ok := strings.Cut(line, " | ")
+		if !ok {
+			return fmt.Errorf("expected signals | digits, got %q", line)
 		}
 
-		for i, s := range signals {
-			signals[i] = orderString(strings.TrimSpace(s))
+		signals, err := parsePatterns(left, 1, len(properDigits))
+		if err != nil {
+			return err
 		}
 
-		// Build a map from number to possible candidate strings for
-		// that number, based on the number of segments in the string
-		candidateMap := make(map[int][]string)
-		for _, s := range signals {
-			for i, d := range properDigits {
-				if len(s) == len(d) {
-					candidateMap[i] = append(candidateMap[i], s)
-				}
-			}
+		digits, err := parsePatterns(right, len(left)+4, 4)
+		if err != nil {
+			return err
 		}
 
-		// Map from string to number once known
-		assignedMap := make(map[string]int)
+		entries = append(entries, Entry{signals, digits})
 
-		// Assign anything we know for sure (i.e. only has one candidate)
-		// Should be 1, 4, 7 and 8 after the 1st pass
-		for n, candidates := range candidateMap {
-			if len(candidates) == 1 {
-				assignedMap[candidates[0]] = n
-				delete(candidateMap, n)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Decode works out which signal is which digit, and returns the value being
+// displayed
+func (e Entry) Decode() (int, error) {
+	// Build a map from number to possible candidate strings for
+	// that number, based on the number of segments in the string
+	candidateMap := make(map[int][]string)
+	for _, s := range e.Signals {
+		for i, d := range properDigits {
+			if len(s) == len(d) {
+				candidateMap[i] = append(candidateMap[i], s)
 			}
 		}
+	}
 
-		// While we still have values to find
-		remaining := len(candidateMap)
-		for remaining > 0 {
-			for n, candidates := range candidateMap {
-				// If 'assigned' and 'candidate' don't share the same
-				// number of common letters as 'm' and 'n' share segments,
-				// then 'candidate' is *not* a valid possibility for 'n'
-				// and can be eliminated.
-				//
-				// Note: The candidate *must* be consistent with *all*
-				// already-assigned values (in assignedMap)
-				//
-				// We'll build a new list of candidates which
-				// weren't eliminated.
-				newCandidates := []string{}
-				for _, candidate := range candidates {
-					eliminated := false
-					for assigned, m := range assignedMap {
-						// We only check that the number of segments matches
-						// This is hopefully enough to remove all ambiguity.
-						// We never actually determine which letters represent
-						// which segments.
-						expected := countCommonLetters(properDigits[n], properDigits[m])
-						got := countCommonLetters(assigned, candidate)
-						if expected != got {
-							eliminated = true
-							break
-						}
-					}
-					if !eliminated {
-						// This candidate was consistent with *all* currently-assigned
-						// numbers, so it gets to stay in the list
-						newCandidates = append(newCandidates, candidate)
+	// Map from string to number once known
+	assignedMap := make(map[string]int)
+
+	// Assign anything we know for sure (i.e. only has one candidate)
+	// Should be 1, 4, 7 and 8 after the 1st pass
+	for n, candidates := range candidateMap {
+		if len(candidates) == 1 {
+			assignedMap[candidates[0]] = n
+			delete(candidateMap, n)
+		}
+	}
+
+	// While we still have values to find
+	remaining := len(candidateMap)
+	for remaining > 0 {
+		for n, candidates := range candidateMap {
+			// If 'assigned' and 'candidate' don't share the same
+			// number of common letters as 'm' and 'n' share segments,
+			// then 'candidate' is *not* a valid possibility for 'n'
+			// and can be eliminated.
+			//
+			// Note: The candidate *must* be consistent with *all*
+			// already-assigned values (in assignedMap)
+			//
+			// We'll build a new list of candidates which
+			// weren't eliminated.
+			newCandidates := []string{}
+			for _, candidate := range candidates {
+				eliminated := false
+				for assigned, m := range assignedMap {
+					// We only check that the number of segments matches
+					// This is hopefully enough to remove all ambiguity.
+					// We never actually determine which letters represent
+					// which segments.
+					expected := countCommonLetters(properDigits[n], properDigits[m])
+					got := countCommonLetters(assigned, candidate)
+					if expected != got {
+						eliminated = true
+						break
 					}
 				}
-
-				if len(newCandidates) == 1 {
-					// Hooray, we found one!
-					assignedMap[newCandidates[0]] = n
-					delete(candidateMap, n)
-				} else {
-					// Add the (maybe shorter) candidate list back
-					// to the map for the next iteration
-					candidateMap[n] = newCandidates
+				if !eliminated {
+					// This candidate was consistent with *all* currently-assigned
+					// numbers, so it gets to stay in the list
+					newCandidates = append(newCandidates, candidate)
 				}
 			}
 
-			if len(candidateMap) == remaining {
-				return fmt.Errorf("couldn't assign any numbers, will loop forever")
+			if len(newCandidates) == 1 {
+				// Hooray, we found one!
+				assignedMap[newCandidates[0]] = n
+				delete(candidateMap, n)
+			} else {
+				// Add the (maybe shorter) candidate list back
+				// to the map for the next iteration
+				candidateMap[n] = newCandidates
 			}
-			remaining = len(candidateMap)
 		}
 
-		// Phew! We found all the digits, so what's being displayed?
-		display := 0
-		for _, d := range digits {
-			display *= 10
-			display += assignedMap[d]
+		if len(candidateMap) == remaining {
+			return 0, fmt.Errorf("couldn't assign any numbers, will loop forever")
 		}
+		remaining = len(candidateMap)
+	}
 
-		part2 += display
-
-		return nil
-	}); err != nil {
-		return err
+	// Phew! We found all the digits, so what's being displayed?
+	display := 0
+	for _, d := range e.Digits {
+		display *= 10
+		display += assignedMap[d]
 	}
 
-	fmt.Println("Part1:", part1[2] + part1[4] + part1[3] + part1[7])
-	fmt.Println("Part2:", part2)
+	return display, nil
+}
+
+// Part1 counts the occurrences of the digits with unique lengths
+func Part1(entries []Entry) (int, error) {
+	// Counts the number of occurrences of specific string lengths
+	counts := make([]int, 8)
+
+	for _, e := range entries {
+		for _, d := range e.Digits {
+			counts[len(d)]++
+		}
+	}
 
-	return nil
+	return counts[2] + counts[4] + counts[3] + counts[7], nil
 }
 
-func main() {
-	err := run()
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
+// Part2 accumulates all the displays
+func Part2(entries []Entry) (int, error) {
+	total := 0
+
+	for _, e := range entries {
+		display, err := e.Decode()
+		if err != nil {
+			return 0, err
+		}
+
+		total += display
 	}
+
+	return total, nil
 }
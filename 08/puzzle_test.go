@@ -0,0 +1,32 @@
+package day08
+
+import (
+	"testing"
+
+	"github.com/usedbytes/aoc2021/golden"
+)
+
+func TestGolden(t *testing.T) {
+	golden.Test(t, 8)
+}
+
+func TestGenerated(t *testing.T) {
+	golden.Generated(t, 8, 0, 5)
+}
+
+func TestParseErrors(t *testing.T) {
+	const signals = "acedgfb cdfbe gcdfa fbcad dab cefabd cdfgeb eafb cagedb ab"
+
+	golden.ParseErrors(t, 8, []golden.BadInput{
+		{Name: "no digits", Input: signals + "\n", Line: 1, Column: 0},
+		{Name: "too few signals", Input: "ab | cdfeb fcadb cdfeb cdbaf\n", Line: 1, Column: 1},
+		{Name: "too many digits", Input: signals + " | cdfeb fcadb cdfeb cdbaf ab\n", Line: 1, Column: 62},
+		{Name: "empty digit", Input: signals + " | cdfeb  cdfeb cdbaf\n", Line: 1, Column: 68},
+		{Name: "bad segment", Input: signals + " | cdfeb fcadh cdfeb cdbaf\n", Line: 1, Column: 72},
+		{Name: "repeated segment", Input: signals + " | cdfeb fcadb cdfeb cdbaa\n", Line: 1, Column: 84},
+	})
+}
+
+func BenchmarkInput(b *testing.B) {
+	golden.Benchmark(b, 8)
+}
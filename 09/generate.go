@@ -0,0 +1,144 @@
+package day09
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+)
+
+// Generate writes a 'size' x 'size' height map (100 by default, and at least
+// 5). Like the real input, every basin has exactly one low point, and the
+// basins are separated by 9s.
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 100
+	}
+	if size < 5 {
+		size = 5
+	}
+
+	n := size * size
+	neighbours := func(i int, fn func(j int)) {
+		x, y := i%size, i/size
+		if x > 0 {
+			fn(i - 1)
+		}
+		if x < size-1 {
+			fn(i + 1)
+		}
+		if y > 0 {
+			fn(i - size)
+		}
+		if y < size-1 {
+			fn(i + size)
+		}
+	}
+
+	// Pick the low points, none next to each other
+	basin := make([]int, n)
+	for i := range basin {
+		basin[i] = -1
+	}
+
+	var lows []int
+	want := max(3, n/40)
+	for tries := 0; len(lows) < want && tries < 100*want; tries++ {
+		i := rnd.Intn(n)
+
+		ok := basin[i] < 0
+		neighbours(i, func(j int) {
+			ok = ok && basin[j] < 0
+		})
+
+		if ok {
+			basin[i] = len(lows)
+			lows = append(lows, i)
+		}
+	}
+
+	// Grow the basins out from them
+	queue := append([]int{}, lows...)
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+
+		neighbours(i, func(j int) {
+			if basin[j] < 0 {
+				basin[j] = basin[i]
+				queue = append(queue, j)
+			}
+		})
+	}
+
+	// Wall them off from each other, without walling in the low points
+	height := make([]int, n)
+	for i := range height {
+		neighbours(i, func(j int) {
+			if basin[i] == basin[j] || height[i] == 9 || height[j] == 9 {
+				return
+			}
+
+			if i == lows[basin[i]] {
+				height[j] = 9
+			} else {
+				height[i] = 9
+			}
+		})
+	}
+
+	// The walls might have cut parts of basins off, so work out the
+	// heights from what's still connected to each low point. Anything
+	// which isn't becomes a wall.
+	dist := make([]int, n)
+	for i := range dist {
+		dist[i] = -1
+	}
+
+	queue = append(queue, lows...)
+	for _, i := range lows {
+		dist[i] = 0
+	}
+	for len(queue) > 0 {
+		i := queue[0]
+		queue = queue[1:]
+
+		neighbours(i, func(j int) {
+			if dist[j] < 0 && height[j] != 9 && basin[j] == basin[i] {
+				dist[j] = dist[i] + 1
+				queue = append(queue, j)
+			}
+		})
+	}
+
+	bw := bufio.NewWriter(w)
+
+	for i := range height {
+		if dist[i] < 0 {
+			height[i] = 9
+		} else {
+			height[i] = min(8, dist[i])
+		}
+
+		fmt.Fprint(bw, height[i])
+		if i%size == size-1 {
+			fmt.Fprintln(bw)
+		}
+	}
+
+	return bw.Flush()
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
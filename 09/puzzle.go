@@ -1,54 +1,21 @@
-package main
+package day09
 
 import (
-	"bufio"
-	"fmt"
-	"os"
-	"strconv"
+	"io"
 	"sort"
-)
-
-func doLines(filename string, do func(line string) error) error {
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if err := do(line); err != nil {
-			return err
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return err
-	}
-
-	return nil
-}
 
-func get(heightMap [][]int, x, y, oob int) int {
-	if y < 0 || y >= len(heightMap) {
-		return oob
-	}
-
-	if x < 0 || x >= len(heightMap[0]) {
-		return oob
-	}
+	"github.com/usedbytes/aoc2021/grid"
+)
 
-	return heightMap[y][x]
-}
+type Point = grid.Point
 
-func searchAround(heightMap [][]int, pt Point, basin map[Point]bool) {
+func searchAround(heightMap *grid.Grid[int], pt Point, basin map[Point]bool) {
 	if _, ok := basin[pt]; ok {
 		// Already hit
 		return
 	}
 
-	if get(heightMap, pt.X, pt.Y, 10) >= 9 {
+	if heightMap.Get(pt) >= 9 {
 		// Edge of the basin
 		basin[pt] = false
 		return
@@ -56,16 +23,9 @@ func searchAround(heightMap [][]int, pt Point, basin map[Point]bool) {
 
 	basin[pt] = true
 
-	dirs := [][2]int {
-		{  0, -1 },
-		{  0,  1 },
-		{ -1,  0 },
-		{  1,  0 },
-	}
-
-	for _, d := range dirs {
-		searchAround(heightMap, Point{pt.X + d[0], pt.Y + d[1]}, basin)
-	}
+	heightMap.Neighbours4(pt, func(p Point, v int) {
+		searchAround(heightMap, p, basin)
+	})
 }
 
 func basinSize(basin map[Point]bool) int {
@@ -79,86 +39,69 @@ func basinSize(basin map[Point]bool) int {
 	return size
 }
 
-type Point struct {
-	X, Y int
-}
-
 type LowPoint struct {
 	Point
 	Height int
 	BasinSize int
 }
 
-func run() error {
-
-	var heightMap [][]int
-
-	if err := doLines(os.Args[1], func(line string) error {
-		heightRow := []int{}
-		for _, l := range line {
-			n, err := strconv.Atoi(string(l))
-			if err != nil {
-				return err
-			}
-			heightRow = append(heightRow, n)
-		}
-		heightMap = append(heightMap, heightRow)
-
-		return nil
-	}); err != nil {
-		return err
+// Parse returns the height map. Outside of the map is higher than any point
+// in it.
+func Parse(rd io.Reader) (*grid.Grid[int], error) {
+	heightMap, err := grid.ParseDigits(rd)
+	if err != nil {
+		return nil, err
 	}
+	heightMap.Background = 10
 
+	return heightMap, nil
+}
+
+func findLowPoints(heightMap *grid.Grid[int]) []*LowPoint {
 	lowPoints := []*LowPoint{}
 
-	for y := 0; y < len(heightMap); y++ {
-		for x := 0; x < len(heightMap[0]); x++ {
-			here := get(heightMap, x, y, 10)
-
-			if get(heightMap, x - 1, y, 10) > here &&
-			   get(heightMap, x + 1, y, 10) > here &&
-			   get(heightMap, x, y - 1, 10) > here &&
-			   get(heightMap, x, y + 1, 10) > here {
-				pt := LowPoint{
-					Point: Point {
-						X: x,
-						Y: y,
-					},
-					Height: here,
-				}
-				lowPoints = append(lowPoints, &pt)
+	heightMap.Each(func(p Point, here int) {
+		lowest := true
+		heightMap.Neighbours4(p, func(_ Point, v int) {
+			if v <= here {
+				lowest = false
 			}
+		})
+
+		if lowest {
+			lowPoints = append(lowPoints, &LowPoint{
+				Point: p,
+				Height: here,
+			})
 		}
-	}
+	})
+
+	return lowPoints
+}
 
+func Part1(heightMap *grid.Grid[int]) (int, error) {
 	part1 := 0
 
-	basinSizes := []int{}
-	for _, p := range lowPoints {
+	for _, p := range findLowPoints(heightMap) {
 		part1 += (p.Height + 1)
+	}
+
+	return part1, nil
+}
 
+func Part2(heightMap *grid.Grid[int]) (int, error) {
+	basinSizes := []int{}
+	for _, p := range findLowPoints(heightMap) {
 		basin := make(map[Point]bool)
 		searchAround(heightMap, p.Point, basin)
 		basinSizes = append(basinSizes, basinSize(basin))
 	}
 
-
-	fmt.Println("Part 1:", part1)
-
 	sort.Ints(basinSizes)
 	part2 := 1
 	for i := len(basinSizes) - 1; i >= len(basinSizes) - 3; i-- {
 		part2 *= basinSizes[i]
 	}
-	fmt.Println("Part 2:", part2)
-
-	return nil
-}
 
-func main() {
-	err := run()
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
+	return part2, nil
 }
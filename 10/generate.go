@@ -0,0 +1,78 @@
+package day10
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+)
+
+// Generate writes 'size' lines (100 by default), each either corrupted or
+// incomplete. Like the real input, there's an odd number of incomplete ones.
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 100
+	}
+
+	corrupt := make([]bool, size)
+	incomplete := 0
+	for i := range corrupt {
+		corrupt[i] = rnd.Intn(2) == 0
+		if !corrupt[i] {
+			incomplete++
+		}
+	}
+	if incomplete%2 == 0 {
+		corrupt[0] = !corrupt[0]
+	}
+
+	bw := bufio.NewWriter(w)
+
+	const openers = "([{<"
+	for _, c := range corrupt {
+		// Keep the nesting shallow enough that completion scores
+		// don't overflow
+		const maxDepth = 20
+
+		var line, stack []rune
+		length := 40 + rnd.Intn(70)
+		for len(line) < length || len(stack) == 0 {
+			if len(stack) == 0 || (len(stack) < maxDepth && rnd.Intn(2) == 0) {
+				open := rune(openers[rnd.Intn(len(openers))])
+				line = append(line, open)
+				stack = append(stack, pairs[open])
+			} else {
+				line = append(line, stack[len(stack)-1])
+				stack = stack[:len(stack)-1]
+			}
+		}
+
+		if c {
+			// Swap one of the closing characters for the wrong one,
+			// or add a wrong one on the end if there aren't any
+			var closes []int
+			for i, r := range line {
+				if _, ok := pairs[r]; !ok {
+					closes = append(closes, i)
+				}
+			}
+
+			i := len(line)
+			want := stack[len(stack)-1]
+			if len(closes) > 0 {
+				i = closes[rnd.Intn(len(closes))]
+				want = line[i]
+			} else {
+				line = append(line, want)
+			}
+
+			for line[i] == want {
+				line[i] = pairs[rune(openers[rnd.Intn(len(openers))])]
+			}
+		}
+
+		fmt.Fprintln(bw, string(line))
+	}
+
+	return bw.Flush()
+}
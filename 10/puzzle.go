@@ -1,10 +1,12 @@
-package main
+package day10
 
 import (
-	"bufio"
 	"fmt"
-	"os"
+	"io"
 	"sort"
+
+	"github.com/usedbytes/aoc2021/debug"
+	"github.com/usedbytes/aoc2021/input"
 )
 
 var pairs map[rune]rune = map[rune]rune{
@@ -28,86 +30,105 @@ var completionScores map[rune]int = map[rune]int{
 	'>': 4,
 }
 
-func doLines(filename string, do func(line string) error) error {
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
+// Each line must only have brackets in, and never close more chunks than it
+// has opened
+func Parse(rd io.Reader) ([]string, error) {
+	var lines []string
+
+	if err := input.DoLines(rd, func(line string) error {
+		open := 0
+		for i, l := range line {
+			if _, ok := pairs[l]; ok {
+				open++
+			} else if _, ok := completionScores[l]; !ok {
+				return input.At(i+1, fmt.Errorf("unexpected %q", l))
+			} else if open == 0 {
+				return input.At(i+1, fmt.Errorf("%q doesn't close anything", l))
+			} else {
+				open--
+			}
+		}
+
+		lines = append(lines, line)
+		return nil
+	}); err != nil {
+		return nil, err
 	}
-	defer f.Close()
 
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if err := do(line); err != nil {
-			return err
+	if len(lines) == 0 {
+		return nil, &input.ParseError{Line: 1, Err: io.ErrUnexpectedEOF}
+	}
+
+	return lines, nil
+}
+
+// Returns the syntax error score if the line is corrupt, or the completion
+// score if it is incomplete
+func check(line string) (int, int) {
+	debug.Println(line)
+
+	var err error
+	var illegal rune
+	stack := []rune{}
+	for _, l := range line {
+		if closing, ok := pairs[l]; ok {
+			stack = append(stack, closing)
+		} else if l != stack[len(stack)-1] {
+			err = fmt.Errorf("expected %c, got %c", stack[len(stack)-1], l)
+			illegal = l
+			break
+		} else {
+			stack = stack[:len(stack)-1]
 		}
 	}
 
-	if err := scanner.Err(); err != nil {
-		return err
+	if err != nil {
+		debug.Println("corrupt:", err)
+		return syntaxErrorScores[illegal], 0
+	} else if len(stack) != 0 {
+		debug.Println("incomplete")
+		complete := ""
+		score := 0
+		for i, _ := range stack {
+			l := stack[len(stack)-(i+1)]
+			score *= 5
+			score += completionScores[l]
+
+			complete += string(l)
+		}
+		debug.Println("complete with", complete, score)
+		return 0, score
 	}
 
-	return nil
+	return 0, 0
 }
 
-func run() error {
-
+func Part1(lines []string) (int, error) {
 	part1 := 0
-	part2 := []int{}
 
-	if err := doLines(os.Args[1], func(line string) error {
-		fmt.Println(line)
-
-		var err error
-		var illegal rune
-		stack := []rune{}
-		for _, l := range line {
-			if closing, ok := pairs[l]; ok {
-				stack = append(stack, closing)
-			} else if l != stack[len(stack)-1] {
-				err = fmt.Errorf("expected %c, got %c", stack[len(stack)-1], l)
-				illegal = l
-				break
-			} else {
-				stack = stack[:len(stack)-1]
-			}
-		}
+	for _, line := range lines {
+		syntaxError, _ := check(line)
+		part1 += syntaxError
+	}
 
-		if err != nil {
-			fmt.Println("corrupt:", err)
-			part1 += syntaxErrorScores[illegal]
-		} else if len(stack) != 0 {
-			fmt.Println("incomplete")
-			complete := ""
-			score := 0
-			for i, _ := range stack {
-				l := stack[len(stack)-(i+1)]
-				score *= 5
-				score += completionScores[l]
-
-				complete += string(l)
-			}
-			fmt.Println("complete with", complete, score)
-			part2 = append(part2, score)
-		}
+	return part1, nil
+}
 
-		return nil
-	}); err != nil {
-		return err
+func Part2(lines []string) (int, error) {
+	part2 := []int{}
+
+	for _, line := range lines {
+		_, completion := check(line)
+		if completion > 0 {
+			part2 = append(part2, completion)
+		}
 	}
 
-	fmt.Println("Part 1:", part1)
+	if len(part2) == 0 {
+		return 0, fmt.Errorf("no lines are incomplete")
+	}
 
 	sort.Ints(part2)
-	fmt.Println("Part 2:", part2[len(part2) / 2])
 
-	return nil
-}
-
-func main() {
-	err := run()
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
+	return part2[len(part2) / 2], nil
 }
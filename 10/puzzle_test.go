@@ -0,0 +1,27 @@
+package day10
+
+import (
+	"testing"
+
+	"github.com/usedbytes/aoc2021/golden"
+)
+
+func TestGolden(t *testing.T) {
+	golden.Test(t, 10)
+}
+
+func TestGenerated(t *testing.T) {
+	golden.Generated(t, 10, 0, 5)
+}
+
+func TestParseErrors(t *testing.T) {
+	golden.ParseErrors(t, 10, []golden.BadInput{
+		{Name: "empty", Input: "", Line: 1, Column: 0},
+		{Name: "not a bracket", Input: "[({(<(())[]>[[{[]{<()<>>\n[(()[<>])]a\n", Line: 2, Column: 11},
+		{Name: "nothing to close", Input: "()]\n", Line: 1, Column: 3},
+	})
+}
+
+func BenchmarkInput(b *testing.B) {
+	golden.Benchmark(b, 10)
+}
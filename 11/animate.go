@@ -0,0 +1,38 @@
+package day11
+
+import (
+	"fmt"
+
+	"github.com/usedbytes/aoc2021/grid"
+	"github.com/usedbytes/aoc2021/solver"
+)
+
+// Animate shows the octopuses' energy levels at each step, until a little
+// after they all flash at once (or 500 steps if they never do). The ones
+// which just flashed are the zeroes.
+func Animate(scr solver.Screen, cavern *grid.Grid[int]) error {
+	cavern = cavern.Clone()
+
+	if err := scr.Frame(fmt.Sprintf("Step 0\n\n%v", cavern)); err != nil {
+		return err
+	}
+
+	synced := -1
+	for step := 1; step <= 500 && (synced < 0 || step < synced+10); step++ {
+		flashed := Step(cavern)
+		if flashed == cavern.Width()*cavern.Height() && synced < 0 {
+			synced = step
+		}
+
+		status := fmt.Sprintf("Step %d: %d flashed", step, flashed)
+		if synced > 0 {
+			status += fmt.Sprintf(" (all flashed on step %d)", synced)
+		}
+
+		if err := scr.Frame(fmt.Sprintf("%s\n\n%v", status, cavern)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
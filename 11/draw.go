@@ -0,0 +1,43 @@
+package day11
+
+import (
+	"image/color"
+	"io"
+	"time"
+
+	"github.com/usedbytes/aoc2021/grid"
+	"github.com/usedbytes/aoc2021/visual"
+)
+
+// Draw writes an animated GIF of the octopuses, a frame per step, until a
+// little after they all flash at once (or 500 steps if they never do).
+// Flashing octopuses are yellow, and the rest get lighter as their energy
+// goes up.
+func Draw(w io.Writer, cavern *grid.Grid[int], scale int) error {
+	if scale <= 0 {
+		scale = 20
+	}
+
+	palette := visual.Gradient(color.RGBA{0x00, 0x10, 0x30, 0xff}, color.RGBA{0x40, 0xa0, 0xc0, 0xff}, 10)
+	palette[0] = color.RGBA{0xff, 0xe0, 0x40, 0xff}
+
+	colour := func(v int) uint8 {
+		return uint8(v)
+	}
+
+	cavern = cavern.Clone()
+
+	var anim visual.Animation
+	anim.Add(visual.Grid(cavern, scale, palette, colour), 500*time.Millisecond)
+
+	synced := -1
+	for step := 1; step <= 500 && (synced < 0 || step < synced+10); step++ {
+		if Step(cavern) == cavern.Width()*cavern.Height() && synced < 0 {
+			synced = step
+		}
+
+		anim.Add(visual.Grid(cavern, scale, palette, colour), 100*time.Millisecond)
+	}
+
+	return anim.WriteGIF(w)
+}
@@ -0,0 +1,36 @@
+package day11
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+
+	"github.com/usedbytes/aoc2021/grid"
+)
+
+// Generate writes a 'size' x 'size' grid of octopuses (10 by default), which
+// all flash at once within a thousand steps
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 10
+	}
+
+	const maxSteps = 1000
+
+	for tries := 0; tries < 100; tries++ {
+		cavern := grid.NewDense(size, size, 0)
+		cavern.Each(func(p Point, _ int) {
+			cavern.Set(p, rnd.Intn(10))
+		})
+
+		sim := cavern.Clone()
+		for step := 0; step < maxSteps; step++ {
+			if Step(sim) == size*size {
+				_, err := fmt.Fprintln(w, cavern)
+				return err
+			}
+		}
+	}
+
+	return fmt.Errorf("couldn't find a %dx%d grid which synchronises", size, size)
+}
@@ -1,136 +1,82 @@
-package main
+package day11
 
 import (
-	"bufio"
-	"fmt"
-	"os"
-)
-
-func doLines(filename string, do func(line string) error) error {
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
+	"io"
 
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if err := do(line); err != nil {
-			return err
-		}
-	}
+	"github.com/usedbytes/aoc2021/grid"
+)
 
-	if err := scanner.Err(); err != nil {
-		return err
-	}
+type Point = grid.Point
 
-	return nil
+func Parse(rd io.Reader) (*grid.Grid[int], error) {
+	return grid.ParseDigits(rd)
 }
 
-type Point struct {
-	X, Y int
-}
+// Step advances 'cavern' by one step, and returns the number of octopodes
+// which flashed
+func Step(cavern *grid.Grid[int]) int {
+	flashed := make(map[Point]bool)
+
+	// First all octopodes increment
+	cavern.Each(func(k Point, v int) {
+		cavern.Set(k, v + 1)
+	})
+
+	// Then process flashes until there are no more
+	for {
+		done := true
+		cavern.Each(func(k Point, v int) {
+			if v <= 9 || flashed[k] {
+				// Just save an indent level
+				return
+			}
 
-func run() error {
-	cavern := make(map[Point]int)
+			// There was a flash, so we aren't done yet
+			done = false
+			flashed[k] = true
 
-	y := 0
-	if err := doLines(os.Args[1], func(line string) error {
-		for i, l := range line {
-			pt := Point{ X: i, Y: y }
+			cavern.Neighbours8(k, func(adjacent Point, v int) {
+				// Just increment, any flash will be
+				// processed in a later iteration
+				cavern.Set(adjacent, v + 1)
+			})
+		})
 
-			cavern[pt] = int(l - '0')
+		if done {
+			break
 		}
-
-		y++
-		return nil
-	}); err != nil {
-		return err
 	}
 
-	nsteps := 100
-	flashes := 0
-
-	adjacent_dirs := [][2]int{
-		{ -1, -1, },
-		{  0, -1, },
-		{  1, -1, },
-		{ -1,  0, },
-		// Skip self
-		{  1,  0, },
-		{ -1,  1, },
-		{  0,  1, },
-		{  1,  1, },
+	// Then all that flashed go to 0
+	for k, _ := range flashed {
+		cavern.Set(k, 0)
 	}
 
-	finished := false
-	step := 0
-	for !finished {
-		flashed := make(map[Point]bool)
+	return len(flashed)
+}
 
-		// First all octopodes increment
-		for k, v := range cavern {
-			cavern[k] = v + 1
-		}
+func Part1(cavern *grid.Grid[int]) (int, error) {
+	cavern = cavern.Clone()
 
-		// Then process flashes until there are no more
-		for {
-			done := true
-			for k, v := range cavern {
-				if v <= 9 || flashed[k] {
-					// Just save an indent level
-					continue
-				}
-
-				// There was a flash, so we aren't done yet
-				done = false
-				flashed[k] = true
-
-				for _, d := range adjacent_dirs {
-					adjacent := Point{
-						X: k.X + d[0],
-						Y: k.Y + d[1],
-					}
-
-					if v, exists := cavern[adjacent]; exists {
-						// Just increment, any flash will be
-						// processed in a later iteration
-						cavern[adjacent] = v + 1
-					}
-				}
-			}
+	nsteps := 100
+	flashes := 0
+	for step := 0; step < nsteps; step++ {
+		flashes += Step(cavern)
+	}
 
-			if done {
-				break
-			}
-		}
+	return flashes, nil
+}
 
-		// Then all that flashed go to 0
-		for k, _ := range flashed {
-			cavern[k] = 0
-		}
+func Part2(cavern *grid.Grid[int]) (int, error) {
+	cavern = cavern.Clone()
 
-		flashes += len(flashed)
+	step := 0
+	for {
+		flashed := Step(cavern)
 		step++
 
-		if (step == nsteps) {
-			fmt.Println("Part 1:", flashes)
-		}
-
-		if len(flashed) == len(cavern) {
-			fmt.Println("Part 2:", step)
-			finished = true
+		if flashed == cavern.Width() * cavern.Height() {
+			return step, nil
 		}
 	}
-
-	return nil
-}
-
-func main() {
-	err := run()
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
 }
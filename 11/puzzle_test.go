@@ -0,0 +1,67 @@
+package day11
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/usedbytes/aoc2021/golden"
+	"github.com/usedbytes/aoc2021/grid"
+)
+
+func TestGolden(t *testing.T) {
+	golden.Test(t, 11)
+}
+
+func TestGenerated(t *testing.T) {
+	golden.Generated(t, 11, 0, 5)
+}
+
+func TestDraw(t *testing.T) {
+	// Frame n is after step n, and the first one where every octopus is
+	// 0 is when they all flash together
+	golden.Drawn(t, 11, func(t *testing.T, c golden.Case, frames []*image.Paletted) {
+		for n, f := range frames {
+			if golden.Count(f, 0) == len(f.Pix) {
+				if got, want := fmt.Sprint(n), c.Answers["2"]; got != want {
+					t.Errorf("all flashed in frame %s, want %s", got, want)
+				}
+				return
+			}
+		}
+
+		t.Errorf("they never all flashed in %d frames", len(frames))
+	})
+}
+
+func TestAnimate(t *testing.T) {
+	// Frame n is after step n, and the first one where every octopus is
+	// 0 is when they all flash together
+	golden.Animated(t, 11, func(t *testing.T, c golden.Case, frames []string) {
+		cavern := c.In.(*grid.Grid[int])
+
+		for n, f := range frames {
+			status, board, _ := strings.Cut(f, "\n\n")
+			if strings.Trim(board, "0\n") != "" {
+				continue
+			}
+
+			if got, want := fmt.Sprint(n), c.Answers["2"]; got != want {
+				t.Errorf("all flashed in frame %s, want %s", got, want)
+			}
+
+			want := fmt.Sprintf("Step %d: %d flashed", n, cavern.Width()*cavern.Height())
+			if !strings.HasPrefix(status, want) {
+				t.Errorf("status is %q, want %q", status, want)
+			}
+			return
+		}
+
+		t.Errorf("they never all flashed in %d frames", len(frames))
+	})
+}
+
+func BenchmarkInput(b *testing.B) {
+	golden.Benchmark(b, 11)
+}
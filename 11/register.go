@@ -0,0 +1,10 @@
+package day11
+
+import "github.com/usedbytes/aoc2021/solver"
+
+func init() {
+	s := solver.Register(11, Parse, Part1, Part2)
+	s.Generate = Generate
+	solver.SetDrawing(s, "gif", Draw)
+	solver.SetAnimation(s, 10, Animate)
+}
@@ -0,0 +1,73 @@
+package day12
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+)
+
+// Generate writes a cave system with 'size' caves as well as start and end
+// (10 by default, and at most 60). About a fifth of them are big. Big caves
+// are never connected to each other, otherwise there would be infinitely
+// many routes. The number of routes grows very quickly with 'size'.
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 10
+	}
+	if size > 60 {
+		return fmt.Errorf("too many caves: %d", size)
+	}
+
+	// Two letter names, which can't clash with start or end. The first is
+	// always small, so that every big cave has something to connect to.
+	var caves []string
+	for i := 0; i < size; i++ {
+		name := string([]byte{byte('a' + i/26), byte('a' + i%26)})
+		if i > 0 && rnd.Intn(5) == 0 {
+			name = strings.ToUpper(name)
+		}
+		caves = append(caves, name)
+	}
+
+	var lines []string
+	seen := make(map[[2]string]bool)
+	connect := func(a, b string) {
+		if a == b || (!isLower(a) && !isLower(b)) || seen[[2]string{a, b}] {
+			return
+		}
+		seen[[2]string{a, b}] = true
+		seen[[2]string{b, a}] = true
+		lines = append(lines, a+"-"+b)
+	}
+
+	// Connect each cave to an earlier one, so that they're all reachable
+	for i := 1; i < len(caves); i++ {
+		j := rnd.Intn(i)
+		for !isLower(caves[i]) && !isLower(caves[j]) {
+			j = rnd.Intn(i)
+		}
+		connect(caves[i], caves[j])
+	}
+
+	// Then some extra connections
+	for i := 0; i < size/2; i++ {
+		connect(caves[rnd.Intn(size)], caves[rnd.Intn(size)])
+	}
+
+	for _, end := range []string{"start", "end"} {
+		for i := 0; i < 1+rnd.Intn(3); i++ {
+			connect(end, caves[rnd.Intn(size)])
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+
+	rnd.Shuffle(len(lines), func(i, j int) { lines[i], lines[j] = lines[j], lines[i] })
+	for _, l := range lines {
+		fmt.Fprintln(bw, l)
+	}
+
+	return bw.Flush()
+}
@@ -1,146 +1,135 @@
-package main
+package day12
 
 import (
-	"bufio"
 	"fmt"
-	"os"
+	"io"
 	"strings"
-)
-
-func doLines(filename string, do func(line string) error) error {
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if err := do(line); err != nil {
-			return err
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return err
-	}
-
-	return nil
-}
 
-func contains(visited []string, place string) bool {
-	for _, s := range visited {
-		if s == place {
-			return true
-		}
-	}
+	"github.com/usedbytes/aoc2021/input"
+	"github.com/usedbytes/aoc2021/search"
+)
 
-	return false
-}
+// Each small cave gets a bit in state.visited
+const maxSmallCaves = 64
 
 func isLower(a string) bool {
 	return strings.ToLower(a) == a
 }
 
-func part1CanVisit(visited []string, node string) bool {
-	if contains(visited, node) && isLower(node) {
-		return false
+// Checks that 'name' is all lower case letters, for a small cave, or all
+// upper case for a big one
+func checkName(name string) error {
+	if name == "" {
+		return fmt.Errorf("expected a cave name")
 	}
 
-	return true
-}
-
-func part2CanVisit(visited []string, node string) bool {
-	counts := make(map[string]int)
-
-	if !isLower(node) {
-		return true
+	for _, c := range name {
+		if c < 'A' || c > 'z' || (c > 'Z' && c < 'a') {
+			return fmt.Errorf("unexpected %q in cave name %q", c, name)
+		}
 	}
 
-	if node == "start" {
-		return false
+	if isLower(name) == (strings.ToUpper(name) == name) {
+		return fmt.Errorf("cave name %q is mixed case", name)
 	}
 
-	if node == "end" {
-		return true
-	}
+	return nil
+}
 
-	for _, n := range visited {
-		if isLower(n) {
-			counts[n] = counts[n] + 1
-		}
-	}
+// The state of a route: where it is, and which small caves it has visited
+type state struct {
+	cave    string
+	visited uint64
+	// Whether a small cave has been visited twice
+	twice   bool
+}
 
-	if counts[node] > 1 {
-		return false
+// countRoutes counts the routes from start to end, which visit small caves
+// at most once - except one, which may be visited twice if 'allowTwice' is
+// set.
+func countRoutes(system map[string][]string, allowTwice bool) int {
+	// Give each small cave a bit in 'visited'
+	bits := make(map[string]uint64)
+	for cave := range system {
+		if isLower(cave) {
+			bits[cave] = 1 << len(bits)
+		}
 	}
 
-	visitedBefore := contains(visited, node)
-	for _, v := range counts {
-		if v > 1 && visitedBefore {
-			return false
-		}
+	s := &search.Search[state]{
+		Neighbours: func(st state, yield func(state, int)) {
+			for _, l := range system[st.cave] {
+				if l == "start" {
+					continue
+				}
+
+				next := state{l, st.visited | bits[l], st.twice}
+				if st.visited & bits[l] != 0 {
+					if !allowTwice || st.twice {
+						continue
+					}
+					next.twice = true
+				}
+
+				yield(next, 1)
+			}
+		},
+		IsGoal: func(st state) bool {
+			return st.cave == "end"
+		},
 	}
 
-	return true
+	return s.CountPaths(state{"start", bits["start"], false})
 }
 
-func explore(system map[string][]string, from string, visited []string, canVisit func([]string, string) bool) [][]string {
-	conns := system[from]
-	visited = append(visited, from)
-
-	routes := make([][]string, 0)
-
-	for _, l := range conns {
-		if l == "end" {
-			newVisited := make([]string, len(visited), len(visited)+1)
-			copy(newVisited, visited)
+func Parse(rd io.Reader) (map[string][]string, error) {
+	system := make(map[string][]string)
+	small := 0
 
-			routes = append(routes, append(newVisited, l))
-			continue
+	if err := input.DoLines(rd, func(line string) error {
+		a, b, ok := strings.Cut(line, "-")
+		if !ok {
+			return fmt.Errorf("expected cave-cave, got %q", line)
 		}
 
-		if !canVisit(visited, l) {
-			continue
+		if err := checkName(a); err != nil {
+			return input.At(1, err)
 		}
 
-		routes = append(routes, explore(system, l, visited, canVisit)...)
-	}
-
-	return routes
-}
+		if err := checkName(b); err != nil {
+			return input.At(len(a)+2, err)
+		}
 
-func run() error {
-	system := make(map[string][]string)
+		// There would be endless routes going back and forth
+		if !isLower(a) && !isLower(b) {
+			return input.At(len(a)+2, fmt.Errorf("big caves %s and %s are connected", a, b))
+		}
 
-	if err := doLines(os.Args[1], func(line string) error {
-		parts := strings.Split(line, "-")
+		for _, cave := range []string{a, b} {
+			if _, ok := system[cave]; !ok && isLower(cave) {
+				small++
+			}
+		}
 
-		conns := system[parts[0]]
-		system[parts[0]] = append(conns, parts[1])
+		if small > maxSmallCaves {
+			return fmt.Errorf("more than %d small caves", maxSmallCaves)
+		}
 
-		conns = system[parts[1]]
-		system[parts[1]] = append(conns, parts[0])
+		system[a] = append(system[a], b)
+		system[b] = append(system[b], a)
 
 		return nil
 	}); err != nil {
-		return err
+		return nil, err
 	}
 
-	routes := explore(system, "start", []string{}, part1CanVisit)
-	fmt.Println("Part 1:", len(routes))
-
-	routes = explore(system, "start", []string{}, part2CanVisit)
-	fmt.Println("Part 2:", len(routes))
+	return system, nil
+}
 
-	return nil
+func Part1(system map[string][]string) (int, error) {
+	return countRoutes(system, false), nil
 }
 
-func main() {
-	err := run()
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
+func Part2(system map[string][]string) (int, error) {
+	return countRoutes(system, true), nil
 }
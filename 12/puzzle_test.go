@@ -0,0 +1,38 @@
+package day12
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/usedbytes/aoc2021/golden"
+)
+
+func TestGolden(t *testing.T) {
+	golden.Test(t, 12)
+}
+
+func TestGenerated(t *testing.T) {
+	golden.Generated(t, 12, 0, 5)
+}
+
+func TestParseErrors(t *testing.T) {
+	var many strings.Builder
+	for i := 0; i < 63; i++ {
+		fmt.Fprintf(&many, "start-%c%c\n", 'a'+i/26, 'a'+i%26)
+	}
+	many.WriteString("start-end\n")
+
+	golden.ParseErrors(t, 12, []golden.BadInput{
+		{Name: "no dash", Input: "start-A\nAend\n", Line: 2, Column: 0},
+		{Name: "empty name", Input: "start-\n", Line: 1, Column: 7},
+		{Name: "bad name", Input: "start-A\nA-b c\n", Line: 2, Column: 3},
+		{Name: "mixed case", Input: "start-Ab\n", Line: 1, Column: 7},
+		{Name: "big caves connected", Input: "start-A\nA-B\nB-end\n", Line: 2, Column: 3},
+		{Name: "too many small caves", Input: many.String(), Line: 64, Column: 0},
+	})
+}
+
+func BenchmarkInput(b *testing.B) {
+	golden.Benchmark(b, 12)
+}
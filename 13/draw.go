@@ -0,0 +1,97 @@
+package day13
+
+import (
+	"image"
+	"image/color"
+	"io"
+	"time"
+
+	"github.com/usedbytes/aoc2021/grid"
+	"github.com/usedbytes/aoc2021/visual"
+)
+
+// Draw writes an animated GIF of the paper being folded, a frame per fold.
+// Each frame is zoomed in so that the paper fills it, so the letters are
+// readable at the end.
+func Draw(w io.Writer, m *Manual, scale int) error {
+	if scale <= 0 {
+		scale = 1
+	}
+
+	// The paper starts just big enough for all the dots
+	var width, height int
+	for _, p := range m.Dots {
+		if p.X >= width {
+			width = p.X + 1
+		}
+		if p.Y >= height {
+			height = p.Y + 1
+		}
+	}
+
+	palette := color.Palette{
+		color.RGBA{0xf0, 0xf0, 0xe0, 0xff},
+		color.RGBA{0x20, 0x20, 0x60, 0xff},
+		color.RGBA{0xc0, 0x20, 0x20, 0xff},
+	}
+
+	// The frames stay the size of the unfolded paper
+	fullWidth, fullHeight := width, height
+	origin := grid.Point{}
+	corner := grid.Point{X: width - 1, Y: height - 1}
+
+	// Draws the dots (folded by 'f') on paper 'w' x 'h', with the next
+	// fold in red
+	frame := func(f func(Point) Point, w, h int, next *Instruction) *image.Paletted {
+		img := visual.Canvas(origin, corner, scale, palette)
+
+		zoom := fullWidth / w
+		if fullHeight/h < zoom {
+			zoom = fullHeight / h
+		}
+		if zoom < 1 {
+			zoom = 1
+		}
+
+		if next != nil && next.Direction == "horizontal" {
+			for x := 0; x < w; x++ {
+				visual.Cell(img, origin, grid.Point{X: x, Y: next.Line}, scale*zoom, 2)
+			}
+		} else if next != nil {
+			for y := 0; y < h; y++ {
+				visual.Cell(img, origin, grid.Point{X: next.Line, Y: y}, scale*zoom, 2)
+			}
+		}
+
+		for _, d := range m.Dots {
+			p := f(d)
+			visual.Cell(img, origin, grid.Point{X: p.X, Y: p.Y}, scale*zoom, 1)
+		}
+
+		return img
+	}
+
+	var anim visual.Animation
+
+	for i := 0; i <= len(m.Instructions); i++ {
+		var next *Instruction
+		delay := time.Second
+		if i < len(m.Instructions) {
+			next = &m.Instructions[i]
+		} else {
+			delay = 5 * time.Second
+		}
+
+		anim.Add(frame(makeFolds(m.Instructions[:i]), width, height, next), delay)
+
+		if next != nil {
+			if next.Direction == "horizontal" {
+				height = next.Line
+			} else {
+				width = next.Line
+			}
+		}
+	}
+
+	return anim.WriteGIF(w)
+}
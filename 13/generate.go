@@ -0,0 +1,80 @@
+package day13
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+)
+
+// Generate writes a manual with 'size' folds (12 by default). It starts from
+// a random 40x6 sheet and unfolds it, so that no dots ever land on a fold.
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 12
+	}
+
+	width, height := 40, 6
+
+	var dots []Point
+	for len(dots) == 0 {
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				if rnd.Intn(5) < 2 {
+					dots = append(dots, Point{x, y})
+				}
+			}
+		}
+	}
+
+	// Each dot ends up on one side of the fold, or sometimes both
+	var folds []Instruction
+	for i := 0; i < size; i++ {
+		ins := Instruction{Direction: "vertical", Line: width}
+		mirror := func(p Point) Point { return Point{2*width - p.X, p.Y} }
+		if rnd.Intn(2) == 0 {
+			ins = Instruction{Direction: "horizontal", Line: height}
+			mirror = func(p Point) Point { return Point{p.X, 2*height - p.Y} }
+		}
+
+		var unfolded []Point
+		for _, p := range dots {
+			switch r := rnd.Intn(12); {
+			case r < 5:
+				unfolded = append(unfolded, p)
+			case r < 10:
+				unfolded = append(unfolded, mirror(p))
+			default:
+				unfolded = append(unfolded, p, mirror(p))
+			}
+		}
+		dots = unfolded
+
+		if ins.Direction == "vertical" {
+			width = 2*width + 1
+		} else {
+			height = 2*height + 1
+		}
+
+		folds = append([]Instruction{ins}, folds...)
+	}
+
+	bw := bufio.NewWriter(w)
+
+	rnd.Shuffle(len(dots), func(i, j int) { dots[i], dots[j] = dots[j], dots[i] })
+	for _, p := range dots {
+		fmt.Fprintf(bw, "%d,%d\n", p.X, p.Y)
+	}
+
+	fmt.Fprintln(bw)
+
+	for _, ins := range folds {
+		axis := "x"
+		if ins.Direction == "horizontal" {
+			axis = "y"
+		}
+		fmt.Fprintf(bw, "fold along %s=%d\n", axis, ins.Line)
+	}
+
+	return bw.Flush()
+}
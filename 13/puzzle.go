@@ -1,45 +1,22 @@
-package main
+package day13
 
 import (
-	"bufio"
 	"fmt"
-	"os"
+	"io"
 	"strings"
 	"strconv"
-)
-
-func doLines(filename string, do func(line string) error) error {
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if err := do(line); err != nil {
-			return err
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return err
-	}
 
-	return nil
-}
+	"github.com/usedbytes/aoc2021/input"
+)
 
 type Point struct {
 	X, Y int
 }
 
+// Dots on the fold line don't end up on either side, but Parse makes sure
+// there aren't any
 func makeHorizontalFold(y int) func (Point) Point {
 	return func(in Point) Point {
-		if in.Y == y {
-			panic("dots shouldn't lie on folds")
-		}
-
 		if in.Y < y {
 			return in
 		}
@@ -50,10 +27,6 @@ func makeHorizontalFold(y int) func (Point) Point {
 
 func makeVerticalFold(x int) func (Point) Point {
 	return func(in Point) Point {
-		if in.X == x {
-			panic("dots shouldn't lie on folds")
-		}
-
 		if in.X < x {
 			return in
 		}
@@ -73,88 +46,163 @@ type Instruction struct {
 	Line int
 }
 
-func run() error {
-	coords := []Point{}
-	instructions := []Instruction{}
+// OnFold returns true if 'p' lies on the fold line
+func (ins Instruction) OnFold(p Point) bool {
+	if ins.Direction == "horizontal" {
+		return p.Y == ins.Line
+	}
 
-	reachedInstructions := false
-	if err := doLines(os.Args[1], func(line string) error {
-		if len(line) == 0 {
-			reachedInstructions = true
-			return nil
-		}
+	return p.X == ins.Line
+}
 
-		if !reachedInstructions {
-			split := strings.Split(line, ",")
-			x, err := strconv.Atoi(split[0])
-			if err != nil {
-				return err
-			}
-			y, err := strconv.Atoi(split[1])
-			if err != nil {
-				return err
-			}
+func makeFold(ins Instruction) func(Point) Point {
+	if ins.Direction == "horizontal" {
+		return makeHorizontalFold(ins.Line)
+	}
 
-			coords = append(coords, Point{x, y})
-		} else {
-			split := strings.Split(line, "=")
-			if !strings.HasPrefix(split[0], "fold along ") {
-				return fmt.Errorf("unknown instruction %s", line)
-			}
+	return makeVerticalFold(ins.Line)
+}
 
-			v, err := strconv.Atoi(split[1])
-			if err != nil {
-				return err
-			}
+type Manual struct {
+	Dots         []Point
+	Instructions []Instruction
+}
 
-			dir := ""
-			if split[0][len(split[0])-1] == 'x' {
-				dir = "vertical"
-			} else if split[0][len(split[0])-1] == 'y' {
-				dir = "horizontal"
-			} else {
-				return fmt.Errorf("unknown dir %c", split[0][len(split[0])-1])
-			}
+func parseDot(line string) (Point, error) {
+	split := strings.Split(line, ",")
+	if len(split) != 2 {
+		return Point{}, fmt.Errorf("expected x,y got %s", line)
+	}
 
-			i := Instruction{
-				Direction: dir,
-				Line: v,
-			}
+	x, err := strconv.Atoi(split[0])
+	if err != nil {
+		return Point{}, err
+	}
+	y, err := strconv.Atoi(split[1])
+	if err != nil {
+		return Point{}, input.At(len(split[0])+2, err)
+	}
 
-			instructions = append(instructions, i)
+	return Point{x, y}, nil
+}
+
+func parseInstruction(line string) (Instruction, error) {
+	split := strings.Split(line, "=")
+	if !strings.HasPrefix(split[0], "fold along ") || len(split) != 2 {
+		return Instruction{}, fmt.Errorf("unknown instruction %s", line)
+	}
+
+	v, err := strconv.Atoi(split[1])
+	if err != nil {
+		return Instruction{}, input.At(len(split[0])+2, err)
+	}
+
+	dir := ""
+	if split[0][len(split[0])-1] == 'x' {
+		dir = "vertical"
+	} else if split[0][len(split[0])-1] == 'y' {
+		dir = "horizontal"
+	} else {
+		return Instruction{}, input.At(len(split[0]), fmt.Errorf("unknown dir %c", split[0][len(split[0])-1]))
+	}
+
+	return Instruction{
+		Direction: dir,
+		Line: v,
+	}, nil
+}
+
+func Parse(rd io.Reader) (*Manual, error) {
+	var m Manual
+
+	// The dots, with all the folds so far applied, to check that none of
+	// them lie on the next fold
+	var folded []Point
+
+	nblocks := 0
+	if err := input.DoBlocks(rd, func(block []string) error {
+		nblocks++
+
+		for i, line := range block {
+			switch nblocks {
+			case 1:
+				dot, err := parseDot(line)
+				if err != nil {
+					return input.AtLine(i+1, 0, err)
+				}
+
+				m.Dots = append(m.Dots, dot)
+			case 2:
+				ins, err := parseInstruction(line)
+				if err != nil {
+					return input.AtLine(i+1, 0, err)
+				}
+
+				if folded == nil {
+					folded = append([]Point{}, m.Dots...)
+				}
+
+				fold := makeFold(ins)
+				for j, p := range folded {
+					if ins.OnFold(p) {
+						dot := m.Dots[j]
+						return input.AtLine(i+1, 0, fmt.Errorf("dot %d,%d lies on the fold", dot.X, dot.Y))
+					}
+					folded[j] = fold(p)
+				}
+
+				m.Instructions = append(m.Instructions, ins)
+			default:
+				return fmt.Errorf("unexpected extra section")
+			}
 		}
 
 		return nil
 	}); err != nil {
-		return err
+		return nil, err
 	}
 
+	if len(m.Instructions) == 0 {
+		return nil, &input.ParseError{Err: fmt.Errorf("no fold instructions")}
+	}
+
+	return &m, nil
+}
+
+// Returns a function which applies all of 'instructions' to a Point
+func makeFolds(instructions []Instruction) func(Point) Point {
 	f := func(in Point) Point {
 		return in
 	}
 
-	for i, ins := range instructions {
-		if ins.Direction == "horizontal" {
-			f = wrapXformFunc(f, makeHorizontalFold(ins.Line))
-		} else {
-			f = wrapXformFunc(f, makeVerticalFold(ins.Line))
-		}
+	for _, ins := range instructions {
+		f = wrapXformFunc(f, makeFold(ins))
+	}
 
-		if i == 0 {
-			paper := make(map[Point]bool)
-			for _, c := range coords {
-				paper[f(c)] = true
-			}
+	return f
+}
 
-			fmt.Println("Part 1:", len(paper))
-		}
+// Part1 counts the dots visible after the first fold
+func Part1(m *Manual) (int, error) {
+	f := makeFolds(m.Instructions[:1])
+
+	paper := make(map[Point]bool)
+	for _, c := range m.Dots {
+		paper[f(c)] = true
 	}
 
+	return len(paper), nil
+}
+
+// Part2 returns the letters drawn by the dots after all the folds
+func Part2(m *Manual) (string, error) {
+	f := makeFolds(m.Instructions)
+
 	// Do all the folds, store the eventual canvas dimensions
 	paper := make(map[Point]bool)
 	maxX := 0
 	maxY := 0
-	for _, c := range coords {
+	for _, c := range m.Dots {
 		newc := f(c)
 		paper[newc] = true
 		if newc.X > maxX {
@@ -180,18 +228,10 @@ func run() error {
 		canvas[k.Y][k.X] = '#'
 	}
 
-	// Show the canvas!
-	for _, row := range canvas {
-		fmt.Println(string(row))
+	rows := make([]string, len(canvas))
+	for i, row := range canvas {
+		rows[i] = string(row)
 	}
 
-	return nil
-}
-
-func main() {
-	err := run()
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
+	return strings.Join(rows, "\n"), nil
 }
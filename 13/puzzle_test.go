@@ -0,0 +1,63 @@
+package day13
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/usedbytes/aoc2021/golden"
+)
+
+func TestGolden(t *testing.T) {
+	golden.Test(t, 13)
+}
+
+func TestGenerated(t *testing.T) {
+	golden.Generated(t, 13, 0, 5)
+}
+
+func TestDraw(t *testing.T) {
+	// The last frame is the folded paper, zoomed in to fill the frame,
+	// which should look like part 2's answer
+	golden.Drawn(t, 13, func(t *testing.T, c golden.Case, frames []*image.Paletted) {
+		last := frames[len(frames)-1]
+
+		fullWidth, fullHeight := last.Rect.Dx(), last.Rect.Dy()
+		width, height := fullWidth, fullHeight
+		for _, in := range c.In.(*Manual).Instructions {
+			if in.Direction == "horizontal" {
+				height = in.Line
+			} else {
+				width = in.Line
+			}
+		}
+
+		zoom := fullWidth / width
+		if fullHeight/height < zoom {
+			zoom = fullHeight / height
+		}
+
+		for y, row := range strings.Split(c.Answers["2"], "\n") {
+			for x, ch := range row {
+				dot := last.ColorIndexAt(x*zoom, y*zoom) == 1
+				if dot != (ch == '#') {
+					t.Fatalf("dot at %d,%d is %v, but the answer is\n%s", x, y, dot, c.Answers["2"])
+				}
+			}
+		}
+	})
+}
+
+func TestParseErrors(t *testing.T) {
+	golden.ParseErrors(t, 13, []golden.BadInput{
+		{Name: "bad dot", Input: "6,10\n0,x\n\nfold along y=7\n", Line: 2, Column: 3},
+		{Name: "bad direction", Input: "6,10\n\nfold along z=3\n", Line: 3, Column: 12},
+		{Name: "dot on fold", Input: "6,7\n\nfold along y=7\n", Line: 3, Column: 0},
+		{Name: "dot on later fold", Input: "6,10\n\nfold along y=7\nfold along y=4\n", Line: 4, Column: 0},
+		{Name: "no folds", Input: "6,10\n", Line: 0, Column: 0},
+	})
+}
+
+func BenchmarkInput(b *testing.B) {
+	golden.Benchmark(b, 13)
+}
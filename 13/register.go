@@ -0,0 +1,9 @@
+package day13
+
+import "github.com/usedbytes/aoc2021/solver"
+
+func init() {
+	s := solver.Register(13, Parse, Part1, Part2)
+	s.Generate = Generate
+	solver.SetDrawing(s, "gif", Draw)
+}
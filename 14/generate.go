@@ -0,0 +1,33 @@
+package day14
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+)
+
+// Generate writes a template 'size' elements long (20 by default), and an
+// insertion rule for every pair of the ten elements
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 20
+	}
+
+	const elements = "BCFHKNOPSV"
+
+	bw := bufio.NewWriter(w)
+
+	template := make([]byte, size)
+	for i := range template {
+		template[i] = elements[rnd.Intn(len(elements))]
+	}
+	fmt.Fprintf(bw, "%s\n\n", template)
+
+	for _, i := range rnd.Perm(len(elements) * len(elements)) {
+		a, b := elements[i/len(elements)], elements[i%len(elements)]
+		fmt.Fprintf(bw, "%c%c -> %c\n", a, b, elements[rnd.Intn(len(elements))])
+	}
+
+	return bw.Flush()
+}
@@ -1,33 +1,12 @@
-package main
+package day14
 
 import (
-	"bufio"
 	"fmt"
-	"os"
+	"io"
 	"strings"
-)
-
-func doLines(filename string, do func(line string) error) error {
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
 
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if err := do(line); err != nil {
-			return err
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return err
-	}
-
-	return nil
-}
+	"github.com/usedbytes/aoc2021/input"
+)
 
 // Mutates a
 func add(a *[26]uint64, b [26]uint64) {
@@ -53,7 +32,11 @@ func expandPair(rules map[string]byte, dp map[Tag][26]uint64, pair string, n int
 		return res
 	}
 
-	insert := rules[pair]
+	// Pairs without a rule stay as they are
+	insert, ok := rules[pair]
+	if !ok {
+		return res
+	}
 	res[insert - 'A']++
 
 	add(&res, expandPair(rules, dp, string([]byte{pair[0], insert}), n - 1))
@@ -106,40 +89,75 @@ func max(l []uint64) uint64 {
 	return max
 }
 
-func run() error {
+type Manual struct {
+	Template string
+	Rules    map[string]byte
+}
+
+// Returns the index of the first character of 's' which isn't an element,
+// or -1 if they all are. Elements are upper case letters.
+func badElement(s string) int {
+	for i, c := range s {
+		if c < 'A' || c > 'Z' {
+			return i
+		}
+	}
+
+	return -1
+}
+
+func Parse(rd io.Reader) (*Manual, error) {
 	template := ""
 	rules := make(map[string]byte)
 
-	if err := doLines(os.Args[1], func(line string) error {
+	if err := input.DoLines(rd, func(line string) error {
 		if len(line) == 0 {
 			return nil
 		}
 
 		if len(template) == 0 {
+			if i := badElement(line); i >= 0 {
+				return input.At(i+1, fmt.Errorf("unexpected %q in the template", line[i]))
+			}
 			template = line
 			return nil
 		}
 
-		parts := strings.Split(line, " -> ")
-		rules[parts[0]] = parts[1][0]
+		pair, insert, ok := strings.Cut(line, " -> ")
+		if !ok || len(pair) != 2 || len(insert) != 1 {
+			return fmt.Errorf("expected a rule like AB -> C, got %q", line)
+		}
+
+		if i := badElement(pair + insert); i >= 0 {
+			column := i + 1
+			if i == 2 {
+				column = len(line)
+			}
+			return input.At(column, fmt.Errorf("unexpected %q in the rule", line[column-1]))
+		}
+
+		rules[pair] = insert[0]
 		return nil
 	}); err != nil {
-		return err
+		return nil, err
 	}
 
-	res := expand(rules, template, 10)
-	fmt.Println("Part 1:", max(res[:])-min(res[:]))
+	if len(template) == 0 {
+		return nil, &input.ParseError{Line: 1, Err: io.ErrUnexpectedEOF}
+	}
 
-	res = expand(rules, template, 40)
-	fmt.Println("Part 2:", max(res[:])-min(res[:]))
+	return &Manual{
+		Template: template,
+		Rules: rules,
+	}, nil
+}
 
-	return nil
+func Part1(m *Manual) (int, error) {
+	res := expand(m.Rules, m.Template, 10)
+	return int(max(res[:])-min(res[:])), nil
 }
 
-func main() {
-	err := run()
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
+func Part2(m *Manual) (int, error) {
+	res := expand(m.Rules, m.Template, 40)
+	return int(max(res[:])-min(res[:])), nil
 }
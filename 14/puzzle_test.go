@@ -0,0 +1,44 @@
+package day14
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/usedbytes/aoc2021/golden"
+)
+
+func TestGolden(t *testing.T) {
+	golden.Test(t, 14)
+}
+
+func TestGenerated(t *testing.T) {
+	golden.Generated(t, 14, 0, 5)
+}
+
+func TestParseErrors(t *testing.T) {
+	golden.ParseErrors(t, 14, []golden.BadInput{
+		{Name: "empty", Input: "", Line: 1, Column: 0},
+		{Name: "bad template", Input: "NNcB\n\nCH -> B\n", Line: 1, Column: 3},
+		{Name: "not a rule", Input: "NNCB\n\nCH -> B\nHH N\n", Line: 4, Column: 0},
+		{Name: "long insertion", Input: "NNCB\n\nCH -> BB\n", Line: 3, Column: 0},
+		{Name: "bad pair", Input: "NNCB\n\nC1 -> B\n", Line: 3, Column: 2},
+		{Name: "bad insertion", Input: "NNCB\n\nCH -> b\n", Line: 3, Column: 7},
+	})
+}
+
+func TestMissingRules(t *testing.T) {
+	// Only NN has a rule, so NNCB becomes NCNCB then NCNCB again
+	m, err := Parse(strings.NewReader("NNCB\n\nNN -> C\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := expand(m.Rules, m.Template, 2)
+	if res['N'-'A'] != 2 || res['C'-'A'] != 2 || res['B'-'A'] != 1 {
+		t.Errorf("got %v, want 2 Ns, 2 Cs and a B", res)
+	}
+}
+
+func BenchmarkInput(b *testing.B) {
+	golden.Benchmark(b, 14)
+}
@@ -0,0 +1,37 @@
+package day15
+
+import (
+	"image/color"
+	"io"
+
+	"github.com/usedbytes/aoc2021/grid"
+	"github.com/usedbytes/aoc2021/visual"
+)
+
+// Draw writes a PNG of the full map from part 2, darker where the risk is
+// higher, with the lowest-risk path in red
+func Draw(w io.Writer, cavern *grid.Grid[int], scale int) error {
+	if scale <= 0 {
+		scale = 2
+	}
+
+	full := Tile(cavern)
+	start, _ := full.Bounds()
+	path := Search(full).AStar(start).Path()
+
+	// Risks of 1 to 9 go from light to dark (0 isn't used), and 10 is the
+	// path
+	palette := append(color.Palette{color.Black},
+		visual.Gradient(color.RGBA{0xe0, 0xe8, 0xd0, 0xff}, color.RGBA{0x20, 0x40, 0x20, 0xff}, 9)...)
+	palette = append(palette, color.RGBA{0xe0, 0x20, 0x20, 0xff})
+
+	img := visual.Grid(full, scale, palette, func(v int) uint8 {
+		return uint8(v)
+	})
+
+	for _, p := range path {
+		visual.Cell(img, start, p, scale, 10)
+	}
+
+	return visual.WritePNG(w, img)
+}
@@ -0,0 +1,24 @@
+package day15
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+
+	"github.com/usedbytes/aoc2021/grid"
+)
+
+// Generate writes a 'size' x 'size' map of risk levels (100 by default)
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 100
+	}
+
+	risk := grid.NewDense(size, size, 0)
+	risk.Each(func(p Point, _ int) {
+		risk.Set(p, 1+rnd.Intn(9))
+	})
+
+	_, err := fmt.Fprintln(w, risk)
+	return err
+}
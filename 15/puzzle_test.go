@@ -0,0 +1,49 @@
+package day15
+
+import (
+	"fmt"
+	"image"
+	"testing"
+
+	"github.com/usedbytes/aoc2021/golden"
+	"github.com/usedbytes/aoc2021/grid"
+)
+
+func TestGolden(t *testing.T) {
+	golden.Test(t, 15)
+}
+
+func TestGenerated(t *testing.T) {
+	golden.Generated(t, 15, 20, 5)
+}
+
+func TestDraw(t *testing.T) {
+	// The path is colour 10, and its risk (not counting where it starts)
+	// is part 2's answer
+	golden.Drawn(t, 15, func(t *testing.T, c golden.Case, frames []*image.Paletted) {
+		full := Tile(c.In.(*grid.Grid[int]))
+		start, _ := full.Bounds()
+		img := frames[0]
+
+		if img.ColorIndexAt(0, 0) != 10 {
+			t.Error("the path doesn't start in the top left")
+		}
+
+		risk := -full.Get(start)
+		for y := 0; y < img.Rect.Dy(); y++ {
+			for x := 0; x < img.Rect.Dx(); x++ {
+				if img.ColorIndexAt(x, y) == 10 {
+					risk += full.Get(Point{X: start.X + x, Y: start.Y + y})
+				}
+			}
+		}
+
+		if got, want := fmt.Sprint(risk), c.Answers["2"]; got != want {
+			t.Errorf("the path drawn has risk %s, want %s", got, want)
+		}
+	})
+}
+
+func BenchmarkInput(b *testing.B) {
+	golden.Benchmark(b, 15)
+}
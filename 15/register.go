@@ -0,0 +1,9 @@
+package day15
+
+import "github.com/usedbytes/aoc2021/solver"
+
+func init() {
+	s := solver.Register(15, Parse, Part1, Part2)
+	s.Generate = Generate
+	solver.SetDrawing(s, "png", Draw)
+}
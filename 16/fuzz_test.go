@@ -0,0 +1,103 @@
+package day16
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/usedbytes/aoc2021/input"
+)
+
+func FuzzParse(f *testing.F) {
+	for _, s := range []string{
+		"D2FE28",
+		"38006F45291200",
+		"EE00D40C823060",
+		"C200B40A82",
+		"A0016C880162017C3686B18A3D4780",
+		"9C0141080250320F1802104A08",
+	} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		// Mustn't panic, and any error should say where it is
+		p, err := Parse(strings.NewReader(s))
+		if err != nil {
+			var pe *input.ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("%q: expected a ParseError, got %v", s, err)
+			}
+			return
+		}
+
+		// Re-encoding it (maybe differently) should decode the same
+		bits := encode(p, rand.New(rand.NewSource(int64(len(s)))))
+		got, _, err := decodePacket(bits, 0)
+		if err != nil {
+			t.Fatalf("%q: re-encoded packet didn't decode: %v", s, err)
+		}
+
+		if !samePacket(got, p) {
+			t.Fatalf("%q: re-encoded packet decoded differently", s)
+		}
+	})
+}
+
+func samePacket(a, b *Packet) bool {
+	if a.Version != b.Version || a.Type != b.Type || a.Value != b.Value || len(a.Children) != len(b.Children) {
+		return false
+	}
+
+	for i := range a.Children {
+		if !samePacket(a.Children[i], b.Children[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestRoundTrip(t *testing.T) {
+	rnd := rand.New(rand.NewSource(16))
+
+	for i := 0; i < 500; i++ {
+		want := randomPacket(rnd, 4)
+		bits := encode(want, rnd)
+
+		got, n, err := decodePacket(bits, 0)
+		if err != nil {
+			t.Fatalf("%s: %v", bits, err)
+		}
+
+		if n != len(bits) {
+			t.Errorf("%s: decoded %d bits, want %d", bits, n, len(bits))
+		}
+
+		if !samePacket(got, want) {
+			t.Errorf("%s: decoded differently", bits)
+		}
+	}
+}
+
+func TestGeneratedValue(t *testing.T) {
+	rnd := rand.New(rand.NewSource(16))
+
+	for i := 0; i < 100; i++ {
+		hex, want := randomTransmission(rnd, 6)
+
+		got, err := Parse(strings.NewReader(hex))
+		if err != nil {
+			t.Fatalf("%s: %v", hex, err)
+		}
+
+		if !samePacket(got, want) {
+			t.Fatalf("%s: decoded differently", hex)
+		}
+
+		if v, _ := Part2(got); v != want.Value {
+			t.Errorf("%s: got value %d, want %d", hex, v, want.Value)
+		}
+	}
+}
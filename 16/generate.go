@@ -0,0 +1,142 @@
+package day16
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+)
+
+// Encodes 'p' as bits, picking the length type of operators at random
+func encode(p *Packet, rnd *rand.Rand) string {
+	s := fmt.Sprintf("%03b%03b", p.Version, p.Type)
+
+	if p.Type == 4 {
+		var chunks []string
+		v := p.Value
+		for {
+			chunks = append([]string{fmt.Sprintf("%04b", v&0xf)}, chunks...)
+			v >>= 4
+			if v == 0 {
+				break
+			}
+		}
+
+		for i, c := range chunks {
+			if i == len(chunks)-1 {
+				s += "0" + c
+			} else {
+				s += "1" + c
+			}
+		}
+
+		return s
+	}
+
+	children := ""
+	for _, c := range p.Children {
+		children += encode(c, rnd)
+	}
+
+	// Use whichever length type fits, if only one does
+	lengthType := rnd.Intn(2)
+	if len(children) >= 1<<15 {
+		lengthType = 1
+	} else if len(p.Children) >= 1<<11 {
+		lengthType = 0
+	}
+
+	if lengthType == 0 {
+		return s + fmt.Sprintf("0%015b", len(children)) + children
+	}
+
+	return s + fmt.Sprintf("1%011b", len(p.Children)) + children
+}
+
+// Returns a random packet tree, with values calculated as the puzzle says
+func randomPacket(rnd *rand.Rand, depth int) *Packet {
+	p := &Packet{
+		Version: rnd.Intn(8),
+		Type:    rnd.Intn(8),
+	}
+
+	if depth == 0 {
+		p.Type = 4
+	}
+
+	switch p.Type {
+	case 4:
+		p.Value = rnd.Intn(1 << 20)
+		return p
+	case 5, 6, 7:
+		p.Children = []*Packet{randomPacket(rnd, depth-1), randomPacket(rnd, depth-1)}
+	default:
+		n := 1 + rnd.Intn(4)
+		for i := 0; i < n; i++ {
+			p.Children = append(p.Children, randomPacket(rnd, depth-1))
+		}
+	}
+
+	a, b := p.Children[0].Value, p.Children[1%len(p.Children)].Value
+	switch p.Type {
+	case 0, 1, 2, 3:
+		p.Value = a
+		for _, c := range p.Children[1:] {
+			v := c.Value
+			switch {
+			case p.Type == 0:
+				p.Value += v
+			case p.Type == 1:
+				p.Value *= v
+			case p.Type == 2 && v < p.Value, p.Type == 3 && v > p.Value:
+				p.Value = v
+			}
+		}
+	case 5:
+		p.Value = boolToInt(a > b)
+	case 6:
+		p.Value = boolToInt(a < b)
+	case 7:
+		p.Value = boolToInt(a == b)
+	}
+
+	return p
+}
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// randomTransmission returns a random transmission in hex, and the packet
+// it decodes to
+func randomTransmission(rnd *rand.Rand, depth int) (string, *Packet) {
+	p := randomPacket(rnd, depth)
+
+	bits := encode(p, rnd)
+	for len(bits)%4 != 0 {
+		bits += "0"
+	}
+
+	hex := make([]byte, 0, len(bits)/4)
+	for i := 0; i < len(bits); i += 4 {
+		v, _ := strconv.ParseUint(bits[i:i+4], 2, 4)
+		hex = append(hex, "0123456789ABCDEF"[v])
+	}
+
+	return string(hex), p
+}
+
+// Generate writes a transmission with packets nested up to 'size' deep (6 by
+// default)
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 6
+	}
+
+	hex, _ := randomTransmission(rnd, size)
+	_, err := fmt.Fprintln(w, hex)
+	return err
+}
@@ -1,10 +1,11 @@
-package main
+package day16
 
 import (
 	"fmt"
-	"io/ioutil"
-	"os"
-	"strconv"
+	"io"
+	"math"
+
+	"github.com/usedbytes/aoc2021/input"
 )
 
 type PacketType int
@@ -18,67 +19,101 @@ type Packet struct {
 	Children []*Packet
 }
 
-// Returns value, consumed
-func decodeLiteral(s string) (int, int) {
-	idx := 0
+// Returns an error for the bit at 'idx'. Each hex digit is 4 bits, so the
+// column is the digit which the bit came from.
+func bitError(idx int, format string, args ...interface{}) error {
+	return input.AtLine(1, idx/4+1, fmt.Errorf("bit %d: %s", idx, fmt.Sprintf(format, args...)))
+}
+
+// Reads the 'n' bit number starting at s[idx]
+func readBits(s string, idx, n int) (int, error) {
+	if idx+n > len(s) {
+		return 0, bitError(idx, "want %d bits, only %d left", n, len(s)-idx)
+	}
+
+	v := 0
+	for _, b := range s[idx:idx+n] {
+		v = (v << 1) | int(b - '0')
+	}
+
+	return v, nil
+}
+
+// Returns value, next index
+func decodeLiteral(s string, idx int) (int, int, error) {
 	val := 0
 
 	for {
-		chunk, err := strconv.ParseUint(s[idx+1:idx+5], 2, 4)
+		chunk, err := readBits(s, idx, 5)
 		if err != nil {
-			panic(err)
+			return 0, 0, err
+		}
+
+		if val > math.MaxInt >> 4 {
+			return 0, 0, bitError(idx, "literal is too big")
 		}
 
 		val *= 16
-		val += int(chunk)
+		val += chunk & 0xf
+
+		// Account for this chunk
+		idx += 5
 
-		if s[idx] == '0' {
+		if chunk & 0x10 == 0 {
 			break
 		}
-
-		idx += 5
 	}
 
-	// Account for the last chunk
-	idx += 5
-
-	return val, idx
+	return val, idx, nil
 }
 
-func decodeOperatorChildren(s string) ([]*Packet, int) {
-	idx := 0
-	ltype := s[0]
+// Returns children, next index
+func decodeOperatorChildren(s string, idx int) ([]*Packet, int, error) {
+	ltype, err := readBits(s, idx, 1)
+	if err != nil {
+		return nil, 0, err
+	}
 	idx += 1
 
 	children := []*Packet{}
 	switch ltype {
-	case '0':
-		sublength, err := strconv.ParseUint(s[idx:idx+15], 2, 15)
+	case 0:
+		sublength, err := readBits(s, idx, 15)
 		if err != nil {
-			panic(err)
+			return nil, 0, err
 		}
 		idx += 15
-		n := 0
-		for n < int(sublength) {
-			p, m := decodePacket(s[idx:])
+
+		end := idx + sublength
+		for idx < end {
+			var p *Packet
+			p, idx, err = decodePacket(s, idx)
+			if err != nil {
+				return nil, 0, err
+			}
 			children = append(children, p)
-			n += m
-			idx += m
 		}
-	case '1':
-		subpkts, err := strconv.ParseUint(s[idx:idx+11], 2, 11)
+
+		if idx != end {
+			return nil, 0, bitError(idx, "sub-packets overran their length by %d bits", idx - end)
+		}
+	case 1:
+		subpkts, err := readBits(s, idx, 11)
 		if err != nil {
-			panic(err)
+			return nil, 0, err
 		}
 		idx += 11
-		for n := 0; n < int(subpkts); n++ {
-			p, m := decodePacket(s[idx:])
+		for n := 0; n < subpkts; n++ {
+			var p *Packet
+			p, idx, err = decodePacket(s, idx)
+			if err != nil {
+				return nil, 0, err
+			}
 			children = append(children, p)
-			idx += m
 		}
 	}
 
-	return children, idx
+	return children, idx, nil
 }
 
 func sum(ps []*Packet) int {
@@ -157,36 +192,40 @@ func eq(ps []*Packet) int {
 	return 0
 }
 
-func decodePacket(s string) (*Packet, int) {
-	idx := 0
-	ver, err := strconv.ParseUint(s[idx:idx+3], 2, 3)
+// Decodes the packet starting at s[idx]. Returns the packet, next index
+func decodePacket(s string, idx int) (*Packet, int, error) {
+	start := idx
+
+	ver, err := readBits(s, idx, 3)
 	if err != nil {
-		panic(err)
+		return nil, 0, err
 	}
 	idx += 3
 
-	t, err := strconv.ParseUint(s[idx:idx+3], 2, 3)
+	t, err := readBits(s, idx, 3)
 	if err != nil {
-		panic(err)
+		return nil, 0, err
 	}
 	idx += 3
 
 	p := &Packet{
-		Version: int(ver),
-		Type: int(t),
+		Version: ver,
+		Type: t,
 	}
 
 	switch t {
 	case 4:
 		// Literal
-		v, c := decodeLiteral(s[idx:])
-		idx += c
-		p.Value = v
+		p.Value, idx, err = decodeLiteral(s, idx)
+		if err != nil {
+			return nil, 0, err
+		}
 	default:
 		// Operator
-		ps, c := decodeOperatorChildren(s[idx:])
-		p.Children = ps
-		idx += c
+		p.Children, idx, err = decodeOperatorChildren(s, idx)
+		if err != nil {
+			return nil, 0, err
+		}
 
 		var f func([]*Packet) int
 		switch t {
@@ -204,14 +243,17 @@ func decodePacket(s string) (*Packet, int) {
 			f = lt
 		case 7:
 			f = eq
-		default:
-			panic(fmt.Sprintf("unknown operator %d", t))
 		}
 
-		p.Value = f(ps)
+		// Comparisons always have exactly two sub-packets
+		if t >= 5 && len(p.Children) != 2 {
+			return nil, 0, bitError(start, "comparison has %d sub-packets, want 2", len(p.Children))
+		}
+
+		p.Value = f(p.Children)
 	}
 
-	return p, idx
+	return p, idx, nil
 }
 
 func sumVersions(p *Packet) int {
@@ -223,16 +265,11 @@ func sumVersions(p *Packet) int {
 	return v
 }
 
-func run() error {
-	f, err := os.Open(os.Args[1])
+// Parse decodes the hex transmission into its outermost packet
+func Parse(rd io.Reader) (*Packet, error) {
+	line, err := input.Line(rd)
 	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	bs, err := ioutil.ReadAll(f)
-	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Simpler than using strconv
@@ -258,23 +295,26 @@ func run() error {
 	}
 
 	s := ""
-	for _, b := range bs {
-		s += lut[b]
+	for i, b := range []byte(line) {
+		bits, ok := lut[b]
+		if !ok {
+			return nil, input.AtLine(1, i+1, fmt.Errorf("invalid hex digit %q", b))
+		}
+		s += bits
 	}
 
-	p, _ := decodePacket(s)
-
-	fmt.Println("Part 1:", sumVersions(p))
+	p, _, err := decodePacket(s, 0)
+	if err != nil {
+		return nil, err
+	}
 
-	fmt.Println("Part 2:", p.Value)
+	return p, nil
+}
 
-	return nil
+func Part1(p *Packet) (int, error) {
+	return sumVersions(p), nil
 }
 
-func main() {
-	err := run()
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
+func Part2(p *Packet) (int, error) {
+	return p.Value, nil
 }
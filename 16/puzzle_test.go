@@ -0,0 +1,27 @@
+package day16
+
+import (
+	"testing"
+
+	"github.com/usedbytes/aoc2021/golden"
+)
+
+func TestGolden(t *testing.T) {
+	golden.Test(t, 16)
+}
+
+func TestGenerated(t *testing.T) {
+	golden.Generated(t, 16, 0, 5)
+}
+
+func TestParseErrors(t *testing.T) {
+	golden.ParseErrors(t, 16, []golden.BadInput{
+		{Name: "bad hex", Input: "8A00G0\n", Line: 1, Column: 5},
+		{Name: "truncated literal", Input: "D2FE2\n", Line: 1, Column: 5},
+		{Name: "comparison with one operand", Input: "16004408\n", Line: 1, Column: 1},
+	})
+}
+
+func BenchmarkInput(b *testing.B) {
+	golden.Benchmark(b, 16)
+}
@@ -0,0 +1,34 @@
+package day17
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+)
+
+// Generate writes a target area up to about 'size' away from the probe (150
+// by default, and at least 8). As in the real input, there's always an X
+// velocity which stops inside the target.
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 150
+	}
+	if size < 8 {
+		size = 8
+	}
+
+	for {
+		x1 := size/2 + rnd.Intn(size/2)
+		x2 := x1 + size/8 + rnd.Intn(size/4)
+		y1 := -size/2 - rnd.Intn(size/2)
+		y2 := y1 + size/8 + rnd.Intn(size/4)
+
+		// Find a triangular number in x1..x2
+		for v := 1; v*(v+1)/2 <= x2; v++ {
+			if v*(v+1)/2 >= x1 {
+				_, err := fmt.Fprintf(w, "target area: x=%d..%d, y=%d..%d\n", x1, x2, y1, y2)
+				return err
+			}
+		}
+	}
+}
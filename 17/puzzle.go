@@ -1,9 +1,10 @@
-package main
+package day17
 
 import (
 	"fmt"
-	"io/ioutil"
-	"os"
+	"io"
+
+	"github.com/usedbytes/aoc2021/input"
 )
 
 func abs(a int) int {
@@ -20,26 +21,32 @@ func max(a, b int) int {
 	return b
 }
 
-func run() error {
+type Target struct {
+	X1, X2, Y1, Y2 int
+}
 
-	f, err := os.Open(os.Args[1])
+func Parse(rd io.Reader) (Target, error) {
+	line, err := input.Line(rd)
 	if err != nil {
-		return err
+		return Target{}, err
 	}
-	defer f.Close()
 
-	bs, err := ioutil.ReadAll(f)
+	var t Target
+	_, err = fmt.Sscanf(line, "target area: x=%d..%d, y=%d..%d", &t.X1, &t.X2, &t.Y1, &t.Y2)
 	if err != nil {
-		return err
+		return Target{}, err
 	}
 
-	var x1, x2, y1, y2 int
-	fmt.Sscanf(string(bs), "target area: x=%d..%d, y=%d..%d", &x1, &x2, &y1, &y2)
+	return t, nil
+}
 
+// Returns the highest Y position reached by any trajectory, and the number
+// of distinct initial velocities which hit the target
+func search(t Target) (int, int) {
 	// The Y velocity can't be any more than the furthest edge of the
 	// region, otherwise we'll overshoot on the first step after
 	// leaving (or arriving back at) y = 0. So this sets our Y bounds.
-	maxYVel := max(abs(y1), abs(y2))
+	maxYVel := max(abs(t.Y1), abs(t.Y2))
 
 	maxYPos := 0
 	hits := make(map[[2]int]bool)
@@ -55,12 +62,12 @@ func run() error {
 				peak = y
 			}
 
-			if y > y2 {
+			if y > t.Y2 {
 				// Not there yet
 				continue
 			}
 
-			if y < y1 {
+			if y < t.Y1 {
 				// Overshot
 				break
 			}
@@ -80,7 +87,7 @@ func run() error {
 			// is our lower bound.
 			// Note: We could save these results somewhere instead
 			// of re-searching each time.
-			for ux := 0; ux <= max(x1, x2); ux++ {
+			for ux := 0; ux <= max(t.X1, t.X2); ux++ {
 				x := 0
 				vx := ux
 				// Simulate trajectory up to 'ty'
@@ -92,24 +99,22 @@ func run() error {
 				}
 
 				// And then check if we're inside the target
-				if x >= x1 && x <= x2 {
+				if x >= t.X1 && x <= t.X2 {
 					hits[[2]int{ux, uy}] = true
 				}
 			}
 		}
 	}
 
+	return maxYPos, len(hits)
+}
 
-	fmt.Println("Part 1:", maxYPos)
-	fmt.Println("Part 2:", len(hits))
-
-	return nil
+func Part1(t Target) (int, error) {
+	maxYPos, _ := search(t)
+	return maxYPos, nil
 }
 
-func main() {
-	err := run()
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
+func Part2(t Target) (int, error) {
+	_, nhits := search(t)
+	return nhits, nil
 }
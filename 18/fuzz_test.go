@@ -0,0 +1,144 @@
+package day18
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/usedbytes/aoc2021/input"
+)
+
+// Formats 's' the same way as the input
+func format(s SFN) string {
+	var sb strings.Builder
+	for i, t := range s {
+		if i > 0 && t != TokenClose && s[i-1] != TokenOpen {
+			sb.WriteByte(',')
+		}
+
+		switch t {
+		case TokenOpen:
+			sb.WriteByte('[')
+		case TokenClose:
+			sb.WriteByte(']')
+		default:
+			sb.WriteString(string(rune('0' + t)))
+		}
+	}
+
+	return sb.String()
+}
+
+func equal(a, b SFN) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Checks that 's' is fully reduced: nothing nested inside four pairs, and
+// no regular numbers over 9
+func checkReduced(t *testing.T, s SFN) {
+	t.Helper()
+
+	depth := 0
+	for _, tok := range s {
+		switch tok {
+		case TokenOpen:
+			depth++
+			if depth > 4 {
+				t.Fatalf("%s is nested too deeply", format(s))
+			}
+		case TokenClose:
+			depth--
+		default:
+			if tok > 9 {
+				t.Fatalf("%s has a number over 9", format(s))
+			}
+		}
+	}
+}
+
+func FuzzParseSFN(f *testing.F) {
+	for _, s := range []string{
+		"[1,2]",
+		"[[1,2],3]",
+		"[[[[[9,8],1],2],3],4]",
+		"[[[[[4,3],4],4],[7,[[8,4],9]]],[1,1]]",
+		"[[[0,[5,8]],[[1,7],[9,6]]],[[4,[1,2]],[[1,4],2]]]",
+		"[[[[0,7],4],[15,[0,13]]],[1,1]]",
+	} {
+		f.Add(s)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		sfn, err := ParseSFN(s)
+		if err != nil {
+			var pe *input.ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("%q: expected a ParseError, got %v", s, err)
+			}
+			return
+		}
+
+		checkReduced(t, sfn)
+
+		// Reducing again shouldn't change anything
+		again := append(SFN{}, sfn...)
+		again.Reduce()
+		if !equal(again, sfn) {
+			t.Fatalf("%q: reduced again to %s, was %s", s, format(again), format(sfn))
+		}
+
+		// It should parse back to the same thing
+		re, err := ParseSFN(format(sfn))
+		if err != nil {
+			t.Fatalf("%q: formatted %s doesn't parse: %v", s, format(sfn), err)
+		}
+		if !equal(re, sfn) {
+			t.Fatalf("%q: %s parsed back as %s", s, format(sfn), format(re))
+		}
+
+		// Any valid number has a magnitude
+		Magnitude(sfn)
+	})
+}
+
+func TestAddProperties(t *testing.T) {
+	rnd := rand.New(rand.NewSource(18))
+
+	for i := 0; i < 1000; i++ {
+		a, err := ParseSFN(randomSFN(rnd, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := ParseSFN(randomSFN(rnd, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Add mustn't modify its arguments
+		ac := append(SFN{}, a...)
+		bc := append(SFN{}, b...)
+
+		sum := Add(a, b)
+		checkReduced(t, sum)
+
+		if !equal(a, ac) || !equal(b, bc) {
+			t.Fatalf("Add(%s, %s) modified its arguments", format(ac), format(bc))
+		}
+
+		// Adding nothing is a no-op
+		if !equal(Add(nil, a), a) || !equal(Add(a, nil), a) {
+			t.Fatalf("adding nothing to %s changed it", format(a))
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package day18
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+)
+
+// Returns a random reduced snailfish number
+func randomSFN(rnd *rand.Rand, depth int) string {
+	if depth == 4 || rnd.Intn(3) == 0 {
+		return string(rune('0' + rnd.Intn(10)))
+	}
+
+	return "[" + randomSFN(rnd, depth+1) + "," + randomSFN(rnd, depth+1) + "]"
+}
+
+// Generate writes 'size' reduced snailfish numbers (100 by default)
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 100
+	}
+
+	bw := bufio.NewWriter(w)
+
+	for i := 0; i < size; i++ {
+		// Every line is a pair, never just a regular number
+		fmt.Fprintf(bw, "[%s,%s]\n", randomSFN(rnd, 1), randomSFN(rnd, 1))
+	}
+
+	return bw.Flush()
+}
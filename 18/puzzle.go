@@ -1,34 +1,12 @@
-package main
+package day18
 
 import (
-	"bufio"
 	"fmt"
-	"os"
+	"io"
 	"strconv"
-	"strings"
-)
-
-func doLines(filename string, do func(line string) error) error {
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if err := do(line); err != nil {
-			return err
-		}
-	}
 
-	if err := scanner.Err(); err != nil {
-		return err
-	}
-
-	return nil
-}
+	"github.com/usedbytes/aoc2021/input"
+)
 
 type Token int
 const (
@@ -38,30 +16,82 @@ const (
 
 type SFN []Token
 
-func ParseSFN(s string) SFN {
+// Reducing takes time in proportion to the size of the regular numbers, so
+// ParseSFN doesn't allow them to be enormous
+const maxRegular = 9999
+
+// ParseSFN parses and reduces a snailfish number. Regular numbers can have
+// more than one digit, as they do before reduction, up to maxRegular.
+func ParseSFN(s string) (SFN, error) {
 	var sfn SFN
+	i := 0
+
+	expect := func(c byte) error {
+		if i >= len(s) {
+			return input.At(i+1, fmt.Errorf("expected %q, got end of line", c))
+		} else if s[i] != c {
+			return input.At(i+1, fmt.Errorf("expected %q, got %q", c, s[i]))
+		}
+		i++
+		return nil
+	}
+
+	// Parses a regular number or a pair, starting at s[i]
+	var element func() error
+	element = func() error {
+		if i >= len(s) {
+			return input.At(i+1, fmt.Errorf("expected a number or pair, got end of line"))
+		}
 
-	for i := 0; i < len(s); i++ {
 		l := s[i]
-		if l == '[' {
-			sfn = append(sfn, TokenOpen)
-		} else if l == ']' {
-			sfn = append(sfn, TokenClose)
-		} else if l >= '0' && l <= '9' {
-			count := strings.IndexAny(s[i:], "[],")
-
-			v, err := strconv.Atoi(s[i:i+count])
-			if err != nil {
-				panic(err)
+		if l >= '0' && l <= '9' {
+			start := i
+			for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+				i++
 			}
 
+			v, err := strconv.Atoi(s[start:i])
+			if err != nil {
+				return input.At(start+1, err)
+			} else if v > maxRegular {
+				return input.At(start+1, fmt.Errorf("%d is too big, the most is %d", v, maxRegular))
+			}
 			sfn = append(sfn, Token(v))
-			i += count - 1
+			return nil
+		} else if l != '[' {
+			return input.At(i+1, fmt.Errorf("expected a number or pair, got %q", l))
+		}
+
+		sfn = append(sfn, TokenOpen)
+		i++
+
+		if err := element(); err != nil {
+			return err
+		}
+		if err := expect(','); err != nil {
+			return err
+		}
+		if err := element(); err != nil {
+			return err
+		}
+		if err := expect(']'); err != nil {
+			return err
 		}
+
+		sfn = append(sfn, TokenClose)
+		return nil
+	}
+
+	if err := element(); err != nil {
+		return nil, err
+	}
+
+	if i != len(s) {
+		return nil, input.At(i+1, fmt.Errorf("unexpected %q after the number", s[i]))
 	}
 
 	sfn.Reduce()
-	return sfn
+	return sfn, nil
 }
 
 func (s SFN) String() string {
@@ -79,16 +109,10 @@ func (s SFN) String() string {
 	return res
 }
 
-func assert(cond bool, msg string) {
-	if !cond {
-		panic(msg)
-	}
-}
-
+// Explodes the pair starting at old[idx], which must be two regular numbers
 func explode(old SFN, idx int) SFN {
 	// Scan left for numbers
 	left := old[idx+1]
-	assert(left >= 0, "left not a number")
 	for j := idx; j >= 0; j-- {
 		if old[j] < 0 {
 			continue
@@ -99,7 +123,6 @@ func explode(old SFN, idx int) SFN {
 
 	// Scan right for numbers
 	right := old[idx+2]
-	assert(right >= 0, "right not a number")
 	for j := idx+3; j < len(old); j++ {
 		if old[j] < 0 {
 			continue
@@ -143,7 +166,10 @@ func (s *SFN) Reduce() {
 			if tok == TokenOpen {
 				depth += 1
 
-				if depth > 4 {
+				// Numbers which haven't been reduced could
+				// be nested more deeply, so only explode
+				// pairs of regular numbers
+				if depth > 4 && old[i+1] >= 0 && old[i+2] >= 0 {
 					reduced = explode(old, i)
 					break
 				}
@@ -212,21 +238,44 @@ func Magnitude(s SFN) int {
 	return v
 }
 
-func run() error {
+func Parse(rd io.Reader) ([]SFN, error) {
 	var sfns []SFN
-	var res SFN
-	doLine := func(line string) error {
-		sfn := ParseSFN(line)
+
+	if err := input.DoLines(rd, func(line string) error {
+		if len(line) == 0 {
+			return nil
+		}
+
+		sfn, err := ParseSFN(line)
+		if err != nil {
+			return err
+		}
+
 		sfns = append(sfns, sfn)
-		res = Add(res, sfn)
 		return nil
+	}); err != nil {
+		return nil, err
 	}
-	if err := doLines(os.Args[1], doLine); err != nil {
-		return err
+
+	if len(sfns) == 0 {
+		return nil, &input.ParseError{Line: 1, Err: io.ErrUnexpectedEOF}
 	}
 
-	fmt.Println("Part 1:", Magnitude(res))
+	return sfns, nil
+}
 
+// Part1 returns the magnitude of the sum of all the numbers
+func Part1(sfns []SFN) (int, error) {
+	var res SFN
+	for _, sfn := range sfns {
+		res = Add(res, sfn)
+	}
+
+	return Magnitude(res), nil
+}
+
+// Part2 returns the largest magnitude from adding any two of the numbers
+func Part2(sfns []SFN) (int, error) {
 	largestMag := 0
 	for i := 0; i < len(sfns); i++ {
 		for j := 0; j < len(sfns); j++ {
@@ -246,15 +295,5 @@ func run() error {
 		}
 	}
 
-	fmt.Println("Part 2:", largestMag)
-
-	return nil
-}
-
-func main() {
-	err := run()
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
+	return largestMag, nil
 }
@@ -0,0 +1,42 @@
+package day18
+
+import (
+	"testing"
+
+	"github.com/usedbytes/aoc2021/golden"
+)
+
+func TestGolden(t *testing.T) {
+	golden.Test(t, 18)
+}
+
+func TestGenerated(t *testing.T) {
+	golden.Generated(t, 18, 20, 5)
+}
+
+func TestParseErrors(t *testing.T) {
+	golden.ParseErrors(t, 18, []golden.BadInput{
+		{Name: "bad number", Input: "[1,2]\n[3,x]\n", Line: 2, Column: 4},
+		{Name: "unclosed", Input: "[1,2\n", Line: 1, Column: 5},
+		{Name: "trailing", Input: "[1,2]]\n", Line: 1, Column: 6},
+		{Name: "too big", Input: "[1,10000]\n", Line: 1, Column: 4},
+		{Name: "empty", Input: "", Line: 1, Column: 0},
+		{Name: "blank lines", Input: "\n\n", Line: 1, Column: 0},
+	})
+}
+
+func TestParseSFN(t *testing.T) {
+	// Not reduced yet, from the puzzle's explanation of splitting
+	sfn, err := ParseSFN("[[[[0,7],4],[15,[0,13]]],[1,1]]")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := format(sfn), "[[[[0,7],4],[[7,8],[6,0]]],[8,1]]"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func BenchmarkInput(b *testing.B) {
+	golden.Benchmark(b, 18)
+}
@@ -0,0 +1,8 @@
+package day18
+
+import "github.com/usedbytes/aoc2021/solver"
+
+func init() {
+	s := solver.Register(18, Parse, Part1, Part2)
+	s.Generate = Generate
+}
@@ -0,0 +1,124 @@
+package day19
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+)
+
+// Generate writes 'size' scanners (30 by default), each of which can see
+// beacons up to 1000 away on each axis, and is rotated at random. Each
+// scanner shares at least 12 beacons with one of the scanners before it, so
+// they can all be located.
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 30
+	}
+
+	const reach = 1000
+
+	var beacons []Point
+	seen := make(map[Point]bool)
+
+	// Adds 'n' new beacons somewhere in lo..hi
+	addBeacons := func(lo, hi Point, n int) {
+		for n > 0 {
+			b := Point{
+				lo.X + rnd.Intn(hi.X-lo.X+1),
+				lo.Y + rnd.Intn(hi.Y-lo.Y+1),
+				lo.Z + rnd.Intn(hi.Z-lo.Z+1),
+			}
+			if !seen[b] {
+				seen[b] = true
+				beacons = append(beacons, b)
+				n--
+			}
+		}
+	}
+
+	around := func(s Point) (Point, Point) {
+		return s.Sub(Point{reach, reach, reach}), s.Add(Point{reach, reach, reach})
+	}
+
+	// Between 600 and 1200 away from another scanner, in either direction
+	offset := func() int {
+		return (600 + rnd.Intn(601)) * (1 - 2*rnd.Intn(2))
+	}
+
+	// Whether 's' is too close to any of the other scanners, which would
+	// make them see too many of the same beacons
+	tooClose := func(s Point, scanners []Point) bool {
+		for _, q := range scanners {
+			d := s.Sub(q)
+			if abs(d.X) < 600 && abs(d.Y) < 600 && abs(d.Z) < 600 {
+				return true
+			}
+		}
+		return false
+	}
+
+	scanners := []Point{{0, 0, 0}}
+	for len(scanners) < size {
+		parent := scanners[rnd.Intn(len(scanners))]
+		s := parent.Add(Point{offset(), offset(), offset()})
+		if tooClose(s, scanners) {
+			continue
+		}
+
+		plo, phi := around(parent)
+		slo, shi := around(s)
+		lo := Point{max(plo.X, slo.X), max(plo.Y, slo.Y), max(plo.Z, slo.Z)}
+		hi := Point{min(phi.X, shi.X), min(phi.Y, shi.Y), min(phi.Z, shi.Z)}
+		addBeacons(lo, hi, 12)
+
+		scanners = append(scanners, s)
+	}
+
+	for _, s := range scanners {
+		lo, hi := around(s)
+		addBeacons(lo, hi, 10)
+	}
+
+	bw := bufio.NewWriter(w)
+
+	for i, s := range scanners {
+		rot := RotFuncs[0]
+		if i > 0 {
+			rot = RotFuncs[rnd.Intn(len(RotFuncs))]
+		}
+
+		var visible []Point
+		for _, b := range beacons {
+			d := b.Sub(s)
+			if abs(d.X) <= reach && abs(d.Y) <= reach && abs(d.Z) <= reach {
+				visible = append(visible, rot(d))
+			}
+		}
+		rnd.Shuffle(len(visible), func(i, j int) { visible[i], visible[j] = visible[j], visible[i] })
+
+		if i > 0 {
+			fmt.Fprintln(bw)
+		}
+		fmt.Fprintf(bw, "--- scanner %d ---\n", i)
+		for _, b := range visible {
+			fmt.Fprintf(bw, "%d,%d,%d\n", b.X, b.Y, b.Z)
+		}
+	}
+
+	return bw.Flush()
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
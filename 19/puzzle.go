@@ -1,33 +1,16 @@
-package main
+package day19
 
 import (
-	"bufio"
+	"context"
 	"fmt"
-	"os"
-	"strings"
-)
-
-func doLines(filename string, do func(line string) error) error {
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if err := do(line); err != nil {
-			return err
-		}
-	}
+	"io"
+	"sort"
+	"sync"
 
-	if err := scanner.Err(); err != nil {
-		return err
-	}
-
-	return nil
-}
+	"github.com/usedbytes/aoc2021/debug"
+	"github.com/usedbytes/aoc2021/input"
+	"github.com/usedbytes/aoc2021/progress"
+)
 
 var RotFuncs []func(Point) Point
 
@@ -165,39 +148,77 @@ func abs(a int) int {
 	return a
 }
 
-func run() error {
-	var scanners []*Scanner
-	var scanner *Scanner
+// Report is the scanners from the input. Locating them is slow, so it's done
+// the first time a part needs it, and kept for the other part.
+type Report struct {
+	Scanners []*Scanner
 
-	if err := doLines(os.Args[1], func(line string) error {
-		if len(line) == 0 {
-			return nil
-		}
+	mu      sync.Mutex
+	located []*Scanner
+}
 
-		if strings.HasPrefix(line, "---") {
-			var v int
-			_, err := fmt.Sscanf(line, "--- scanner %d ---", &v)
-			if err != nil {
-				return err
-			}
+// Located returns the scanners in absolute coordinates, locating them if
+// that hasn't been done yet
+func (r *Report) Located(ctx context.Context) ([]*Scanner, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 
-			scanner = &Scanner{
-				Idx: v,
-			}
-			scanners = append(scanners, scanner)
-			return nil
+	if r.located == nil {
+		located, err := Locate(ctx, r.Scanners, progress.From(ctx))
+		if err != nil {
+			return nil, err
 		}
+		r.located = located
+	}
+
+	return r.located, nil
+}
 
-		var x, y, z int
-		_, err := fmt.Sscanf(line, "%d,%d,%d", &x, &y, &z)
+func Parse(rd io.Reader) (*Report, error) {
+	var scanners []*Scanner
+
+	if err := input.DoBlocks(rd, func(block []string) error {
+		var v int
+		_, err := fmt.Sscanf(block[0], "--- scanner %d ---", &v)
 		if err != nil {
 			return err
 		}
-		scanner.Beacons = append(scanner.Beacons, Point{x, y, z})
+
+		scanner := &Scanner{
+			Idx: v,
+		}
+
+		for i, line := range block[1:] {
+			var x, y, z int
+			_, err := fmt.Sscanf(line, "%d,%d,%d", &x, &y, &z)
+			if err != nil {
+				return input.AtLine(i+2, 0, err)
+			}
+			scanner.Beacons = append(scanner.Beacons, Point{x, y, z})
+		}
+
+		scanners = append(scanners, scanner)
 
 		return nil
 	}); err != nil {
-		return err
+		return nil, err
+	}
+
+	return &Report{Scanners: scanners}, nil
+}
+
+// Locate returns copies of 'scanners', converted to absolute coordinates
+// relative to the first scanner. Each one found is reported to 'prog'. It
+// fails if some scanners don't overlap any of the others, or if 'ctx' is
+// done first.
+func Locate(ctx context.Context, in []*Scanner, prog progress.Reporter) ([]*Scanner, error) {
+	scanners := make([]*Scanner, len(in))
+	for i, s := range in {
+		scanners[i] = &Scanner{
+			Idx: s.Idx,
+			Beacons: append([]Point{}, s.Beacons...),
+			Coordinates: s.Coordinates,
+		}
 	}
 
 	// Track which scanners we've found
@@ -206,6 +227,9 @@ func run() error {
 	// We will assume scanners[0] is at (0, 0, 0) with rotation '0'
 	foundScanners[0] = true
 
+	prog.Phase("scanners located", int64(len(scanners)))
+	prog.Add(1)
+
 	// This only works if there's no ambiguity. If there are two scanners
 	// which look like they overlap, but they actually don't, then this
 	// falls over.
@@ -214,12 +238,18 @@ func run() error {
 	// it's consistent with all the other one's you've found already and
 	// keep track of permutations you've tried.
 	for len(foundScanners) < len(scanners) {
+		found := len(foundScanners)
+
 		for i, _ := range scanners {
 			// We already know where scanner[i] is, don't look for it again
 			if _, ok := foundScanners[i]; ok {
 				continue
 			}
 
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+
 			// Compare scanners[i] with all of the ones we already
 			// found, trying to find a match.
 			// Note: This might not succeed if scanners[i] doesn't
@@ -242,11 +272,35 @@ func run() error {
 
 				// Found a match, stop looking
 				if _, ok := foundScanners[i]; ok {
-					fmt.Println("Scanner", i, "at", t.Coordinates)
+					debug.Println("Scanner", i, "at", t.Coordinates)
+					prog.Add(1)
 					break
 				}
 			}
 		}
+
+		// Nothing left overlaps any of the ones already found
+		if len(foundScanners) == found {
+			var lost []int
+			for i, s := range scanners {
+				if !foundScanners[i] {
+					lost = append(lost, s.Idx)
+				}
+			}
+			sort.Ints(lost)
+
+			return nil, fmt.Errorf("scanners %v don't overlap any others", lost)
+		}
+	}
+
+	return scanners, nil
+}
+
+// Part1 counts the distinct beacons
+func Part1(ctx context.Context, r *Report) (int, error) {
+	scanners, err := r.Located(ctx)
+	if err != nil {
+		return 0, err
 	}
 
 	// Now just build a map of all the beacon absolute coordinates
@@ -257,7 +311,15 @@ func run() error {
 		}
 	}
 
-	fmt.Println("Part 1:", len(beacons))
+	return len(beacons), nil
+}
+
+// Part2 finds the largest distance between any two scanners
+func Part2(ctx context.Context, r *Report) (int, error) {
+	scanners, err := r.Located(ctx)
+	if err != nil {
+		return 0, err
+	}
 
 	maxDist := 0
 	for i, s := range scanners {
@@ -273,19 +335,9 @@ func run() error {
 		}
 	}
 
-	fmt.Println("Part 2:", maxDist)
-
-	return nil
+	return maxDist, nil
 }
 
 func init() {
 	calcRotFuncs()
 }
-
-func main() {
-	err := run()
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
-}
@@ -0,0 +1,48 @@
+package day19
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/usedbytes/aoc2021/golden"
+)
+
+func TestGolden(t *testing.T) {
+	golden.Test(t, 19, golden.Slow("input.txt", 1), golden.Slow("input.txt", 2))
+}
+
+func TestGenerated(t *testing.T) {
+	golden.Generated(t, 19, 3, 2)
+}
+
+func TestLocateLost(t *testing.T) {
+	r, err := Parse(strings.NewReader("--- scanner 0 ---\n1,2,3\n4,5,6\n\n--- scanner 1 ---\n7,8,9\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Part1(context.Background(), r)
+	if err == nil || !strings.Contains(err.Error(), "scanners [1]") {
+		t.Errorf("got %v, want scanner 1 to be lost", err)
+	}
+}
+
+func TestLocateCancelled(t *testing.T) {
+	r, err := Parse(strings.NewReader("--- scanner 0 ---\n1,2,3\n\n--- scanner 1 ---\n7,8,9\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := Part1(ctx, r); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want %v", err, context.Canceled)
+	}
+}
+
+func BenchmarkInput(b *testing.B) {
+	golden.Benchmark(b, 19, golden.Slow("input.txt", 1), golden.Slow("input.txt", 2))
+}
@@ -0,0 +1,9 @@
+package day19
+
+import "github.com/usedbytes/aoc2021/solver"
+
+func init() {
+	s := solver.RegisterContext(19, Parse, Part1, Part2)
+	s.Generate = Generate
+	solver.SetSlow(s, 1, 2)
+}
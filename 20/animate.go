@@ -0,0 +1,41 @@
+package day20
+
+import (
+	"fmt"
+
+	"github.com/usedbytes/aoc2021/solver"
+)
+
+// Animate shows the 50 enhancement passes from part 2. Every frame is the
+// size of the final image, so the image grows out into the infinite
+// background.
+func Animate(scr solver.Screen, p *Puzzle) error {
+	const passes = 50
+
+	min, max := p.Image.Grow(passes + 1).Bounds()
+
+	img := p.Image
+	for i := 0; i <= passes; i++ {
+		if i > 0 {
+			img = Enhance(img, p.Algorithm, 1)
+		}
+
+		frame := img.Resize(min, max).Format(func(v bool) string {
+			if v {
+				return "#"
+			}
+			return "."
+		})
+
+		status := fmt.Sprintf("Pass %d: %d lit", i, img.Count(true))
+		if img.Background {
+			status = fmt.Sprintf("Pass %d: infinitely many lit", i)
+		}
+
+		if err := scr.Frame(status + "\n\n" + frame); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
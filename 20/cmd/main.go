@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	day20 "github.com/usedbytes/aoc2021/20"
+	"github.com/usedbytes/aoc2021/input"
+)
+
+func run() error {
+	f, err := input.Open(os.Args[1])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	p, err := day20.Parse(f)
+	if err != nil {
+		return err
+	}
+
+	part1, err := day20.Part1(p)
+	if err != nil {
+		return err
+	}
+
+	part2, err := day20.Part2(p)
+	if err != nil {
+		return err
+	}
+
+	if len(os.Args) > 2 {
+		fmt.Println(day20.Render(day20.Enhance(p.Image, p.Algorithm, 50)))
+	}
+
+	fmt.Println("Part 1:", part1)
+	fmt.Println("Part 2:", part2)
+
+	return nil
+}
+
+func main() {
+	err := run()
+	if err != nil {
+		fmt.Println("ERROR:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,48 @@
+package day20
+
+import (
+	"image/color"
+	"io"
+	"time"
+
+	"github.com/usedbytes/aoc2021/visual"
+)
+
+// Draw writes an animated GIF of the 50 enhancement passes from part 2. Every
+// frame is the size of the final image, so the image grows out into the
+// infinite background.
+func Draw(w io.Writer, p *Puzzle, scale int) error {
+	if scale <= 0 {
+		scale = 3
+	}
+
+	palette := color.Palette{
+		color.RGBA{0x10, 0x10, 0x20, 0xff},
+		color.RGBA{0xf0, 0xf0, 0xff, 0xff},
+	}
+
+	colour := func(v bool) uint8 {
+		if v {
+			return 1
+		}
+		return 0
+	}
+
+	images := []*Image{p.Image}
+	for i := 0; i < 50; i++ {
+		images = append(images, Enhance(images[i], p.Algorithm, 1))
+	}
+
+	min, max := images[len(images)-1].Grow(1).Bounds()
+
+	var anim visual.Animation
+	for i, img := range images {
+		delay := 200 * time.Millisecond
+		if i == len(images)-1 {
+			delay = 3 * time.Second
+		}
+		anim.Add(visual.Region(img, min, max, scale, palette, colour), delay)
+	}
+
+	return anim.WriteGIF(w)
+}
@@ -0,0 +1,43 @@
+package day20
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+)
+
+// Generate writes a random algorithm, and a 'size' x 'size' image (100 by
+// default). If the algorithm lights up empty space, it also turns fully lit
+// space off again, or the answers would be infinite.
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 100
+	}
+
+	pixel := func() byte {
+		return ".#"[rnd.Intn(2)]
+	}
+
+	algorithm := make([]byte, 512)
+	for i := range algorithm {
+		algorithm[i] = pixel()
+	}
+	if algorithm[0] == '#' {
+		algorithm[511] = '.'
+	}
+
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "%s\n\n", algorithm)
+
+	row := make([]byte, size)
+	for y := 0; y < size; y++ {
+		for x := range row {
+			row[x] = pixel()
+		}
+		fmt.Fprintf(bw, "%s\n", row)
+	}
+
+	return bw.Flush()
+}
@@ -1,51 +1,18 @@
-package main
+package day20
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-)
-
-func doLines(filename string, do func(line string) error) error {
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if err := do(line); err != nil {
-			return err
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return err
-	}
-
-	return nil
-}
-
-type Point struct {
-	X, Y int
-}
+	"io"
 
-type Image struct {
-	Min, Max Point
-	Pixels map[Point]bool
-	Oob bool
-}
+	"github.com/usedbytes/aoc2021/grid"
+	"github.com/usedbytes/aoc2021/input"
+)
 
-func (i *Image) Get(p Point) bool {
-	if p.X < i.Min.X || p.Y < i.Min.Y ||
-	   p.X > i.Max.X || p.Y > i.Max.Y {
-		return i.Oob
-	}
+type Point = grid.Point
 
-	return i.Pixels[p]
-}
+// Image is infinite, and the pixels outside of its bounds are all the
+// Background value
+type Image = grid.Grid[bool]
 
 func sig(img *Image, p Point) int {
 	bitPositions := [][2]int{
@@ -62,7 +29,7 @@ func sig(img *Image, p Point) int {
 
 	res := 0
 	for i, d := range bitPositions {
-		np := Point{p.X + d[0], p.Y + d[1]}
+		np := Point{X: p.X + d[0], Y: p.Y + d[1]}
 		if img.Get(np) {
 			res |= (1 << i)
 		}
@@ -71,112 +38,117 @@ func sig(img *Image, p Point) int {
 	return res
 }
 
-func run() error {
-	var algorithm []byte
+type Puzzle struct {
+	Algorithm []byte
+	Image     *Image
+}
 
-	pixels := make(map[Point]bool)
+func parsePixel(c byte) (bool, error) {
+	switch c {
+	case '#':
+		return true, nil
+	case '.':
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected pixel %q", c)
+	}
+}
+
+func Parse(rd io.Reader) (*Puzzle, error) {
+	var algorithm []byte
+	var img *Image
 
-	var y int
-	var maxX, maxY int
-	if err := doLines(os.Args[1], func(line string) error {
+	if err := input.DoBlocks(rd, func(block []string) error {
 		if algorithm == nil {
+			if len(block) != 1 {
+				return input.AtLine(2, 0, fmt.Errorf("algorithm should be one line"))
+			}
+
+			line := block[0]
 			if len(line) != 512 {
-				panic("algorithm should be 512 bytes")
+				return input.AtLine(1, 0, fmt.Errorf("algorithm should be 512 bytes, not %d", len(line)))
 			}
+
+			for i := range line {
+				if _, err := parsePixel(line[i]); err != nil {
+					return input.AtLine(1, i+1, err)
+				}
+			}
+
 			algorithm = []byte(line)
 			return nil
 		}
 
-		if len(line) == 0 {
-			return nil
+		if img != nil {
+			return fmt.Errorf("unexpected block after the image")
 		}
 
-		maxX = len(line)
-		for x := 0; x < len(line); x++ {
-			if line[x] == '#' {
-				pixels[Point{x, y}] = true
-			}
+		var err error
+		img, err = grid.FromLines(block, parsePixel)
+		if err != nil {
+			return err
 		}
-		y++
+
 		return nil
 	}); err != nil {
-		return err
+		return nil, err
 	}
-	maxY = y
 
-	img := &Image{
-		Min: Point{0, 0},
-		Max: Point{maxX - 1, maxY - 1},
-		Pixels: pixels,
-		Oob: false, // Initially, out-of-bounds areas of the input are '.' (unlit)
+	if img == nil {
+		return nil, &input.ParseError{Err: fmt.Errorf("no image")}
 	}
 
-	part1 := 0
-	part2 := 0
-
-	for i := 0; i < 50; i++ {
-		newPixels := make(map[Point]bool)
-		for y := img.Min.Y - 1; y <= img.Max.Y + 1; y++ {
-			for x := img.Min.X - 1; x <= img.Max.X + 1; x++ {
-				idx := sig(img, Point{x, y})
-				b := algorithm[idx]
-				if b == '#' {
-					newPixels[Point{x, y}] = true
-				}
-			}
-		}
+	// Initially, out-of-bounds areas of the input are '.' (unlit)
+	img = img.AsSparse()
+	img.Mode = grid.Infinite
+	img.Background = false
+
+	return &Puzzle{
+		Algorithm: algorithm,
+		Image: img,
+	}, nil
+}
+
+// Enhance applies the image enhancement algorithm 'n' times, returning the
+// new image
+func Enhance(img *Image, algorithm []byte, n int) *Image {
+	for i := 0; i < n; i++ {
+		newImg := img.Grow(1)
+		newImg.Each(func(p Point, _ bool) {
+			idx := sig(img, p)
+			newImg.Set(p, algorithm[idx] == '#')
+		})
 
 		// Just use some random far out-of-bounds point to get Oob
 		// Note: This is the trick! algorithm[0] is '#', which means
 		// after the first image enhancement pass, all of the "infinite"
 		// outer edges of the image become '#', so the second pass needs
 		// to take that into account.
-		oob := algorithm[sig(img, Point{-10000, -10000})]
-		oobb := false
-		if oob == '#' {
-			oobb = true
-		}
+		oob := algorithm[sig(img, Point{X: -10000, Y: -10000})]
+		newImg.Background = oob == '#'
 
-		newImg := &Image{
-			Min: Point{ img.Min.X - 1, img.Min.Y - 1},
-			Max: Point{ img.Max.X + 1, img.Max.Y + 1},
-			Pixels: newPixels,
-			Oob: oobb,
-		}
 		img = newImg
-
-		if i == 1 {
-			part1 = len(img.Pixels)
-		}
 	}
 
-	part2 = len(img.Pixels)
+	return img
+}
 
-	if len(os.Args) > 2 {
-		for y := img.Min.Y - 1; y <= img.Max.Y + 1; y++ {
-			line := ""
-			for x := img.Min.X - 1; x <= img.Max.X + 1; x++ {
-				if img.Get(Point{x, y}) {
-					line += "#"
-				} else {
-					line += "."
-				}
-			}
-			fmt.Println(line)
+// Render draws the image, with a border of one pixel of the background
+func Render(img *Image) string {
+	return img.Grow(1).Format(func(v bool) string {
+		if v {
+			return "#"
 		}
-	}
-
-	fmt.Println("Part 1:", part1)
-	fmt.Println("Part 2:", part2)
-
+		return "."
+	})
+}
 
-	return nil
+func Part1(p *Puzzle) (int, error) {
+	img := Enhance(p.Image, p.Algorithm, 2)
+	return img.Count(true), nil
 }
 
-func main() {
-	err := run()
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
+func Part2(p *Puzzle) (int, error) {
+	img := Enhance(p.Image, p.Algorithm, 50)
+	return img.Count(true), nil
 }
@@ -0,0 +1,61 @@
+package day20
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/usedbytes/aoc2021/golden"
+)
+
+func TestGolden(t *testing.T) {
+	golden.Test(t, 20)
+}
+
+func TestGenerated(t *testing.T) {
+	golden.Generated(t, 20, 20, 5)
+}
+
+func TestDraw(t *testing.T) {
+	// Frame n is after n passes, with the lit pixels in colour 1
+	golden.Drawn(t, 20, func(t *testing.T, c golden.Case, frames []*image.Paletted) {
+		for part, n := range map[string]int{"1": 2, "2": 50} {
+			if got, want := fmt.Sprint(golden.Count(frames[n], 1)), c.Answers[part]; got != want {
+				t.Errorf("frame %d has %s lit, want %s", n, got, want)
+			}
+		}
+	})
+}
+
+func TestParseErrors(t *testing.T) {
+	algorithm := strings.Repeat(".", 512)
+
+	golden.ParseErrors(t, 20, []golden.BadInput{
+		{Name: "short algorithm", Input: "#..#\n\n#.\n.#\n", Line: 1, Column: 0},
+		{Name: "bad algorithm", Input: algorithm[:10] + "x" + algorithm[11:] + "\n\n#.\n.#\n", Line: 1, Column: 11},
+		{Name: "bad pixel", Input: algorithm + "\n\n#.\n.x\n", Line: 4, Column: 2},
+		{Name: "no image", Input: algorithm + "\n", Line: 0, Column: 0},
+	})
+}
+
+func TestAnimate(t *testing.T) {
+	// Frame n is after n passes
+	golden.Animated(t, 20, func(t *testing.T, c golden.Case, frames []string) {
+		for part, n := range map[string]int{"1": 2, "2": 50} {
+			status, board, _ := strings.Cut(frames[n], "\n\n")
+
+			if got, want := fmt.Sprint(strings.Count(board, "#")), c.Answers[part]; got != want {
+				t.Errorf("frame %d has %s lit, want %s", n, got, want)
+			}
+
+			if want := fmt.Sprintf("Pass %d: %s lit", n, c.Answers[part]); status != want {
+				t.Errorf("status is %q, want %q", status, want)
+			}
+		}
+	})
+}
+
+func BenchmarkInput(b *testing.B) {
+	golden.Benchmark(b, 20)
+}
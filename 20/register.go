@@ -0,0 +1,14 @@
+package day20
+
+import "github.com/usedbytes/aoc2021/solver"
+
+func init() {
+	s := solver.Register(20, Parse, Part1, Part2)
+	solver.AddOption(s, "image", "print the image after this many enhancement passes", 0,
+		func(p *Puzzle, n int) (string, error) {
+			return Render(Enhance(p.Image, p.Algorithm, n)), nil
+		})
+	s.Generate = Generate
+	solver.SetDrawing(s, "gif", Draw)
+	solver.SetAnimation(s, 5, Animate)
+}
@@ -1,32 +1,13 @@
-package main
+package day21
 
 import (
-	"bufio"
+	"context"
 	"fmt"
-	"os"
-)
-
-func doLines(filename string, do func(line string) error) error {
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if err := do(line); err != nil {
-			return err
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return err
-	}
+	"io"
 
-	return nil
-}
+	"github.com/usedbytes/aoc2021/input"
+	"github.com/usedbytes/aoc2021/progress"
+)
 
 func Part2Approach1(initialPositions [2]int) int64 {
 	// Calculate all the possible scores for the Dirac dice
@@ -135,7 +116,8 @@ func MakeState(pos [2]int, score [2]int) State {
 	}
 }
 
-func Part2Approach2(initialPositions [2]int) int64 {
+// Part2Approach2 reports its progress through the scores to 'prog'
+func Part2Approach2(initialPositions [2]int, prog progress.Reporter) int64 {
 	// Calculate all the possible scores for the Dirac dice
 	// and the number of ways to reach that score
 	dieScores := make([]int, 10)
@@ -157,10 +139,13 @@ func Part2Approach2(initialPositions [2]int) int64 {
 	pos := [2]int{}
 	maxScore := 0
 
+	prog.Phase("player 1 scores", 21)
+
 	// Exhaustively populate all possible states
 	// For the rules given, there's ~100k possible states to go through,
 	// and not all of them are reachable, so totally manageable
 	for scores[0] = 0; scores[0] < 21; scores[0]++ {
+		prog.Add(1)
 		for scores[1] = 0; scores[1] < 21; scores[1]++ {
 			for pos[0] = 1; pos[0] <= 10; pos[0]++ {
 				for pos[1] = 1; pos[1] <= 10; pos[1]++ {
@@ -243,9 +228,9 @@ func Part2Approach2(initialPositions [2]int) int64 {
 	}
 }
 
-func run() error {
+func Parse(rd io.Reader) ([2]int, error) {
 	var initialPositions [2]int
-	if err := doLines(os.Args[1], func(line string) error {
+	if err := input.DoLines(rd, func(line string) error {
 		var p, pos int
 		_, err := fmt.Sscanf(line, "Player %d starting position: %d", &p, &pos)
 		if err != nil {
@@ -256,9 +241,14 @@ func run() error {
 
 		return nil
 	}); err != nil {
-		return err
+		return initialPositions, err
 	}
 
+	return initialPositions, nil
+}
+
+// Part1 plays with the deterministic die
+func Part1(initialPositions [2]int) (int, error) {
 	nrolls := 0
 	roll := func() int {
 		nrolls++
@@ -287,21 +277,10 @@ func run() error {
 		}
 	}
 
-	fmt.Println("Part 1:", scores[(winner + 1) % 2] * nrolls)
-
-	part2 := Part2Approach1(initialPositions)
-	fmt.Println("Part 2 (Approach 1):", part2)
-
-	part2 = Part2Approach2(initialPositions)
-	fmt.Println("Part 2 (Approach 2):", part2)
-
-	return nil
+	return scores[(winner + 1) % 2] * nrolls, nil
 }
 
-func main() {
-	err := run()
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
+// Part2 plays with the Dirac dice. Part2Approach1 gives the same answer.
+func Part2(ctx context.Context, initialPositions [2]int) (int, error) {
+	return int(Part2Approach2(initialPositions, progress.From(ctx))), nil
 }
@@ -0,0 +1,8 @@
+package day21
+
+import "github.com/usedbytes/aoc2021/solver"
+
+func init() {
+	s := solver.RegisterContext(21, Parse, solver.IgnoreContext(Part1), Part2)
+	s.Generate = Generate
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	day22 "github.com/usedbytes/aoc2021/22"
+	"github.com/usedbytes/aoc2021/input"
+	"github.com/usedbytes/aoc2021/progress"
+)
+
+func run() error {
+	f, err := input.Open(os.Args[1])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	cmds, err := day22.Parse(f)
+	if err != nil {
+		return err
+	}
+
+	part1, err := day22.Part1(cmds)
+	if err != nil {
+		return err
+	}
+
+	tracker := progress.NewTracker()
+	stop := progress.Show(os.Stdout, "Part 2 running: ", tracker)
+	part2, err := day22.Reboot(progress.With(context.Background(), tracker), cmds)
+	stop()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Part 1:", part1)
+	fmt.Println("Part 2:", part2)
+
+	return nil
+}
+
+func main() {
+	err := run()
+	if err != nil {
+		fmt.Println("ERROR:", err)
+		os.Exit(1)
+	}
+}
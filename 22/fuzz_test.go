@@ -0,0 +1,109 @@
+package day22
+
+import (
+	"testing"
+)
+
+// Small ranges, so that the fuzzer finds the interesting overlaps
+func fuzzRange(start, length int8) Range {
+	return Range{int(start) % 32, int(length)%16 + 16}
+}
+
+func checkRangeDisjoint(t *testing.T, a, b Range) {
+	t.Helper()
+
+	pieces := a.Disjoint(b)
+	inter := a.Intersect(b)
+
+	// Together, the pieces and the intersection should exactly cover 'a'
+	covered := make(map[int]int)
+	for _, p := range append(pieces, inter) {
+		for i := p.Start; i < p.Start+p.Length; i++ {
+			covered[i]++
+		}
+	}
+
+	for i := a.Start; i < a.Start+a.Length; i++ {
+		if covered[i] != 1 {
+			t.Fatalf("%v - %v: %d covered %d times by %v + %v", a, b, i, covered[i], pieces, inter)
+		}
+		delete(covered, i)
+	}
+
+	if len(covered) != 0 {
+		t.Fatalf("%v - %v: %v + %v covers outside", a, b, pieces, inter)
+	}
+
+	// And none of the pieces are in 'b'
+	for _, p := range pieces {
+		if p.Intersect(b).Length > 0 {
+			t.Fatalf("%v - %v: %v overlaps", a, b, p)
+		}
+	}
+}
+
+func FuzzRangeDisjoint(f *testing.F) {
+	f.Add(int8(0), int8(10), int8(5), int8(2))
+	f.Add(int8(0), int8(5), int8(3), int8(10))
+	f.Add(int8(0), int8(2), int8(5), int8(2))
+
+	f.Fuzz(func(t *testing.T, as, al, bs, bl int8) {
+		checkRangeDisjoint(t, fuzzRange(as, al), fuzzRange(bs, bl))
+	})
+}
+
+func FuzzCuboidDisjoint(f *testing.F) {
+	f.Add(int8(0), int8(0), int8(0), int8(0), int8(0), int8(0),
+		int8(4), int8(4), int8(4), int8(0), int8(0), int8(0))
+	f.Add(int8(0), int8(0), int8(0), int8(0), int8(0), int8(0),
+		int8(40), int8(0), int8(0), int8(0), int8(0), int8(0))
+
+	f.Fuzz(func(t *testing.T, ax, ay, az, axl, ayl, azl, bx, by, bz, bxl, byl, bzl int8) {
+		a := MakeCuboid(fuzzRange(ax, axl), fuzzRange(ay, ayl), fuzzRange(az, azl), true)
+		b := MakeCuboid(fuzzRange(bx, bxl), fuzzRange(by, byl), fuzzRange(bz, bzl), true)
+
+		pieces := a.Disjoint(b)
+
+		// The pieces plus the intersection should add up to 'a'
+		total := a.Intersect(b).Count
+		for i, p := range pieces {
+			total += p.Count
+
+			if p.Intersect(a).Count != p.Count {
+				t.Fatalf("%v - %v: %v isn't inside", a, b, p)
+			}
+
+			if p.Intersect(b).Count != 0 {
+				t.Fatalf("%v - %v: %v overlaps", a, b, p)
+			}
+
+			for _, q := range pieces[i+1:] {
+				if p.Intersect(q).Count != 0 {
+					t.Fatalf("%v - %v: %v and %v overlap", a, b, p, q)
+				}
+			}
+		}
+
+		if total != a.Count {
+			t.Fatalf("%v - %v: pieces add up to %d, want %d", a, b, total, a.Count)
+		}
+	})
+}
+
+func TestRebootSmall(t *testing.T) {
+	// Turning on a cube, and then part of it off, leaves the rest on
+	cmds := []*Cuboid{
+		MakeCuboid(MakeRange(0, 9), MakeRange(0, 9), MakeRange(0, 9), true),
+		MakeCuboid(MakeRange(5, 14), MakeRange(5, 14), MakeRange(5, 14), false),
+		MakeCuboid(MakeRange(-2, 0), MakeRange(0, 0), MakeRange(0, 0), true),
+	}
+
+	got, err := Part1(cmds)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want := 1000 - 125 + 2; got != want {
+		t.Errorf("got %d, want %d", got, want)
+	}
+}
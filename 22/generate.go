@@ -0,0 +1,50 @@
+package day22
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+)
+
+// Generate writes 'size' reboot steps (420 by default). Like the real input,
+// the first twentieth of them are inside the initialization area, and the
+// rest are much bigger and spread out.
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 420
+	}
+
+	bw := bufio.NewWriter(w)
+
+	small := (size + 20) / 21
+	for i := 0; i < size; i++ {
+		// From and to in -reach..reach, no more than 'extent' apart
+		reach, extent := 100000, 30000
+		if i < small {
+			reach, extent = 50, 50
+		}
+
+		randomRange := func() (int, int) {
+			from := rnd.Intn(2*reach-extent/4) - reach
+			to := from + extent/4 + rnd.Intn(extent-extent/4)
+			if to > reach {
+				to = reach
+			}
+			return from, to
+		}
+
+		x1, x2 := randomRange()
+		y1, y2 := randomRange()
+		z1, z2 := randomRange()
+
+		state := "on"
+		if i > 0 && rnd.Intn(4) == 0 {
+			state = "off"
+		}
+
+		fmt.Fprintf(bw, "%s x=%d..%d,y=%d..%d,z=%d..%d\n", state, x1, x2, y1, y2, z1, z2)
+	}
+
+	return bw.Flush()
+}
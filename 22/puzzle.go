@@ -1,34 +1,14 @@
-package main
+package day22
 
 import (
-	"bufio"
+	"context"
 	"fmt"
-	"os"
+	"io"
 	"sync"
-	"runtime/pprof"
-)
-
-func doLines(filename string, do func(line string) error) error {
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if err := do(line); err != nil {
-			return err
-		}
-	}
 
-	if err := scanner.Err(); err != nil {
-		return err
-	}
-
-	return nil
-}
+	"github.com/usedbytes/aoc2021/input"
+	"github.com/usedbytes/aoc2021/progress"
+)
 
 func min(a, b int) int {
 	if a < b {
@@ -170,13 +150,20 @@ func (c Cuboid) count() int {
 }
 
 // Return the number of eventual 'on' cells contributed by *only* 'c'
-// i.e. that aren't masked or turned off by a another entry in 'through'
-func propagate(c *Cuboid, through []*Cuboid) int64 {
+// i.e. that aren't masked or turned off by a another entry in 'through'.
+// Gives up, returning 0, once 'done' is closed.
+func propagate(done <-chan struct{}, c *Cuboid, through []*Cuboid) int64 {
 	if !c.On {
 		// Can't ever turn anything on
 		return 0
 	}
 
+	select {
+	case <-done:
+		return 0
+	default:
+	}
+
 	// If we made it to the end, then return what's left
 	if len(through) == 0 {
 		return int64(c.Count)
@@ -192,23 +179,16 @@ func propagate(c *Cuboid, through []*Cuboid) int64 {
 	djs := c.Disjoint(next)
 	count := int64(0)
 	for _, d := range djs {
-		count += propagate(d, through[1:])
+		count += propagate(done, d, through[1:])
 	}
 
 	return count
 }
 
-func run() error {
-	part1Range := MakeCuboid(
-		MakeRange( -50, 50 ),
-		MakeRange( -50, 50 ),
-		MakeRange( -50, 50 ),
-		false)
+func Parse(rd io.Reader) ([]*Cuboid, error) {
+	var cmds []*Cuboid
 
-	// p1Cmds is filtered by the range
-	var p1Cmds, p2Cmds []*Cuboid
-
-	if err := doLines(os.Args[1], func(line string) error {
+	if err := input.DoLines(rd, func(line string) error {
 		var s string
 		var x1, x2, y1, y2, z1, z2 int
 		_ ,err := fmt.Sscanf(line, "%s x=%d..%d,y=%d..%d,z=%d..%d", &s, &x1, &x2, &y1, &y2, &z1, &z2)
@@ -226,34 +206,33 @@ func run() error {
 			c.On = true
 		}
 
-		if c.Intersect(part1Range).Count > 0 {
-			p1Cmds = append(p1Cmds, c)
-		}
-
-		p2Cmds = append(p2Cmds, c)
+		cmds = append(cmds, c)
 
 		return nil
 	}); err != nil {
-		return err
+		return nil, err
 	}
 
-	part1 := int64(0)
-	for i, cmd := range p1Cmds {
-		this := propagate(cmd, p1Cmds[i+1:])
-		part1 += this
-	}
+	return cmds, nil
+}
 
+// Reboot returns the number of cells left on after running all of 'cmds'.
+// Progress is reported as each command's contribution is worked out.
+// If 'ctx' is cancelled, it stops early and returns the context's error.
+func Reboot(ctx context.Context, cmds []*Cuboid) (int64, error) {
 	// Throw more cores at the problem... This clearly isn't the "right"
 	// solution, it takes ~15 minutes on my M1 Mac
 	var wg sync.WaitGroup
-	counts := make(chan [2]int64)
+	counts := make(chan int64)
 
-	part2 := int64(0)
-	for i, cmd := range p2Cmds {
+	prog := progress.From(ctx)
+	prog.Phase("commands", int64(len(cmds)))
+
+	total := int64(0)
+	for i, cmd := range cmds {
 		wg.Add(1)
 		go func(c *Cuboid, i int) {
-			this := propagate(c, p2Cmds[i+1:])
-			counts <- [2]int64{int64(i), this}
+			counts <- propagate(ctx.Done(), c, cmds[i+1:])
 			wg.Done()
 		}(cmd, i)
 	}
@@ -263,49 +242,44 @@ func run() error {
 		close(counts)
 	}()
 
-	taskStr := make([]byte, len(p2Cmds))
-	for i := range taskStr {
-		taskStr[i] = ' '
+	for count := range counts {
+		total += count
+		prog.Add(1)
 	}
 
-	nret := 0
-	fmt.Println("\033[2J")
-	for result := range counts {
-		nret++
-		i := result[0]
-		c := result[1]
-
-		taskStr[i] = '.'
-
-		part2 += c
-		fmt.Printf("\033[0;0H")
-		fmt.Printf("Part 2 running: [%s]\n", string(taskStr))
-		fmt.Printf("%d/%d %d", nret, len(p2Cmds), part2)
+	if err := ctx.Err(); err != nil {
+		return 0, err
 	}
-	fmt.Println("")
 
-	fmt.Println("Part 1:", part1)
-	fmt.Println("Part 2:", part2)
-
-	return nil
+	return total, nil
 }
 
-func main() {
-	profileEnv := os.Getenv("PROFILE")
-	if profileEnv != "" {
-		f, err := os.Create(profileEnv)
-		if err != nil {
-			fmt.Println("ERROR:", err)
-			os.Exit(1)
-		}
+// Part1 only considers the commands in the -50..50 initialization region
+func Part1(cmds []*Cuboid) (int, error) {
+	part1Range := MakeCuboid(
+		MakeRange( -50, 50 ),
+		MakeRange( -50, 50 ),
+		MakeRange( -50, 50 ),
+		false)
 
-		pprof.StartCPUProfile(f)
-		defer pprof.StopCPUProfile()
+	// p1Cmds is filtered by the range
+	var p1Cmds []*Cuboid
+	for _, c := range cmds {
+		if c.Intersect(part1Range).Count > 0 {
+			p1Cmds = append(p1Cmds, c)
+		}
 	}
 
-	err := run()
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
+	part1 := int64(0)
+	for i, cmd := range p1Cmds {
+		this := propagate(nil, cmd, p1Cmds[i+1:])
+		part1 += this
 	}
+
+	return int(part1), nil
+}
+
+func Part2(ctx context.Context, cmds []*Cuboid) (int, error) {
+	total, err := Reboot(ctx, cmds)
+	return int(total), err
 }
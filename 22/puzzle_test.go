@@ -0,0 +1,69 @@
+package day22
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/usedbytes/aoc2021/golden"
+	"github.com/usedbytes/aoc2021/progress"
+)
+
+func TestGolden(t *testing.T) {
+	golden.Test(t, 22, golden.Slow("input.txt", 2))
+}
+
+func TestGenerated(t *testing.T) {
+	golden.Generated(t, 22, 20, 5)
+}
+
+func TestCancel(t *testing.T) {
+	f, err := os.Open("input.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	cmds, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The full part 2 takes many minutes, so this should time out
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = Part2(ctx, cmds)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected a timeout, got %v", err)
+	}
+
+	if d := time.Since(start); d > 5*time.Second {
+		t.Errorf("took %v to stop", d)
+	}
+}
+
+func TestRebootProgress(t *testing.T) {
+	cmds := []*Cuboid{
+		MakeCuboid(MakeRange(0, 9), MakeRange(0, 9), MakeRange(0, 9), true),
+		MakeCuboid(MakeRange(5, 14), MakeRange(5, 14), MakeRange(5, 14), false),
+		MakeCuboid(MakeRange(-2, 0), MakeRange(0, 0), MakeRange(0, 0), true),
+	}
+
+	tracker := progress.NewTracker()
+	if _, err := Reboot(progress.With(context.Background(), tracker), cmds); err != nil {
+		t.Fatal(err)
+	}
+
+	s := tracker.Snapshot()
+	if s == nil || s.Done != 3 || s.Total != 3 {
+		t.Errorf("got progress %+v, want 3/3", s)
+	}
+}
+
+func BenchmarkInput(b *testing.B) {
+	golden.Benchmark(b, 22, golden.Slow("input.txt", 2))
+}
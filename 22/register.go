@@ -0,0 +1,9 @@
+package day22
+
+import "github.com/usedbytes/aoc2021/solver"
+
+func init() {
+	s := solver.RegisterContext(22, Parse, solver.IgnoreContext(Part1), Part2)
+	s.Generate = Generate
+	solver.SetSlow(s, 2)
+}
@@ -0,0 +1,53 @@
+package day23
+
+import (
+	"fmt"
+
+	"github.com/usedbytes/aoc2021/solver"
+)
+
+// Returns the amphipod move which turns 'a' into 'b'
+func moveBetween(a, b Cave) (from, to Position) {
+	for y := range a {
+		for x := range a[y] {
+			switch {
+			case a[y][x] == '.' && b[y][x] != '.':
+				to = Position{x, y}
+			case a[y][x] != '.' && b[y][x] == '.':
+				from = Position{x, y}
+			}
+		}
+	}
+
+	return from, to
+}
+
+// Animate shows the cheapest way to organise the amphipods, one move at a
+// time
+func Animate(scr solver.Screen, cave Cave) error {
+	r := Search().Dijkstra(cave)
+	if !r.Found {
+		return fmt.Errorf("the amphipods can't be organised")
+	}
+
+	path := r.Path()
+
+	energy := 0
+	for i, c := range path {
+		status := "Start"
+		if i > 0 {
+			from, to := moveBetween(path[i-1], c)
+			_, cost := path[i-1].Move(from, to)
+			energy += cost
+
+			status = fmt.Sprintf("Move %d/%d: %c uses %d energy (%d total)",
+				i, len(path)-1, c.At(to.X, to.Y), cost, energy)
+		}
+
+		if err := scr.Frame(status + "\n\n" + c.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
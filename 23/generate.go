@@ -0,0 +1,54 @@
+package day23
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+
+	"github.com/usedbytes/aoc2021/progress"
+)
+
+// Generate writes a burrow with rooms 'size' deep (2 by default, and at most
+// 4), which can be solved. Part 2 only works on burrows 2 deep, because it
+// unfolds them to 4.
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 2
+	}
+	if size > len(Cave{})-1 {
+		return fmt.Errorf("rooms can't be %d deep", size)
+	}
+
+	for tries := 0; tries < 100; tries++ {
+		pods := []byte(strings.Repeat("ABCD", size))
+		rnd.Shuffle(len(pods), func(i, j int) { pods[i], pods[j] = pods[j], pods[i] })
+
+		var buf bytes.Buffer
+		buf.WriteString("#############\n#...........#\n")
+		for y := 0; y < size; y++ {
+			row := pods[y*4 : y*4+4]
+			if y == 0 {
+				fmt.Fprintf(&buf, "###%c#%c#%c#%c###\n", row[0], row[1], row[2], row[3])
+			} else {
+				fmt.Fprintf(&buf, "  #%c#%c#%c#%c#\n", row[0], row[1], row[2], row[3])
+			}
+		}
+		buf.WriteString("  #########\n")
+
+		cave, err := Parse(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			return err
+		}
+
+		if cave.IsSolved() || solve(cave, progress.Discard) < 0 {
+			continue
+		}
+
+		_, err = w.Write(buf.Bytes())
+		return err
+	}
+
+	return fmt.Errorf("couldn't find a solvable burrow %d deep", size)
+}
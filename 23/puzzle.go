@@ -1,34 +1,16 @@
-package main
+package day23
 
 import (
-	"bufio"
+	"bytes"
+	"context"
 	"fmt"
-	"os"
-	"runtime/pprof"
+	"io"
 	"strings"
-)
-
-func doLines(filename string, do func(line string) error) error {
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if err := do(line); err != nil {
-			return err
-		}
-	}
 
-	if err := scanner.Err(); err != nil {
-		return err
-	}
-
-	return nil
-}
+	"github.com/usedbytes/aoc2021/input"
+	"github.com/usedbytes/aoc2021/progress"
+	"github.com/usedbytes/aoc2021/search"
+)
 
 type Cave [5][11]byte
 
@@ -196,12 +178,29 @@ func AllowedDestinations(c Cave, p Position) []Position {
 	return poss
 }
 
-func (c Cave) Print() {
-	fmt.Println("---")
-	for _, row := range c {
-		fmt.Printf("%s\n", row)
+// String draws the cave like the diagrams in the puzzle
+func (c Cave) String() string {
+	var sb strings.Builder
+
+	sb.WriteString("#############\n")
+	sb.WriteString("#" + string(c[0][:]) + "#\n")
+	for y := 1; y <= c.Depth(); y++ {
+		row := []byte("  #.#.#.#.#")
+		if y == 1 {
+			row = []byte("###.#.#.#.###")
+		}
+
+		for i := 0; i < 4; i++ {
+			x := RoomXPosition(i)
+			row[x+1] = c.At(x, y)
+		}
+
+		sb.Write(row)
+		sb.WriteString("\n")
 	}
-	fmt.Println("---")
+	sb.WriteString("  #########")
+
+	return sb.String()
 }
 
 func FindPods(c Cave) []Position {
@@ -250,95 +249,175 @@ func (c Cave) IsSolved() bool {
 	return true
 }
 
-func solve(c Cave, dp map[Cave]int) int {
-	if c.IsSolved() {
-		return 0
+// Search returns the search for the cheapest way to organise the amphipods
+func Search() *search.Search[Cave] {
+	return &search.Search[Cave]{
+		Neighbours: func(c Cave, yield func(Cave, int)) {
+			for _, p := range FindPods(c) {
+				for _, m := range AllowedDestinations(c, p) {
+					yield(c.Move(p, m))
+				}
+			}
+		},
+		IsGoal: Cave.IsSolved,
 	}
+}
 
-	if v, ok := dp[c]; ok {
-		return v
+// Returns the lowest cost to solve 'c', or -1 if it can't be solved. Each
+// state searched is reported to 'prog', but how many there'll be isn't known.
+func solve(c Cave, prog progress.Reporter) int {
+	s := Search()
+	neighbours := s.Neighbours
+	s.Neighbours = func(c Cave, yield func(Cave, int)) {
+		prog.Add(1)
+		neighbours(c, yield)
 	}
 
-	minCost := -1
-	pods := FindPods(c)
-	for _, p := range pods {
-		moves := AllowedDestinations(c, p)
-		for _, m := range moves {
-			d, moveCost := c.Move(p, m)
-
-			newCost := solve(d, dp)
-			if newCost >= 0 {
-				newCost += moveCost
-				if minCost < 0 || (newCost < minCost) {
-					minCost = newCost
-				}
+	prog.Phase("states searched", 0)
+	r := s.Dijkstra(c)
+	if !r.Found {
+		return -1
+	}
+
+	return r.Cost
+}
+
+// The shape of each line of the diagram, where '.' is a space which might
+// have an amphipod in
+const (
+	wallLine   = "#############"
+	hallLine   = "#...........#"
+	firstRoom  = "###.#.#.#.###"
+	otherRoom  = "  #.#.#.#.#"
+	bottomLine = "  #########"
+)
+
+// Checks that 'line' is the same shape as 'want'
+func checkLine(line, want string) error {
+	for i := 0; i < len(want); i++ {
+		if i >= len(line) {
+			return input.At(i+1, fmt.Errorf("line is too short, expected %q", want))
+		}
+
+		c := line[i]
+		if want[i] == '.' {
+			if c != '.' && !strings.Contains("ABCD", string(c)) {
+				return input.At(i+1, fmt.Errorf("expected an amphipod or '.', got %q", c))
 			}
+		} else if c != want[i] {
+			return input.At(i+1, fmt.Errorf("expected %q, got %q", want[i], c))
 		}
 	}
 
-	dp[c] = minCost
+	if len(line) > len(want) {
+		return input.At(len(want)+1, fmt.Errorf("unexpected %q after the diagram", line[len(want):]))
+	}
 
-	return minCost
+	return nil
 }
 
-func run() error {
+func Parse(rd io.Reader) (Cave, error) {
 	var cave Cave
 
-	part2 := (len(os.Args) > 2)
+	n := 0
+	depth := 0
+	closed := false
+	if err := input.DoLines(rd, func(line string) error {
+		n++
+		line = strings.TrimRight(line, " ")
 
-	y := -1
-	if err := doLines(os.Args[1], func(line string) error {
-		if y < 0 || y > len(cave) - 1 {
-			y++
+		if closed {
+			if line != "" {
+				return fmt.Errorf("unexpected line after the diagram")
+			}
 			return nil
 		}
 
-		for x, c := range line[1:] {
-			if x > len(cave[0]) - 1 {
-				break
-			}
-			cave[y][x] = byte(c)
+		var err error
+		switch {
+		case n == 1:
+			return checkLine(line, wallLine)
+		case n == 2:
+			err = checkLine(line, hallLine)
+		case n == 3:
+			err = checkLine(line, firstRoom)
+			depth++
+		case line == bottomLine:
+			closed = true
+		default:
+			err = checkLine(line, otherRoom)
+			depth++
 		}
 
-		y++
+		if err != nil {
+			return err
+		}
 
-		if part2 && y == 2 {
-			copy(cave[2][:], []byte(" #D#C#B#A#"))
-			copy(cave[3][:], []byte(" #D#B#A#C#"))
-			y += 2
+		// The bottom wall isn't needed if the rooms are as deep as they
+		// can be
+		y := n - 2
+		if y > len(cave)-1 {
+			if closed {
+				return nil
+			}
+			return fmt.Errorf("rooms can be at most %d deep", len(cave)-1)
 		}
 
+		copy(cave[y][:], line[1:])
+
 		return nil
 	}); err != nil {
-		return err
+		return cave, err
 	}
 
-	cave.Print()
+	if !closed {
+		return cave, &input.ParseError{Line: n + 1, Err: fmt.Errorf("expected %q", bottomLine)}
+	}
 
-	dp := make(map[Cave]int)
-	totalCost := solve(cave, dp)
+	for _, c := range []byte("ABCD") {
+		count := 0
+		for _, row := range cave {
+			count += bytes.Count(row[:], []byte{c})
+		}
 
-	fmt.Println(totalCost)
+		if count != depth {
+			return cave, &input.ParseError{Err: fmt.Errorf("expected %d of each amphipod, but there are %d %cs", depth, count, c)}
+		}
+	}
 
-	return nil
+	return cave, nil
 }
 
-func main() {
-	profileEnv := os.Getenv("PROFILE")
-	if profileEnv != "" {
-		f, err := os.Create(profileEnv)
-		if err != nil {
-			fmt.Println("ERROR:", err)
-			os.Exit(1)
-		}
-
-		pprof.StartCPUProfile(f)
-		defer pprof.StopCPUProfile()
+// Depth returns how many amphipods fit in each room
+func (c Cave) Depth() int {
+	depth := 0
+	for y := 1; y < len(c) && c.At(RoomXPosition(0), y) != '#'; y++ {
+		depth++
 	}
 
-	err := run()
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
+	return depth
+}
+
+// Unfold inserts the two extra rows from the diagram for part 2
+func (c Cave) Unfold() Cave {
+	var r Cave
+
+	r[0], r[1] = c[0], c[1]
+	copy(r[2][:], []byte(" #D#C#B#A#"))
+	copy(r[3][:], []byte(" #D#B#A#C#"))
+	r[4] = c[2]
+
+	return r
+}
+
+func Part1(ctx context.Context, cave Cave) (int, error) {
+	return solve(cave, progress.From(ctx)), nil
+}
+
+func Part2(ctx context.Context, cave Cave) (int, error) {
+	if d := cave.Depth(); d != 2 {
+		return 0, fmt.Errorf("can only unfold rooms 2 deep, not %d", d)
 	}
+
+	return solve(cave.Unfold(), progress.From(ctx)), nil
 }
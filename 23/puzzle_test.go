@@ -0,0 +1,51 @@
+package day23
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/usedbytes/aoc2021/golden"
+)
+
+func TestGolden(t *testing.T) {
+	golden.Test(t, 23)
+}
+
+func TestGenerated(t *testing.T) {
+	golden.Generated(t, 23, 0, 2)
+}
+
+func TestAnimate(t *testing.T) {
+	// The last frame has everything in its room, for part 1's energy
+	golden.Animated(t, 23, func(t *testing.T, c golden.Case, frames []string) {
+		status, board, _ := strings.Cut(frames[len(frames)-1], "\n\n")
+
+		if want := fmt.Sprintf("(%s total)", c.Answers["1"]); !strings.HasSuffix(status, want) {
+			t.Errorf("status is %q, want it to end %q", status, want)
+		}
+
+		if !strings.Contains(board, "###A#B#C#D###") || !strings.Contains(board, "  #A#B#C#D#") {
+			t.Errorf("not organised at the end:\n%s", board)
+		}
+	})
+}
+
+func TestParseErrors(t *testing.T) {
+	golden.ParseErrors(t, 23, []golden.BadInput{
+		{Name: "empty", Input: "", Line: 1, Column: 0},
+		{Name: "empty line", Input: "#############\n\n", Line: 2, Column: 1},
+		{Name: "short line", Input: "#############\n#...........#\n#\n", Line: 3, Column: 2},
+		{Name: "bad amphipod", Input: "#############\n#...........#\n###B#C#E#D###\n  #A#D#C#A#\n  #########\n", Line: 3, Column: 8},
+		{Name: "wall missing", Input: "#############\n#...........#\n###B#C#B#D###\n  #A#D#C#A.\n  #########\n", Line: 4, Column: 11},
+		{Name: "too long", Input: "#############\n#...........#\n###B#C#B#D####\n  #A#D#C#A#\n  #########\n", Line: 3, Column: 14},
+		{Name: "not closed", Input: "#############\n#...........#\n###B#C#B#D###\n  #A#D#C#A#\n", Line: 5, Column: 0},
+		{Name: "too deep", Input: "#############\n#...........#\n###B#C#B#D###\n" + strings.Repeat("  #A#D#C#A#\n", 4) + "  #########\n", Line: 7, Column: 0},
+		{Name: "after the end", Input: "#############\n#...........#\n###B#C#B#D###\n  #A#D#C#A#\n  #########\nABCD\n", Line: 6, Column: 0},
+		{Name: "wrong amphipods", Input: "#############\n#...........#\n###B#C#B#D###\n  #A#D#C#B#\n  #########\n", Line: 0, Column: 0},
+	})
+}
+
+func BenchmarkInput(b *testing.B) {
+	golden.Benchmark(b, 23)
+}
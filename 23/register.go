@@ -0,0 +1,9 @@
+package day23
+
+import "github.com/usedbytes/aoc2021/solver"
+
+func init() {
+	s := solver.RegisterContext(23, Parse, Part1, Part2)
+	s.Generate = Generate
+	solver.SetAnimation(s, 2, Animate)
+}
@@ -0,0 +1,280 @@
+package day24
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/usedbytes/aoc2021/input"
+)
+
+// Opcode is an ALU instruction's operation
+type Opcode uint8
+
+const (
+	Inp Opcode = iota
+	Add
+	Mul
+	Div
+	Mod
+	Eql
+)
+
+var opcodes = map[string]Opcode{
+	"inp": Inp,
+	"add": Add,
+	"mul": Mul,
+	"div": Div,
+	"mod": Mod,
+	"eql": Eql,
+}
+
+func (op Opcode) String() string {
+	return [...]string{"inp", "add", "mul", "div", "mod", "eql"}[op]
+}
+
+// Register is one of the ALU's registers, w, x, y or z
+type Register uint8
+
+const registers = "wxyz"
+
+func parseRegister(name string) Register {
+	return Register(strings.Index(registers, name))
+}
+
+func (r Register) String() string {
+	return string(registers[r])
+}
+
+// Operand is an instruction's second operand, a register or an immediate
+// value
+type Operand struct {
+	Reg   Register
+	Imm   int
+	IsImm bool
+}
+
+func (o Operand) String() string {
+	if o.IsImm {
+		return strconv.Itoa(o.Imm)
+	}
+	return o.Reg.String()
+}
+
+// Instruction is a decoded ALU instruction. 'B' isn't used by inp.
+type Instruction struct {
+	Op Opcode
+	A  Register
+	B  Operand
+}
+
+func (insn Instruction) String() string {
+	if insn.Op == Inp {
+		return fmt.Sprintf("%v %v", insn.Op, insn.A)
+	}
+	return fmt.Sprintf("%v %v %v", insn.Op, insn.A, insn.B)
+}
+
+// Program is an assembled ALU program, which can be run many times without
+// decoding the instructions again
+type Program []Instruction
+
+// Assemble decodes 'program'. Errors have the line and column of the bad
+// instruction.
+func Assemble(program []string) (Program, error) {
+	p := make(Program, len(program))
+	for n, line := range program {
+		parts, err := decode(line)
+		if err != nil {
+			return nil, input.AtLine(n+1, 0, err)
+		}
+
+		// decode() checked the registers and numbers
+		insn := Instruction{
+			Op: opcodes[parts[0]],
+			A:  parseRegister(parts[1]),
+		}
+
+		if insn.Op != Inp {
+			if isRegister(parts[2]) {
+				insn.B.Reg = parseRegister(parts[2])
+			} else {
+				insn.B.IsImm = true
+				insn.B.Imm, _ = strconv.Atoi(parts[2])
+			}
+		}
+
+		p[n] = insn
+	}
+
+	return p, nil
+}
+
+// Run runs the program on 's', the same as RunProgram does with the
+// instructions as text, but much faster. If it fails, 's' is left as it was
+// after the last instruction which worked, like RunProgram.
+func (p Program) Run(s *ALUState, in []int) error {
+	regs := [4]int{s.W, s.X, s.Y, s.Z}
+	err := p.run(&regs, in)
+	s.W, s.X, s.Y, s.Z = regs[0], regs[1], regs[2], regs[3]
+
+	return err
+}
+
+func (p Program) run(regs *[4]int, in []int) error {
+	i := 0
+	for n, insn := range p {
+		if insn.Op == Inp {
+			if i >= len(in) {
+				// RunProgram gives the ALU a 0 before noticing
+				regs[insn.A] = 0
+				return fmt.Errorf("instruction %d: input underflow", n+1)
+			}
+
+			regs[insn.A] = in[i]
+			i++
+			continue
+		}
+
+		a := &regs[insn.A]
+		b := insn.B.Imm
+		if !insn.B.IsImm {
+			b = regs[insn.B.Reg]
+		}
+
+		switch insn.Op {
+		case Add:
+			*a += b
+		case Mul:
+			*a *= b
+		case Div:
+			if b == 0 {
+				return fmt.Errorf("instruction %d: %v: divide by zero", n+1, insn)
+			}
+			*a /= b
+		case Mod:
+			if *a < 0 || b <= 0 {
+				return fmt.Errorf("instruction %d: %v: invalid mod %d %% %d", n+1, insn, *a, b)
+			}
+			*a %= b
+		case Eql:
+			if *a == b {
+				*a = 1
+			} else {
+				*a = 0
+			}
+		}
+	}
+
+	return nil
+}
+
+// Compiled is a program compiled to a chain of closures, one per
+// instruction, which each know their registers and operation already
+type Compiled func(s *ALUState, in []int) error
+
+// The state a compiled program runs with
+type machine struct {
+	regs [4]int
+	in   []int
+}
+
+type step func(m *machine) error
+
+// Returns the step for instruction 'n'
+func compileInsn(n int, insn Instruction) step {
+	a := insn.A
+	if insn.Op == Inp {
+		return func(m *machine) error {
+			if len(m.in) == 0 {
+				m.regs[a] = 0
+				return fmt.Errorf("instruction %d: input underflow", n+1)
+			}
+			m.regs[a] = m.in[0]
+			m.in = m.in[1:]
+			return nil
+		}
+	}
+
+	// Specialise on immediates, which are most of the operands
+	if insn.B.IsImm {
+		b := insn.B.Imm
+		switch insn.Op {
+		case Add:
+			return func(m *machine) error { m.regs[a] += b; return nil }
+		case Mul:
+			if b == 0 {
+				return func(m *machine) error { m.regs[a] = 0; return nil }
+			}
+			return func(m *machine) error { m.regs[a] *= b; return nil }
+		case Div:
+			if b == 0 {
+				return func(m *machine) error {
+					return fmt.Errorf("instruction %d: %v: divide by zero", n+1, insn)
+				}
+			}
+			return func(m *machine) error { m.regs[a] /= b; return nil }
+		}
+	}
+
+	b := insn.B
+	return func(m *machine) error {
+		bv := b.Imm
+		if !b.IsImm {
+			bv = m.regs[b.Reg]
+		}
+
+		av := &m.regs[a]
+		switch insn.Op {
+		case Add:
+			*av += bv
+		case Mul:
+			*av *= bv
+		case Div:
+			if bv == 0 {
+				return fmt.Errorf("instruction %d: %v: divide by zero", n+1, insn)
+			}
+			*av /= bv
+		case Mod:
+			if *av < 0 || bv <= 0 {
+				return fmt.Errorf("instruction %d: %v: invalid mod %d %% %d", n+1, insn, *av, bv)
+			}
+			*av %= bv
+		case Eql:
+			if *av == bv {
+				*av = 1
+			} else {
+				*av = 0
+			}
+		}
+
+		return nil
+	}
+}
+
+// Compile compiles the program to closures, which run it the same way as Run
+// (including when it fails)
+func (p Program) Compile() Compiled {
+	steps := make([]step, len(p))
+	for n, insn := range p {
+		steps[n] = compileInsn(n, insn)
+	}
+
+	return func(s *ALUState, in []int) error {
+		m := machine{
+			regs: [4]int{s.W, s.X, s.Y, s.Z},
+			in:   in,
+		}
+
+		var err error
+		for _, st := range steps {
+			if err = st(&m); err != nil {
+				break
+			}
+		}
+
+		s.W, s.X, s.Y, s.Z = m.regs[0], m.regs[1], m.regs[2], m.regs[3]
+
+		return err
+	}
+}
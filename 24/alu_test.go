@@ -0,0 +1,165 @@
+package day24
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/usedbytes/aoc2021/input"
+)
+
+func TestAssemble(t *testing.T) {
+	program := []string{"inp w", "add x -3", "mul y w", "div z 26", "mod x 7", "eql w x"}
+
+	p, err := Assemble(program)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Instruction{Op: Add, A: 1, B: Operand{Imm: -3, IsImm: true}}
+	if p[1] != want {
+		t.Errorf("got %+v, want %+v", p[1], want)
+	}
+
+	// It disassembles back to the same text
+	for i, insn := range p {
+		if insn.String() != program[i] {
+			t.Errorf("instruction %d is %q, want %q", i+1, insn, program[i])
+		}
+	}
+}
+
+func TestAssembleErrors(t *testing.T) {
+	_, err := Assemble([]string{"inp w", "add x 1", "sub x 1"})
+
+	var pe *input.ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("got %v, want a ParseError", err)
+	}
+
+	if pe.Line != 3 || pe.Column != 1 {
+		t.Errorf("error at %d:%d, want 3:1", pe.Line, pe.Column)
+	}
+}
+
+// Checks that 'program' does the same on 'in' when it's assembled, both
+// interpreted and compiled, as when it's run as text
+func checkAssembled(t *testing.T, program []string, in []int) {
+	t.Helper()
+
+	var want ALUState
+	wantErr := RunProgram(&want, program, in)
+
+	p, err := Assemble(program)
+	if err != nil {
+		t.Fatalf("%q: %v", program, err)
+	}
+
+	for _, run := range []struct {
+		name string
+		run  func(s *ALUState, in []int) error
+	}{
+		{"interpreted", p.Run},
+		{"compiled", p.Compile()},
+	} {
+		var got ALUState
+		err := run.run(&got, in)
+
+		if fmt.Sprint(err) != fmt.Sprint(wantErr) {
+			t.Fatalf("%q with %v: %s error is %v, want %v", program, in, run.name, err, wantErr)
+		}
+
+		if got != want {
+			t.Fatalf("%q with %v: %s got %+v, want %+v", program, in, run.name, got, want)
+		}
+	}
+}
+
+func TestAssembledInput(t *testing.T) {
+	f, err := os.Open("input.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	program, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rnd := rand.New(rand.NewSource(24))
+	for i := 0; i < 100; i++ {
+		in := make([]int, 14)
+		for d := range in {
+			in[d] = 1 + rnd.Intn(9)
+		}
+
+		checkAssembled(t, program, in)
+	}
+}
+
+// Builds a program out of 'data', three bytes per instruction
+func fuzzProgram(data []byte) []string {
+	ops := []string{"inp", "add", "mul", "div", "mod", "eql"}
+	regs := "wxyz"
+
+	var program []string
+	for i := 0; i+2 < len(data); i += 3 {
+		op := ops[int(data[i])%len(ops)]
+		dst := regs[int(data[i+1])%len(regs)]
+
+		if op == "inp" {
+			program = append(program, fmt.Sprintf("inp %c", dst))
+			continue
+		}
+
+		src := fmt.Sprint(int(data[i+2]%32) - 8)
+		if data[i+2]&0x80 != 0 {
+			src = string(regs[int(data[i+2])%len(regs)])
+		}
+		program = append(program, fmt.Sprintf("%s %c %s", op, dst, src))
+	}
+
+	return program
+}
+
+func BenchmarkALU(b *testing.B) {
+	f, err := os.Open("input.txt")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer f.Close()
+
+	program, err := Parse(f)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	p, err := Assemble(program)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	in := parseInput("13579246899999")
+
+	for _, run := range []struct {
+		name string
+		run  func(s *ALUState, in []int) error
+	}{
+		{"text", func(s *ALUState, in []int) error { return RunProgram(s, program, in) }},
+		{"interpreted", p.Run},
+		{"compiled", p.Compile()},
+	} {
+		run := run
+		b.Run(run.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				var s ALUState
+				if err := run.run(&s, in); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	day24 "github.com/usedbytes/aoc2021/24"
+	"github.com/usedbytes/aoc2021/input"
+)
+
+func run() error {
+	f, err := input.Open(os.Args[1])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	program, err := day24.Parse(f)
+	if err != nil {
+		return err
+	}
+
+	stages, err := day24.SymbolicStages(day24.SplitDigits(program))
+	if err != nil {
+		return err
+	}
+
+	// Just print the program's expressions, to look at
+	if len(os.Args) > 2 {
+		switch os.Args[2] {
+		case "dot":
+			return day24.WriteDOT(os.Stdout, stages)
+		case "infix":
+			return day24.WriteInfix(os.Stdout, stages)
+		case "go":
+			return day24.WriteGo(os.Stdout, "monad", stages)
+		}
+		return fmt.Errorf("unknown format %q, want dot, infix or go", os.Args[2])
+	}
+
+	analysis, err := day24.Analyse(stages)
+	if err != nil {
+		return err
+	}
+	fmt.Print(analysis)
+
+	part1, err := day24.Part1(program)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Part 1:", part1)
+
+	part2, err := day24.Part2(program)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Part 2:", part2)
+
+	return nil
+}
+
+func main() {
+	err := run()
+	if err != nil {
+		fmt.Println("ERROR:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,275 @@
+package day24
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DAG hash-conses expressions, so that there's only ever one copy of each
+// distinct expression. Anything computed twice, like 'z % 26' in a MONAD, is
+// shared instead, and the expressions for a whole program stay small even
+// though they'd be enormous written out as trees.
+type DAG struct {
+	nodes map[dagKey]*Expression
+}
+
+// Expressions are the same if their operands are the same expressions, which
+// is only a pointer comparison once they've been interned
+type dagKey struct {
+	A, B *Expression
+	Op   Operator
+	Val  int
+}
+
+func NewDAG() *DAG {
+	return &DAG{
+		nodes: make(map[dagKey]*Expression),
+	}
+}
+
+// Intern returns the DAG's copy of 'e', adding it if there isn't one yet.
+// Its operands have to have been interned already.
+func (d *DAG) Intern(e *Expression) *Expression {
+	key := dagKey{e.A, e.B, e.Op, e.Val}
+	if n, ok := d.nodes[key]; ok {
+		return n
+	}
+
+	d.nodes[key] = e
+
+	return e
+}
+
+// Len returns the number of distinct expressions in the DAG
+func (d *DAG) Len() int {
+	return len(d.nodes)
+}
+
+// Walks every expression reachable from 'roots' once, operands first
+func walkDAG(roots []*Expression, visit func(e *Expression)) {
+	seen := make(map[*Expression]bool)
+
+	var walk func(e *Expression)
+	walk = func(e *Expression) {
+		if e == nil || seen[e] {
+			return
+		}
+		seen[e] = true
+
+		walk(e.A)
+		walk(e.B)
+		visit(e)
+	}
+
+	for _, r := range roots {
+		walk(r)
+	}
+}
+
+// WriteDOT writes the stages' expressions as a Graphviz graph. Each stage's
+// result is drawn as one node with the uses of it in later stages, so it's
+// the whole program in one graph.
+func WriteDOT(w io.Writer, stages []*Expression) error {
+	bw := bufio.NewWriter(w)
+
+	// A stage's result is drawn as the node for that stage's expression.
+	// That might be another stage's result, if a stage doesn't change z.
+	resolve := func(e *Expression) *Expression {
+		for e.Op == OpRes && e.Val >= 0 && e.Val < len(stages) {
+			e = stages[e.Val]
+		}
+		return e
+	}
+
+	ids := make(map[*Expression]string)
+	id := func(e *Expression) string {
+		return ids[resolve(e)]
+	}
+
+	fmt.Fprintln(bw, "digraph monad {")
+	// Keep the operands in order, left then right
+	fmt.Fprintln(bw, "\tordering=out;")
+
+	walkDAG(stages, func(e *Expression) {
+		if resolve(e) != e {
+			return
+		}
+
+		ids[e] = fmt.Sprintf("n%d", len(ids))
+
+		var label, shape string
+		switch e.Op {
+		case OpLiteral:
+			label, shape = fmt.Sprint(e.Val), "plaintext"
+		case OpVar:
+			label, shape = fmt.Sprintf("inp%d", e.Val), "box"
+		case OpRes:
+			// Not one of these stages, so just a value from outside
+			label, shape = fmt.Sprintf("out%d", e.Val), "box"
+		default:
+			label, shape = string(e.Op), "circle"
+		}
+
+		fmt.Fprintf(bw, "\t%s [label=%q, shape=%s];\n", ids[e], label, shape)
+		if e.A != nil {
+			fmt.Fprintf(bw, "\t%s -> %s;\n", ids[e], id(e.A))
+			fmt.Fprintf(bw, "\t%s -> %s;\n", ids[e], id(e.B))
+		}
+	})
+
+	for i, s := range stages {
+		fmt.Fprintf(bw, "\tout%d [shape=doublecircle];\n", i)
+		fmt.Fprintf(bw, "\tout%d -> %s;\n", i, id(s))
+	}
+
+	fmt.Fprintln(bw, "}")
+
+	return bw.Flush()
+}
+
+// printer writes expressions as a list of assignments, with a temporary for
+// each expression which is used more than once, so nothing is written out
+// twice
+type printer struct {
+	// Go source, rather than plain infix
+	goSource bool
+
+	uses  map[*Expression]int
+	names map[*Expression]string
+	temps int
+	sb    strings.Builder
+}
+
+func newPrinter(stages []*Expression, goSource bool) *printer {
+	p := &printer{
+		goSource: goSource,
+		uses:     make(map[*Expression]int),
+		names:    make(map[*Expression]string),
+	}
+
+	walkDAG(stages, func(e *Expression) {
+		if e.A != nil {
+			p.uses[e.A]++
+			p.uses[e.B]++
+		}
+	})
+
+	return p
+}
+
+func (p *printer) assign(name, value string) {
+	op := "="
+	if p.goSource {
+		p.sb.WriteString("\t")
+		if name != "_" {
+			op = ":="
+		}
+	}
+
+	fmt.Fprintf(&p.sb, "%s %s %s\n", name, op, value)
+}
+
+// Returns 'e' as an operand, bracketed unless it's a single value
+func (p *printer) operand(e *Expression) string {
+	s := p.expr(e)
+	if _, named := p.names[e]; e.A != nil && !named {
+		return "(" + s + ")"
+	}
+	return s
+}
+
+func (p *printer) expr(e *Expression) string {
+	if name, ok := p.names[e]; ok {
+		return name
+	}
+
+	switch e.Op {
+	case OpLiteral:
+		return fmt.Sprint(e.Val)
+	case OpVar:
+		if p.goSource {
+			return fmt.Sprintf("in[%d]", e.Val)
+		}
+		return fmt.Sprintf("inp%d", e.Val)
+	case OpRes:
+		return fmt.Sprintf("out%d", e.Val)
+	}
+
+	var s string
+	if p.goSource && e.Op == OpEquals {
+		s = fmt.Sprintf("eql(%s, %s)", p.expr(e.A), p.expr(e.B))
+	} else {
+		s = fmt.Sprintf("%s %s %s", p.operand(e.A), e.Op, p.operand(e.B))
+	}
+
+	if p.uses[e] > 1 {
+		name := fmt.Sprintf("t%d", p.temps)
+		p.temps++
+		p.assign(name, s)
+		p.names[e] = name
+		return name
+	}
+
+	return s
+}
+
+// Writes each stage's result
+func (p *printer) stages(stages []*Expression) {
+	used := make([]bool, len(stages))
+	walkDAG(stages, func(e *Expression) {
+		if e.Op == OpRes && e.Val >= 0 && e.Val < len(stages) {
+			used[e.Val] = true
+		}
+	})
+
+	for i, s := range stages {
+		if p.goSource {
+			fmt.Fprintf(&p.sb, "\t// Digit %d\n", i)
+		}
+
+		name := fmt.Sprintf("out%d", i)
+		if p.goSource && !used[i] && i != len(stages)-1 {
+			// Go doesn't allow unused variables
+			name = "_"
+		}
+		p.assign(name, p.expr(s))
+	}
+}
+
+// WriteInfix writes the stages' expressions as assignments to out0, out1
+// and so on, with a temporary for everything which is used more than once
+func WriteInfix(w io.Writer, stages []*Expression) error {
+	p := newPrinter(stages, false)
+	p.stages(stages)
+
+	_, err := io.WriteString(w, p.sb.String())
+	return err
+}
+
+// WriteGo writes the stages' expressions as a Go function called 'name',
+// which takes the digits and returns z at the end
+func WriteGo(w io.Writer, name string, stages []*Expression) error {
+	p := newPrinter(stages, true)
+	fmt.Fprintf(&p.sb, "func %s(in []int) int {\n", name)
+	p.stages(stages)
+
+	if len(stages) == 0 {
+		p.sb.WriteString("\treturn 0\n")
+	} else {
+		fmt.Fprintf(&p.sb, "\treturn out%d\n", len(stages)-1)
+	}
+	p.sb.WriteString("}\n\n")
+
+	p.sb.WriteString(`func eql(a, b int) int {
+	if a == b {
+		return 1
+	}
+	return 0
+}
+`)
+
+	_, err := io.WriteString(w, p.sb.String())
+	return err
+}
@@ -0,0 +1,182 @@
+package day24
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestIntern(t *testing.T) {
+	salu := NewSymbolicAlu()
+	program := []string{"inp w", "add x w", "add y w", "mul x 2", "mul y 2", "add z 3", "add w 0"}
+	if err := RunProgram(salu, program, []int{0}); err != nil {
+		t.Fatal(err)
+	}
+
+	if salu.X != salu.Y {
+		t.Errorf("x and y are both inp0 * 2, but they're different expressions")
+	}
+	if salu.W.Op != OpVar {
+		t.Errorf("w is %v, want inp0", salu.W)
+	}
+
+	// 0, inp0, 2, inp0 * 2 and 3
+	if n := salu.DAG.Len(); n != 5 {
+		t.Errorf("DAG has %d expressions, want 5", n)
+	}
+}
+
+func loadStages(t *testing.T) []*Expression {
+	f, err := os.Open("input.txt")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer f.Close()
+
+	program, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stages, err := SymbolicStages(SplitDigits(program))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return stages
+}
+
+func TestWriteInfix(t *testing.T) {
+	// Push inp0 + 4, then pop it for inp1
+	program := []string{
+		"inp w", "add z w", "add z 4",
+		"inp w", "mul x 0", "add x z", "mod x 26", "div z 26", "add x -2",
+		"eql x w", "eql x 0", "mul y 0", "add y 25", "mul y x", "add y 1",
+		"mul z y", "mul y 0", "add y w", "add y 6", "mul y x", "add z y",
+	}
+
+	stages, err := SymbolicStages(SplitDigits(program))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteInfix(&buf, stages); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `out0 = inp0 + 4
+t0 = (((out0 % 26) + -2) == inp1) == 0
+out1 = ((out0 / 26) * ((25 * t0) + 1)) + ((inp1 + 6) * t0)
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// Type checks the Go source for 'stages', the same as compiling it would
+func checkGo(t *testing.T, stages []*Expression) {
+	var buf bytes.Buffer
+	buf.WriteString("package monad\n\n")
+	if err := WriteGo(&buf, "monad", stages); err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "monad.go", buf.String(), 0)
+	if err != nil {
+		t.Fatalf("%v\n%s", err, buf.String())
+	}
+
+	var conf types.Config
+	if _, err := conf.Check("monad", fset, []*ast.File{f}, nil); err != nil {
+		t.Fatalf("%v\n%s", err, buf.String())
+	}
+}
+
+func TestWriteGo(t *testing.T) {
+	checkGo(t, loadStages(t))
+
+	rnd := rand.New(rand.NewSource(24))
+	for i := 0; i < 5; i++ {
+		program, _, _ := monad(rnd, 14)
+
+		stages, err := SymbolicStages(SplitDigits(program))
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkGo(t, stages)
+	}
+
+	// A stage which doesn't use the one before
+	stages, err := SymbolicStages(SplitDigits([]string{"inp w", "add z w", "inp w", "mul z 0"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkGo(t, stages)
+}
+
+// Checks that WriteDOT writes a graph with a node for each stage, and no
+// edges which go nowhere
+func checkDOT(t *testing.T, stages []*Expression) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := WriteDOT(&buf, stages); err != nil {
+		t.Fatal(err)
+	}
+	dot := buf.String()
+
+	if !strings.HasPrefix(dot, "digraph monad {\n") || !strings.HasSuffix(dot, "}\n") {
+		t.Fatalf("not a graph:\n%s", dot)
+	}
+
+	// Each stage's result has a node
+	for i := range stages {
+		if !strings.Contains(dot, fmt.Sprintf("\tout%d -> ", i)) {
+			t.Errorf("no out%d", i)
+		}
+	}
+
+	// Every edge goes to a node which exists
+	nodes := map[string]bool{}
+	for _, line := range strings.Split(dot, "\n") {
+		if fields := strings.Fields(line); len(fields) > 1 && fields[1] != "->" {
+			nodes[fields[0]] = true
+		}
+	}
+	for _, line := range strings.Split(dot, "\n") {
+		if fields := strings.Fields(line); len(fields) == 3 && fields[1] == "->" {
+			to := strings.TrimSuffix(fields[2], ";")
+			if !nodes[to] {
+				t.Errorf("%q goes nowhere", line)
+			}
+		}
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	checkDOT(t, loadStages(t))
+}
+
+func TestWriteDOTPassThrough(t *testing.T) {
+	// Digits 1 and 2 leave z as it was, so their results are digit 0's
+	program := []string{"inp w", "add z w", "inp w", "inp w", "mul x 0"}
+
+	stages, err := SymbolicStages(SplitDigits(program))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stages[2].Op != OpRes {
+		t.Fatalf("digit 2's result is %v, not passed through", stages[2])
+	}
+
+	checkDOT(t, stages)
+}
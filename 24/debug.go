@@ -0,0 +1,283 @@
+package day24
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The state of the debugger between two instructions
+type frame struct {
+	// The next instruction to run, and how much input has been used
+	pc, used int
+	alu      ALUState
+	sym      SymbolicALUState
+	// z at the start of each digit, which the symbolic registers use
+	outs []int
+}
+
+// Debugger runs an ALU program one instruction at a time, on both the real
+// ALU and the symbolic one. It remembers every state it's been through, so
+// it can step backwards as well as forwards.
+type Debugger struct {
+	program []string
+	input   []int
+
+	cur     frame
+	history []frame
+
+	breaks   map[int]bool
+	breakInp bool
+	watches  [4]bool
+}
+
+// NewDebugger returns a debugger at the start of 'program', with 'input' as
+// the digits
+func NewDebugger(program []string, input []int) *Debugger {
+	d := &Debugger{
+		program: program,
+		breaks:  make(map[int]bool),
+	}
+	d.Restart(input)
+
+	return d
+}
+
+// Restart goes back to the start, with new input
+func (d *Debugger) Restart(input []int) {
+	d.input = input
+	d.history = nil
+	d.cur = frame{
+		sym: *NewSymbolicAlu(),
+	}
+}
+
+// Input returns the digits it's running with
+func (d *Debugger) Input() []int {
+	return d.input
+}
+
+// PC returns the number of the next instruction to run, counting from 1 like
+// errors do. It's one past the end when the program has finished.
+func (d *Debugger) PC() int {
+	return d.cur.pc + 1
+}
+
+// Instruction returns instruction 'n', counting from 1
+func (d *Debugger) Instruction(n int) (string, bool) {
+	if n < 1 || n > len(d.program) {
+		return "", false
+	}
+	return d.program[n-1], true
+}
+
+// Len returns the number of instructions
+func (d *Debugger) Len() int {
+	return len(d.program)
+}
+
+// Done returns true if the program has finished
+func (d *Debugger) Done() bool {
+	return d.cur.pc >= len(d.program)
+}
+
+// State returns the real ALU's registers
+func (d *Debugger) State() ALUState {
+	return d.cur.alu
+}
+
+// Steps returns how many instructions have been run, which is how far back
+// it can go
+func (d *Debugger) Steps() int {
+	return len(d.history)
+}
+
+// Break sets or clears a breakpoint on instruction 'n', counting from 1
+func (d *Debugger) Break(n int, on bool) error {
+	if _, ok := d.Instruction(n); !ok {
+		return fmt.Errorf("there's no instruction %d, only 1 to %d", n, len(d.program))
+	}
+
+	if on {
+		d.breaks[n] = true
+	} else {
+		delete(d.breaks, n)
+	}
+
+	return nil
+}
+
+// BreakInp sets whether to stop before every 'inp'
+func (d *Debugger) BreakInp(on bool) {
+	d.breakInp = on
+}
+
+// Watch sets whether to stop when 'r' changes
+func (d *Debugger) Watch(r Register, on bool) {
+	d.watches[r] = on
+}
+
+// Breakpoints returns the instructions with breakpoints, in order
+func (d *Debugger) Breakpoints() []int {
+	var bps []int
+	for n := range d.program {
+		if d.breaks[n+1] {
+			bps = append(bps, n+1)
+		}
+	}
+
+	return bps
+}
+
+// Watches returns the registers being watched
+func (d *Debugger) Watches() []Register {
+	var regs []Register
+	for r, on := range d.watches {
+		if on {
+			regs = append(regs, Register(r))
+		}
+	}
+
+	return regs
+}
+
+func registerValues(s ALUState) [4]int {
+	return [4]int{s.W, s.X, s.Y, s.Z}
+}
+
+// Step runs the next instruction. If it fails, nothing changes, so that the
+// state it failed in can be looked at.
+func (d *Debugger) Step() error {
+	if d.Done() {
+		return fmt.Errorf("the program has finished")
+	}
+
+	insn := d.program[d.cur.pc]
+	next := d.cur
+
+	var v int
+	isInp := strings.HasPrefix(insn, "inp")
+	if isInp {
+		if next.used >= len(d.input) {
+			return fmt.Errorf("instruction %d: input underflow", d.PC())
+		}
+		v = d.input[next.used]
+
+		// Start a new digit, like SymbolicStages
+		if next.sym.InpCount > 0 {
+			next.outs = append(append([]int{}, next.outs...), next.alu.Z)
+			next.sym.Z = next.sym.DAG.Intern(&Expression{
+				Op:  OpRes,
+				Val: len(next.outs) - 1,
+				Min: next.sym.Z.Min,
+				Max: next.sym.Z.Max,
+			})
+		}
+	}
+
+	if _, err := next.alu.Execute(insn, v); err != nil {
+		return fmt.Errorf("instruction %d: %w", d.PC(), err)
+	}
+	if _, err := next.sym.Execute(insn, v); err != nil {
+		return fmt.Errorf("instruction %d: %w", d.PC(), err)
+	}
+
+	if isInp {
+		next.used++
+	}
+	next.pc++
+
+	d.history = append(d.history, d.cur)
+	d.cur = next
+
+	return nil
+}
+
+// Back undoes the last instruction, returning false if there isn't one
+func (d *Debugger) Back() bool {
+	if len(d.history) == 0 {
+		return false
+	}
+
+	d.cur = d.history[len(d.history)-1]
+	d.history = d.history[:len(d.history)-1]
+
+	return true
+}
+
+// Returns why it should stop at the current state, given the registers were
+// 'before' and 'after' either side of the instruction just run (or undone),
+// or "" if it shouldn't
+func (d *Debugger) stopReason(before, after frame) string {
+	for r, on := range d.watches {
+		was, is := registerValues(before.alu)[r], registerValues(after.alu)[r]
+		if on && was != is {
+			return fmt.Sprintf("%v changed from %d to %d", Register(r), was, is)
+		}
+	}
+
+	if d.breaks[d.PC()] {
+		return fmt.Sprintf("breakpoint at instruction %d", d.PC())
+	}
+
+	if insn, ok := d.Instruction(d.PC()); ok && d.breakInp && strings.HasPrefix(insn, "inp") {
+		return fmt.Sprintf("inp at instruction %d", d.PC())
+	}
+
+	return ""
+}
+
+// Continue runs until a breakpoint, a watched register changes, or the end
+// of the program. It returns why it stopped.
+func (d *Debugger) Continue() (string, error) {
+	for !d.Done() {
+		before := d.cur
+		if err := d.Step(); err != nil {
+			return "", err
+		}
+
+		if why := d.stopReason(before, d.cur); why != "" {
+			return why, nil
+		}
+	}
+
+	return "end of the program", nil
+}
+
+// ReverseContinue runs backwards until a breakpoint, a watched register
+// changes (stopping before the instruction which changed it), or the start
+// of the program. It returns why it stopped.
+func (d *Debugger) ReverseContinue() string {
+	for {
+		after := d.cur
+		if !d.Back() {
+			return "start of the program"
+		}
+
+		if why := d.stopReason(d.cur, after); why != "" {
+			return why
+		}
+	}
+}
+
+// WriteSymbolic writes each register as an expression of the digits (inpN)
+// and z at the start of each digit (outN), with the range it's known to be
+// in, and the values of the outNs it uses
+func (d *Debugger) WriteSymbolic(w io.Writer) error {
+	s := d.cur.sym
+	regs := []*Expression{s.W, s.X, s.Y, s.Z}
+
+	p := newPrinter(regs, false)
+	for i, e := range regs {
+		p.assign(Register(i).String(), fmt.Sprintf("%s  {%v}", p.expr(e), e.interval()))
+	}
+
+	walkDAG(regs, func(e *Expression) {
+		if e.Op == OpRes && e.Val < len(d.cur.outs) {
+			fmt.Fprintf(&p.sb, "where out%d = %d\n", e.Val, d.cur.outs[e.Val])
+		}
+	})
+
+	_, err := io.WriteString(w, p.sb.String())
+	return err
+}
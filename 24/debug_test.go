@@ -0,0 +1,156 @@
+package day24
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// Two digits: z = (inp0 + 1) * 26 + inp1, and x = inp1 == 3
+var debugProgram = []string{
+	"inp w", "add z w", "add z 1", "mul z 26",
+	"inp w", "add z w", "mul x 0", "add x w", "eql x 3",
+}
+
+func TestDebuggerStep(t *testing.T) {
+	d := NewDebugger(debugProgram, []int{4, 3})
+
+	for !d.Done() {
+		if err := d.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := ALUState{W: 3, X: 1, Z: 133}
+	if got := d.State(); got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	// The same as the real thing
+	var alu ALUState
+	if err := RunProgram(&alu, debugProgram, []int{4, 3}); err != nil {
+		t.Fatal(err)
+	}
+	if alu != want {
+		t.Errorf("RunProgram got %+v, want %+v", alu, want)
+	}
+
+	if err := d.Step(); err == nil {
+		t.Error("stepped past the end")
+	}
+
+	// All the way back to the start
+	for i := 0; i < 4; i++ {
+		d.Back()
+	}
+	if pc, st := d.PC(), d.State(); pc != 6 || st != (ALUState{W: 3, Z: 130}) {
+		t.Errorf("at instruction %d with %+v, want 6 with z = 130", pc, st)
+	}
+
+	for d.Back() {
+	}
+	if pc, st := d.PC(), d.State(); pc != 1 || st != (ALUState{}) {
+		t.Errorf("at instruction %d with %+v, want the start", pc, st)
+	}
+}
+
+func TestDebuggerUnderflow(t *testing.T) {
+	d := NewDebugger(debugProgram, []int{4})
+
+	_, err := d.Continue()
+	if err == nil || !strings.Contains(err.Error(), "instruction 5: input underflow") {
+		t.Fatalf("got %v, want an underflow at instruction 5", err)
+	}
+
+	// It stays where it failed
+	if pc, z := d.PC(), d.State().Z; pc != 5 || z != 130 {
+		t.Errorf("at instruction %d with z = %d, want 5 with 130", pc, z)
+	}
+
+	// Until there's more input
+	d.Restart([]int{4, 3})
+	if why, err := d.Continue(); err != nil || why != "end of the program" {
+		t.Errorf("got %q, %v", why, err)
+	}
+}
+
+func TestDebuggerBreakpoints(t *testing.T) {
+	d := NewDebugger(debugProgram, []int{4, 3})
+
+	if err := d.Break(100, true); err == nil {
+		t.Error("no error for a breakpoint past the end")
+	}
+
+	d.Break(4, true)
+	d.BreakInp(true)
+	d.Watch(parseRegister("x"), true)
+
+	var stops []string
+	for !d.Done() {
+		why, err := d.Continue()
+		if err != nil {
+			t.Fatal(err)
+		}
+		stops = append(stops, why)
+	}
+
+	want := []string{
+		"breakpoint at instruction 4",
+		"inp at instruction 5",
+		"x changed from 0 to 3",
+		"x changed from 3 to 1",
+	}
+	if strings.Join(stops, "\n") != strings.Join(want, "\n") {
+		t.Errorf("stopped for:\n%s\nwant:\n%s", strings.Join(stops, "\n"), strings.Join(want, "\n"))
+	}
+
+	if why, err := d.Continue(); err != nil || why != "end of the program" {
+		t.Errorf("got %q, %v at the end", why, err)
+	}
+
+	// Backwards, it stops before the instructions which changed x
+	var back []string
+	for d.Steps() > 0 {
+		back = append(back, fmt.Sprintf("%s @%d", d.ReverseContinue(), d.PC()))
+	}
+
+	want = []string{
+		"x changed from 3 to 1 @9",
+		"x changed from 0 to 3 @8",
+		"inp at instruction 5 @5",
+		"breakpoint at instruction 4 @4",
+		"inp at instruction 1 @1",
+	}
+	if strings.Join(back, "\n") != strings.Join(want, "\n") {
+		t.Errorf("stopped for:\n%s\nwant:\n%s", strings.Join(back, "\n"), strings.Join(want, "\n"))
+	}
+
+	if why := d.ReverseContinue(); why != "start of the program" {
+		t.Errorf("got %q at the start", why)
+	}
+}
+
+func TestDebuggerSymbolic(t *testing.T) {
+	d := NewDebugger(debugProgram, []int{4, 3})
+	for d.PC() < 7 {
+		if err := d.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := d.WriteSymbolic(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `w = inp1  {1..9}
+x = 0  {0}
+y = 0  {0}
+z = out0 + inp1  {53..269}
+where out0 = 130
+`
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
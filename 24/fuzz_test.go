@@ -0,0 +1,68 @@
+package day24
+
+import (
+	"strings"
+	"testing"
+)
+
+func FuzzSimplify(f *testing.F) {
+	f.Add([]byte{0, 0, 0, 2, 1, 0, 1, 1, 0x80, 4, 1, 26, 5, 1, 0x81, 5, 1, 8}, []byte{5})
+	f.Add([]byte{0, 0, 0, 1, 3, 0x80, 1, 3, 20, 3, 3, 2, 0, 1, 0, 5, 1, 0x83}, []byte{9, 3})
+
+	f.Fuzz(func(t *testing.T, data, digits []byte) {
+		program := fuzzProgram(data)
+
+		in := make([]int, strings.Count(strings.Join(program, "\n"), "inp"))
+		for i := range in {
+			in[i] = 1
+			if len(digits) > 0 {
+				in[i] = 1 + int(digits[i%len(digits)])%9
+			}
+		}
+
+		// Programs which crash the ALU aren't interesting
+		var alu ALUState
+		if err := RunProgram(&alu, program, in); err != nil {
+			return
+		}
+
+		salu := NewSymbolicAlu()
+		if err := RunProgram(salu, program, in); err != nil {
+			t.Fatalf("%q: concrete ALU was fine, but symbolic failed: %v", program, err)
+		}
+
+		ev := newEvaluator(t, in, nil, true)
+		for _, reg := range []struct {
+			name string
+			expr *Expression
+			want int
+		}{
+			{"w", salu.W, alu.W},
+			{"x", salu.X, alu.X},
+			{"y", salu.Y, alu.Y},
+			{"z", salu.Z, alu.Z},
+		} {
+			if got := ev.eval(reg.expr); got != reg.want {
+				t.Fatalf("%q with %v: %s = %d, want %d", program, in, reg.name, got, reg.want)
+			}
+		}
+	})
+}
+
+func FuzzAssemble(f *testing.F) {
+	f.Add([]byte{0, 0, 0, 2, 1, 0, 1, 1, 0x80, 4, 1, 26, 5, 1, 0x81, 5, 1, 8}, []byte{5})
+	f.Add([]byte{0, 0, 0, 3, 3, 8, 4, 0, 0x81, 0, 2, 0}, []byte{9})
+
+	f.Fuzz(func(t *testing.T, data, digits []byte) {
+		program := fuzzProgram(data)
+
+		// Unlike FuzzSimplify, there might not be enough input, to check
+		// underflow is the same too
+		in := make([]int, len(digits))
+		for i, d := range digits {
+			in[i] = 1 + int(d)%9
+		}
+
+		checkAssembled(t, program, in)
+	})
+}
@@ -0,0 +1,93 @@
+package day24
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+)
+
+// The instructions for one digit of a MONAD. Digits which "push" divide z by
+// 1, and have an 'a' of at least 10, so that x never matches the input.
+// Digits which "pop" divide z by 26, and only leave z smaller if the digit
+// matches the one which pushed, plus its 'b', plus this one's 'a'.
+const monadDigit = `inp w
+mul x 0
+add x z
+mod x 26
+div z %d
+add x %d
+eql x w
+eql x 0
+mul y 0
+add y 25
+mul y x
+add y 1
+mul z y
+mul y 0
+add y w
+add y %d
+mul y x
+add z y`
+
+// monad returns a random MONAD with 'n' digits (which must be even), and its
+// largest and smallest valid model numbers
+func monad(rnd *rand.Rand, n int) ([]string, string, string) {
+	largest := make([]byte, n)
+	smallest := make([]byte, n)
+
+	var program []string
+	var stack []int
+	bs := make([]int, n)
+	pushes := n / 2
+	for i := 0; i < n; i++ {
+		div, a := 1, 10+rnd.Intn(6)
+		bs[i] = 1 + rnd.Intn(16)
+
+		if pushes == 0 || (len(stack) > 0 && rnd.Intn(2) == 0) {
+			// This digit has to be the pushed one plus 'diff'
+			j := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+
+			diff := rnd.Intn(17) - 8
+			div, a = 26, diff-bs[j]
+
+			if diff >= 0 {
+				largest[j], largest[i] = byte('9'-diff), '9'
+				smallest[j], smallest[i] = '1', byte('1'+diff)
+			} else {
+				largest[j], largest[i] = '9', byte('9'+diff)
+				smallest[j], smallest[i] = byte('1'-diff), '1'
+			}
+		} else {
+			stack = append(stack, i)
+			pushes--
+		}
+
+		program = append(program, strings.Split(fmt.Sprintf(monadDigit, div, a, bs[i]), "\n")...)
+	}
+
+	return program, string(largest), string(smallest)
+}
+
+// Generate writes a MONAD which checks 'size' digits (14 by default, and it
+// must be even). It has the same structure as the real one, so that there
+// are valid model numbers.
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 14
+	}
+	if size%2 != 0 {
+		return fmt.Errorf("the number of digits must be even, not %d", size)
+	}
+
+	program, _, _ := monad(rnd, size)
+
+	bw := bufio.NewWriter(w)
+	for _, insn := range program {
+		fmt.Fprintln(bw, insn)
+	}
+
+	return bw.Flush()
+}
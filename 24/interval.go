@@ -0,0 +1,439 @@
+package day24
+
+import (
+	"fmt"
+	"math"
+)
+
+// Interval is the range of values an expression can have, Lo to Hi
+// inclusive. It's empty if Lo > Hi, which means there's no way for it to
+// have a value without the ALU failing first.
+type Interval struct {
+	Lo, Hi int
+}
+
+// Anything at all, including the results of overflows
+var full = Interval{math.MinInt, math.MaxInt}
+
+var empty = Interval{1, 0}
+
+func point(v int) Interval {
+	return Interval{v, v}
+}
+
+func (iv Interval) Empty() bool {
+	return iv.Lo > iv.Hi
+}
+
+func (iv Interval) Contains(v int) bool {
+	return iv.Lo <= v && v <= iv.Hi
+}
+
+// Point returns the value, if there's only one
+func (iv Interval) Point() (int, bool) {
+	return iv.Lo, iv.Lo == iv.Hi
+}
+
+func (iv Interval) Intersect(o Interval) Interval {
+	return Interval{max(iv.Lo, o.Lo), min(iv.Hi, o.Hi)}
+}
+
+func (iv Interval) String() string {
+	switch {
+	case iv.Empty():
+		return "{}"
+	case iv.Lo == iv.Hi:
+		return fmt.Sprint(iv.Lo)
+	case iv == full:
+		return "any"
+	}
+
+	lo, hi := fmt.Sprint(iv.Lo), fmt.Sprint(iv.Hi)
+	if iv.Lo == math.MinInt {
+		lo = "-inf"
+	}
+	if iv.Hi == math.MaxInt {
+		hi = "inf"
+	}
+
+	return fmt.Sprintf("%s..%s", lo, hi)
+}
+
+func (e *Expression) interval() Interval {
+	return Interval{e.Min, e.Max}
+}
+
+// Overflow-checked arithmetic, for the ends of intervals
+func addOK(a, b int) (int, bool) {
+	s := a + b
+	return s, (s > a) == (b > 0)
+}
+
+func mulOK(a, b int) (int, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	p := a * b
+	return p, p/b == a && !(a == -1 && b == math.MinInt) && !(b == -1 && a == math.MinInt)
+}
+
+// Division rounding down and up, rather than towards zero
+func floorDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) != (b < 0)) {
+		q--
+	}
+	return q
+}
+
+func ceilDiv(a, b int) int {
+	q := a / b
+	if (a%b != 0) && ((a < 0) == (b < 0)) {
+		q++
+	}
+	return q
+}
+
+// Returns the range of 'f' applied to each combination of the ends of 'a' and
+// 'b', or 'full' if any overflows
+func corners(a, b Interval, f func(x, y int) (int, bool)) Interval {
+	r := empty
+	for _, x := range [2]int{a.Lo, a.Hi} {
+		for _, y := range [2]int{b.Lo, b.Hi} {
+			v, ok := f(x, y)
+			if !ok {
+				return full
+			}
+			if r.Empty() {
+				r = point(v)
+			}
+			r.Lo, r.Hi = min(r.Lo, v), max(r.Hi, v)
+		}
+	}
+
+	return r
+}
+
+// Returns the range of a / b, for b all positive or all negative
+func divRange(a, b Interval) Interval {
+	return corners(a, b, func(x, y int) (int, bool) {
+		return x / y, !(x == math.MinInt && y == -1)
+	})
+}
+
+// forward returns the range of 'a op b', given the ranges of a and b. It
+// assumes the ALU didn't fail, so for example a divisor can't be 0.
+func forward(op Operator, a, b Interval) Interval {
+	if a.Empty() || b.Empty() {
+		return empty
+	}
+
+	switch op {
+	case OpAdd:
+		return corners(a, b, addOK)
+	case OpMul:
+		return corners(a, b, mulOK)
+	case OpDiv:
+		// Split the divisor into its negative and positive parts
+		r := empty
+		for _, part := range []Interval{b.Intersect(Interval{math.MinInt, -1}), b.Intersect(Interval{1, math.MaxInt})} {
+			if part.Empty() {
+				continue
+			}
+
+			q := divRange(a, part)
+			if r.Empty() {
+				r = q
+			}
+			r.Lo, r.Hi = min(r.Lo, q.Lo), max(r.Hi, q.Hi)
+		}
+		return r
+	case OpMod:
+		a = a.Intersect(Interval{0, math.MaxInt})
+		b = b.Intersect(Interval{1, math.MaxInt})
+		if a.Empty() || b.Empty() {
+			return empty
+		}
+
+		// Within one multiple of a constant, mod is just an offset
+		if c, ok := b.Point(); ok && a.Lo/c == a.Hi/c {
+			return Interval{a.Lo % c, a.Hi % c}
+		}
+		return Interval{0, min(a.Hi, b.Hi-1)}
+	case OpEquals:
+		if _, ok := a.Point(); ok && a == b {
+			return point(1)
+		}
+		if a.Intersect(b).Empty() {
+			return point(0)
+		}
+		return Interval{0, 1}
+	}
+
+	return full
+}
+
+// Returns the values of 'a' for which a * b can be in 'r', for some value of
+// b, which must be all positive
+func mulInverse(r, a, b Interval) Interval {
+	if r.Lo != math.MinInt {
+		a.Lo = max(a.Lo, min(ceilDiv(r.Lo, b.Lo), ceilDiv(r.Lo, b.Hi)))
+	}
+	if r.Hi != math.MaxInt {
+		a.Hi = min(a.Hi, max(floorDiv(r.Hi, b.Lo), floorDiv(r.Hi, b.Hi)))
+	}
+
+	return a
+}
+
+// Narrows 'a' to the values for which a * b can be in 'r'
+func narrowMul(r, a, b Interval) Interval {
+	switch {
+	case a.Empty() || b.Empty():
+		return empty
+	case b.Lo > 0:
+		return mulInverse(r, a, b)
+	case b.Hi < 0:
+		// a * b = -a * -b
+		neg := func(iv Interval) Interval {
+			if iv.Lo == math.MinInt || iv.Hi == math.MinInt {
+				return full
+			}
+			return Interval{-iv.Hi, -iv.Lo}
+		}
+		if r.Lo == math.MinInt {
+			r.Lo = math.MinInt + 1
+		}
+		return mulInverse(neg(r), a, neg(b))
+	}
+
+	// b could be 0, which says nothing about a
+	return a
+}
+
+// backward narrows the ranges of a and b to the values which could give a
+// result in 'r'. If either is empty, there are none.
+func backward(op Operator, r, a, b Interval) (Interval, Interval) {
+	if r.Empty() || a.Empty() || b.Empty() {
+		return empty, empty
+	}
+
+	switch op {
+	case OpAdd:
+		if lo, ok := addOK(r.Lo, -b.Hi); ok && r.Lo != math.MinInt && b.Hi != math.MinInt {
+			a.Lo = max(a.Lo, lo)
+		}
+		if hi, ok := addOK(r.Hi, -b.Lo); ok && r.Hi != math.MaxInt && b.Lo != math.MinInt {
+			a.Hi = min(a.Hi, hi)
+		}
+		if lo, ok := addOK(r.Lo, -a.Hi); ok && r.Lo != math.MinInt && a.Hi != math.MinInt {
+			b.Lo = max(b.Lo, lo)
+		}
+		if hi, ok := addOK(r.Hi, -a.Lo); ok && r.Hi != math.MaxInt && a.Lo != math.MinInt {
+			b.Hi = min(b.Hi, hi)
+		}
+	case OpMul:
+		a = narrowMul(r, a, b)
+		b = narrowMul(r, b, a)
+	case OpDiv:
+		// The ALU fails on a divisor of 0
+		if b.Lo == 0 {
+			b.Lo = 1
+		}
+		if b.Hi == 0 {
+			b.Hi = -1
+		}
+
+		// Only constant positive divisors, which is what MONADs have.
+		// Division truncates, so each quotient comes from c values
+		// of a, or 2c - 1 for 0.
+		if c, ok := b.Point(); ok && c > 0 {
+			if r.Lo != math.MinInt {
+				lo, ok := mulOK(r.Lo, c)
+				if ok && r.Lo <= 0 {
+					lo, ok = addOK(lo, -(c - 1))
+				}
+				if ok {
+					a.Lo = max(a.Lo, lo)
+				}
+			}
+			if r.Hi != math.MaxInt {
+				hi, ok := mulOK(r.Hi, c)
+				if ok && r.Hi >= 0 {
+					hi, ok = addOK(hi, c-1)
+				}
+				if ok {
+					a.Hi = min(a.Hi, hi)
+				}
+			}
+		}
+	case OpMod:
+		// The ALU fails unless a >= 0 and b > 0
+		a = a.Intersect(Interval{0, math.MaxInt})
+		b = b.Intersect(Interval{1, math.MaxInt})
+
+		if c, ok := b.Point(); ok && !a.Empty() && a.Lo/c == a.Hi/c {
+			base := a.Lo - a.Lo%c
+			a = a.Intersect(Interval{base + max(r.Lo, 0), base + min(r.Hi, c-1)})
+		}
+	case OpEquals:
+		switch {
+		case r == point(1):
+			a = a.Intersect(b)
+			b = b.Intersect(a)
+		case r == point(0):
+			// Only the ends can be cut off
+			if v, ok := b.Point(); ok {
+				if a.Lo == v {
+					a.Lo++
+				} else if a.Hi == v {
+					a.Hi--
+				}
+			}
+			if v, ok := a.Point(); ok {
+				if b.Lo == v {
+					b.Lo++
+				} else if b.Hi == v {
+					b.Hi--
+				}
+			}
+		}
+	}
+
+	return a, b
+}
+
+// ranges is an interval analysis of an expression, which works out the range
+// of every sub-expression from the ranges of its inputs, and then narrows
+// them again from the range the result has to be in
+type ranges struct {
+	of map[*Expression]Interval
+	// Every sub-expression once, operands before the expressions using
+	// them
+	order []*Expression
+}
+
+// Gives up narrowing after this many passes, which is still sound, just
+// less precise. Each pass only ever makes the ranges smaller.
+const maxPasses = 50
+
+// newRanges analyses 'root', with the ranges of digits and previous stages'
+// results given by 'leaf'
+func newRanges(root *Expression, leaf func(e *Expression) Interval) *ranges {
+	r := &ranges{
+		of: make(map[*Expression]Interval),
+	}
+
+	var walk func(e *Expression)
+	walk = func(e *Expression) {
+		if _, ok := r.of[e]; ok {
+			return
+		}
+
+		switch e.Op {
+		case OpLiteral:
+			r.of[e] = point(e.Val)
+		case OpVar, OpRes:
+			r.of[e] = leaf(e).Intersect(e.interval())
+		default:
+			walk(e.A)
+			walk(e.B)
+			r.of[e] = forward(e.Op, r.of[e.A], r.of[e.B])
+		}
+
+		r.order = append(r.order, e)
+	}
+	walk(root)
+
+	return r
+}
+
+// Intersects the range of 'e' with 'v', returning true if it changed
+func (r *ranges) set(e *Expression, v Interval) bool {
+	old := r.of[e]
+	v = v.Intersect(old)
+	if v.Empty() {
+		v = empty
+	}
+	r.of[e] = v
+
+	return v != old
+}
+
+// narrow narrows every range to the values which could give 'root' a value
+// in 'target'. It returns false if there aren't any.
+func (r *ranges) narrow(root *Expression, target Interval) bool {
+	r.set(root, target)
+
+	for pass := 0; pass < maxPasses; pass++ {
+		changed := false
+
+		// Everything which uses an expression comes after it, so
+		// that's all been taken into account by the time it's
+		// narrowed
+		for i := len(r.order) - 1; i >= 0; i-- {
+			e := r.order[i]
+			if e.A == nil {
+				continue
+			}
+
+			a, b := backward(e.Op, r.of[e], r.of[e.A], r.of[e.B])
+			changed = r.set(e.A, a) || changed
+			changed = r.set(e.B, b) || changed
+		}
+
+		// Simplify can copy inputs, so they aren't always the same
+		// expression
+		type input struct {
+			op  Operator
+			val int
+		}
+		inputs := make(map[input]Interval)
+		for _, e := range r.order {
+			if e.Op == OpVar || e.Op == OpRes {
+				in := input{e.Op, e.Val}
+				if v, ok := inputs[in]; ok {
+					inputs[in] = v.Intersect(r.of[e])
+				} else {
+					inputs[in] = r.of[e]
+				}
+			}
+		}
+
+		for _, e := range r.order {
+			switch {
+			case e.A != nil:
+				changed = r.set(e, forward(e.Op, r.of[e.A], r.of[e.B])) || changed
+			case e.Op == OpVar || e.Op == OpRes:
+				changed = r.set(e, inputs[input{e.Op, e.Val}]) || changed
+			}
+		}
+
+		// Every sub-expression gets evaluated, so if any of them
+		// can't have a value, nothing can
+		for _, e := range r.order {
+			if r.of[e].Empty() {
+				return false
+			}
+		}
+
+		if !changed {
+			break
+		}
+	}
+
+	return true
+}
+
+// leaf returns the range of the inputs with operator 'op', or 'full' if
+// there aren't any
+func (r *ranges) leaf(op Operator) Interval {
+	v := full
+	for _, e := range r.order {
+		if e.Op == op {
+			v = v.Intersect(r.of[e])
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,120 @@
+package day24
+
+import (
+	"math"
+	"testing"
+)
+
+// Returns a op b, the same way the ALU does it, and false if the ALU would
+// fail
+func apply(op Operator, a, b int) (int, bool) {
+	e := &Expression{Op: op, A: &Expression{Val: a}, B: &Expression{Val: b}}
+	v, err := e.Eval(nil, nil)
+	return v, err == nil
+}
+
+// Every interval in -4..4
+func smallIntervals() []Interval {
+	var ivs []Interval
+	for lo := -4; lo <= 4; lo++ {
+		for hi := lo; hi <= 4; hi++ {
+			ivs = append(ivs, Interval{lo, hi})
+		}
+	}
+	return ivs
+}
+
+var operators = []Operator{OpAdd, OpMul, OpDiv, OpMod, OpEquals}
+
+func TestForward(t *testing.T) {
+	ivs := smallIntervals()
+
+	for _, op := range operators {
+		for _, a := range ivs {
+			for _, b := range ivs {
+				r := forward(op, a, b)
+
+				for x := a.Lo; x <= a.Hi; x++ {
+					for y := b.Lo; y <= b.Hi; y++ {
+						if v, ok := apply(op, x, y); ok && !r.Contains(v) {
+							t.Fatalf("%v %s %v is %v, but %d %s %d = %d", a, op, b, r, x, op, y, v)
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestBackward(t *testing.T) {
+	ivs := smallIntervals()
+
+	for _, op := range operators {
+		for _, a := range ivs {
+			for _, b := range ivs {
+				for _, r := range ivs {
+					na, nb := backward(op, r, a, b)
+
+					// Nothing which can give a result in 'r'
+					// can be cut off
+					for x := a.Lo; x <= a.Hi; x++ {
+						for y := b.Lo; y <= b.Hi; y++ {
+							v, ok := apply(op, x, y)
+							if ok && r.Contains(v) && !(na.Contains(x) && nb.Contains(y)) {
+								t.Fatalf("%v %s %v in %v narrowed to %v %s %v, but %d %s %d = %d",
+									a, op, b, r, na, op, nb, x, op, y, v)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestIntervalOverflow(t *testing.T) {
+	big := Interval{math.MaxInt / 2, math.MaxInt}
+	for _, op := range []Operator{OpAdd, OpMul} {
+		if r := forward(op, big, big); r != full {
+			t.Errorf("%v %s %v is %v, want %v", big, op, big, r, full)
+		}
+	}
+
+	// These would overflow, so mustn't narrow anything
+	a, b := backward(OpAdd, Interval{math.MinInt, 0}, full, Interval{1, 1})
+	if a.Lo != math.MinInt || b != (Interval{1, 1}) {
+		t.Errorf("got %v and %v", a, b)
+	}
+}
+
+func TestRangesNarrow(t *testing.T) {
+	// The last stage of a MONAD, popping a digit which has to match
+	// (z % 26) - 4
+	program := []string{
+		"inp w", "mul x 0", "add x z", "mod x 26", "div z 26", "add x -4",
+		"eql x w", "eql x 0", "mul y 0", "add y 25", "mul y x", "add y 1",
+		"mul z y", "mul y 0", "add y w", "add y 5", "mul y x", "add z y",
+	}
+
+	salu := NewSymbolicAlu()
+	salu.Z = &Expression{Op: OpRes, Val: 0, Min: 0, Max: math.MaxInt}
+	if err := RunProgram(salu, program, []int{0}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := newRanges(salu.Z, func(e *Expression) Interval {
+		return full
+	})
+	if !r.narrow(salu.Z, point(0)) {
+		t.Fatal("z can be 0")
+	}
+
+	// z / 26 has to be 0, and z % 26 - 4 a digit
+	if got, want := r.leaf(OpRes), (Interval{5, 13}); got != want {
+		t.Errorf("z before is %v, want %v", got, want)
+	}
+
+	if r.narrow(salu.Z, point(1000)) {
+		t.Errorf("z can't be 1000, but got %v", r.leaf(OpRes))
+	}
+}
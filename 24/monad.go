@@ -0,0 +1,314 @@
+package day24
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Returns an error unless 'e', the expression for stage 'i', only uses digit
+// 'i' and the result of the stage before. Otherwise the search would have to
+// keep track of more than 'z' between stages.
+func checkStage(e *Expression, i int) error {
+	seen := make(map[*Expression]bool)
+
+	var walk func(e *Expression) error
+	walk = func(e *Expression) error {
+		if e == nil || seen[e] {
+			return nil
+		}
+		seen[e] = true
+
+		switch {
+		case e.Op == OpVar && e.Val != i:
+			return fmt.Errorf("stage %d uses digit %d", i, e.Val)
+		case e.Op == OpRes && e.Val != i-1:
+			return fmt.Errorf("stage %d uses the result of stage %d", i, e.Val)
+		}
+
+		if err := walk(e.A); err != nil {
+			return err
+		}
+		return walk(e.B)
+	}
+
+	return walk(e)
+}
+
+// Analysis is what interval analysis can prove about a MONAD's stages
+type Analysis struct {
+	// Z[i] is the range z has to be in before stage i, for it to be able
+	// to get to 0. There's one more, for after the last stage, which is
+	// always 0.
+	Z []Interval
+	// Digits[i] are the values digit i can have, and still get to z == 0
+	Digits [][]int
+}
+
+func (a *Analysis) String() string {
+	var sb strings.Builder
+	for i, digits := range a.Digits {
+		fmt.Fprintf(&sb, "digit %2d: %v, z before: %v\n", i, digits, a.Z[i])
+	}
+
+	return sb.String()
+}
+
+// Narrows the ranges of z before and after stage i, and of its digit,
+// returning false if there's no way for z to end up in the range after it
+func (a *Analysis) solve(stage *Expression, i int, digit Interval) (Interval, bool) {
+	r := newRanges(stage, func(e *Expression) Interval {
+		if e.Op == OpVar {
+			return digit
+		}
+		return a.Z[i]
+	})
+
+	if !r.narrow(stage, a.Z[i+1]) {
+		return empty, false
+	}
+
+	// Stage 0 starts with a literal 0, not a result
+	if i > 0 {
+		a.Z[i] = a.Z[i].Intersect(r.leaf(OpRes))
+	}
+	a.Z[i+1] = r.of[stage]
+
+	return r.leaf(OpVar).Intersect(digit), true
+}
+
+// Returns the smallest range containing all of 'digits'
+func hull(digits []int) Interval {
+	iv := empty
+	for _, d := range digits {
+		if iv.Empty() {
+			iv = point(d)
+		}
+		iv.Lo, iv.Hi = min(iv.Lo, d), max(iv.Hi, d)
+	}
+
+	return iv
+}
+
+// Returns true if 'a' still has the ranges 'z' and the digits 'digits'
+func (a *Analysis) same(z []Interval, digits [][]int) bool {
+	for i := range a.Z {
+		if a.Z[i] != z[i] {
+			return false
+		}
+	}
+
+	for i := range a.Digits {
+		if len(a.Digits[i]) != len(digits[i]) {
+			return false
+		}
+		for j := range a.Digits[i] {
+			if a.Digits[i][j] != digits[i][j] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// Analyse works out the range z has to be in before each stage, and which
+// values each digit can have, for the MONAD to end with z == 0. The stages
+// must have passed checkStage.
+//
+// It propagates ranges forwards through the stages, from z == 0 at the
+// start, and backwards from z == 0 at the end, and then tries each value of
+// each digit, over and over until nothing changes. Anything ruled out
+// definitely can't be part of a valid model number, but not everything
+// left is, because intervals can't capture how digits depend on each other.
+//
+// It's an error if a stage uses anything but its own digit and the z before
+// it, or if there's no way at all to get to z == 0.
+func Analyse(stages []*Expression) (*Analysis, error) {
+	for i, stage := range stages {
+		if err := checkStage(stage, i); err != nil {
+			return nil, err
+		}
+	}
+
+	n := len(stages)
+	a := &Analysis{
+		Z:      make([]Interval, n+1),
+		Digits: make([][]int, n),
+	}
+
+	for i := range stages {
+		a.Z[i] = full
+		a.Digits[i] = []int{1, 2, 3, 4, 5, 6, 7, 8, 9}
+	}
+	a.Z[0] = point(0)
+	a.Z[n] = point(0)
+
+	for pass := 0; pass < maxPasses; pass++ {
+		// The slices in Digits are replaced rather than changed, so
+		// copying the outer one is enough
+		z := append([]Interval{}, a.Z...)
+		digits := append([][]int{}, a.Digits...)
+
+		visit := func(i int) error {
+			digit, ok := a.solve(stages[i], i, hull(a.Digits[i]))
+			if !ok {
+				return fmt.Errorf("there are no valid model numbers: stage %d can't get z to %v", i, a.Z[i+1])
+			}
+
+			var digits []int
+			for _, d := range a.Digits[i] {
+				if !digit.Contains(d) {
+					continue
+				}
+
+				// Don't let a single digit narrow the ranges for
+				// all of them
+				try := *a
+				try.Z = append([]Interval{}, a.Z...)
+				if _, ok := try.solve(stages[i], i, point(d)); ok {
+					digits = append(digits, d)
+				}
+			}
+
+			if len(digits) == 0 {
+				return fmt.Errorf("there are no valid model numbers: digit %d can't be anything", i)
+			}
+			a.Digits[i] = digits
+
+			return nil
+		}
+
+		for i := 0; i < n; i++ {
+			if err := visit(i); err != nil {
+				return nil, err
+			}
+		}
+		for i := n - 1; i >= 0; i-- {
+			if err := visit(i); err != nil {
+				return nil, err
+			}
+		}
+
+		if a.same(z, digits) {
+			break
+		}
+	}
+
+	return a, nil
+}
+
+// monadSearch looks for a valid model number, one digit at a time, using the
+// expression for each stage's z in terms of the z before and its digit
+type monadSearch struct {
+	stages []*Expression
+	// z has to be in limits[i] before stage i, for it to get back down
+	// to 0 by the end
+	limits []Interval
+	// The z values before each stage which have no way to get to 0
+	dead []map[int]bool
+	// The digits to try for each stage, in order
+	order [][]int
+
+	in, outs []int
+}
+
+func (m *monadSearch) search(i, z int) bool {
+	if i == len(m.stages) {
+		return z == 0
+	}
+
+	if !m.limits[i].Contains(z) || m.dead[i][z] {
+		return false
+	}
+
+	if i > 0 {
+		m.outs[i-1] = z
+	}
+
+	for _, w := range m.order[i] {
+		m.in[i] = w
+
+		// The ALU would fail (dividing by zero, say) with this digit,
+		// so it can't be part of a valid model number, but the
+		// others still might be. z is only a dead end if they all are.
+		next, err := m.stages[i].Eval(m.in, m.outs)
+		if err != nil {
+			continue
+		}
+
+		if m.search(i+1, next) {
+			return true
+		}
+	}
+
+	m.dead[i][z] = true
+
+	return false
+}
+
+// ModelNumber finds the largest (or smallest) valid model number for a MONAD
+// program.
+//
+// The program is evaluated symbolically one digit at a time, which gives an
+// expression for z at the end of each digit's instructions, in terms of z
+// before them and the digit. Then it's a depth-first search over the digits,
+// best digits first, remembering which z values were a dead end for each
+// digit.
+//
+// It relies on only z carrying over from one digit to the next, which is
+// checked. Interval analysis (see Analyse) says which digits are worth
+// trying, and how big z can get before there's no way back to 0, which
+// prunes most of the search. The answer is checked on the real ALU at the
+// end too.
+func ModelNumber(program []string, largest bool) (string, error) {
+	digits := SplitDigits(program)
+
+	stages, err := SymbolicStages(digits)
+	if err != nil {
+		return "", err
+	}
+
+	a, err := Analyse(stages)
+	if err != nil {
+		return "", err
+	}
+
+	m := &monadSearch{
+		stages: stages,
+		limits: a.Z,
+		dead:   make([]map[int]bool, len(stages)),
+		order:  make([][]int, len(stages)),
+		in:     make([]int, len(stages)),
+		outs:   make([]int, len(stages)),
+	}
+
+	for i := range stages {
+		m.dead[i] = make(map[int]bool)
+		m.order[i] = a.Digits[i]
+		if largest {
+			order := make([]int, len(a.Digits[i]))
+			for j, d := range a.Digits[i] {
+				order[len(order)-1-j] = d
+			}
+			m.order[i] = order
+		}
+	}
+
+	if !m.search(0, 0) {
+		return "", fmt.Errorf("there are no valid model numbers")
+	}
+
+	var sb strings.Builder
+	for _, d := range m.in {
+		sb.WriteByte(byte('0' + d))
+	}
+	number := sb.String()
+
+	if ok, err := Check(digits, number); err != nil {
+		return "", err
+	} else if !ok {
+		return "", fmt.Errorf("found %s, but the ALU says it isn't valid", number)
+	}
+
+	return number, nil
+}
@@ -0,0 +1,129 @@
+package day24
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestGeneratedMONAD(t *testing.T) {
+	rnd := rand.New(rand.NewSource(24))
+
+	for i := 0; i < 20; i++ {
+		program, largest, smallest := monad(rnd, 14)
+
+		parsed, err := Parse(strings.NewReader(strings.Join(program, "\n")))
+		if err != nil {
+			t.Fatal(err)
+		}
+		digits := SplitDigits(parsed)
+
+		for _, input := range []string{largest, smallest} {
+			if ok, err := Check(digits, input); err != nil || !ok {
+				t.Fatalf("%s should be valid (%v)", input, err)
+			}
+		}
+
+		// Whatever else the analysis rules out, it can't be these
+		stages, err := SymbolicStages(digits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, err := Analyse(stages)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, input := range []string{largest, smallest} {
+			in := parseInput(input)
+			outs := make([]int, len(stages))
+			z := 0
+			for i, stage := range stages {
+				if !a.Z[i].Contains(z) || !containsInt(a.Digits[i], in[i]) {
+					t.Fatalf("%s: analysis rules out digit %d = %d with z = %d\n%v", input, i, in[i], z, a)
+				}
+
+				if z, err = stage.Eval(in, outs); err != nil {
+					t.Fatal(err)
+				}
+				outs[i] = z
+			}
+		}
+
+		for _, want := range []struct {
+			largest bool
+			number  string
+		}{{true, largest}, {false, smallest}} {
+			got, err := ModelNumber(parsed, want.largest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want.number {
+				t.Errorf("ModelNumber(largest=%v) is %s, want %s", want.largest, got, want.number)
+			}
+		}
+
+		// Every digit is tied to another, so changing any one of them
+		// makes the number invalid
+		for d := range largest {
+			if largest[d] == '9' {
+				continue
+			}
+
+			bigger := []byte(largest)
+			bigger[d]++
+			if ok, err := Check(digits, string(bigger)); err != nil || ok {
+				t.Fatalf("%s shouldn't be valid (%v)", bigger, err)
+			}
+		}
+	}
+}
+
+func containsInt(s []int, v int) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func TestModelNumberErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		program string
+	}{
+		// z is always 1 at the end
+		{"none valid", "inp w\nadd z 1"},
+		// z is 0 for w == 10, which isn't a digit
+		{"no digits", "inp w\nadd z w\nadd z -10"},
+		// Digit 1's stage uses digit 0, via x
+		{"carries x", "inp w\nadd x w\ninp w\nadd z x"},
+	} {
+		program, err := Parse(strings.NewReader(tc.program))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if n, err := ModelNumber(program, true); err == nil {
+			t.Errorf("%s: got %s, want an error", tc.name, n)
+		}
+	}
+}
+
+func TestModelNumberFailingDigits(t *testing.T) {
+	// z = (z + w - 5) % 5 for each digit. Digits which take z negative
+	// make the ALU fail on the mod, which mustn't stop the search.
+	program, err := Parse(strings.NewReader(strings.Repeat("inp w\nadd z w\nadd z -5\nmod z 5\n", 2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []struct {
+		largest bool
+		number  string
+	}{{true, "96"}, {false, "55"}} {
+		if n, err := ModelNumber(program, want.largest); err != nil || n != want.number {
+			t.Errorf("ModelNumber(largest=%v) is %s (%v), want %s", want.largest, n, err, want.number)
+		}
+	}
+}
@@ -1,37 +1,58 @@
-package main
+package day24
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"runtime/pprof"
+	"io"
+	"strconv"
 	"strings"
+
+	"github.com/usedbytes/aoc2021/input"
 )
 
-func doLines(filename string, do func(line string) error) error {
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
+type ALUState struct {
+	X, Y, Z, W int
+}
+
+var operands = map[string]int{
+	"inp": 1,
+	"add": 2,
+	"mul": 2,
+	"div": 2,
+	"mod": 2,
+	"eql": 2,
+}
+
+func isRegister(name string) bool {
+	return name == "w" || name == "x" || name == "y" || name == "z"
+}
+
+// Splits 'insn' into its opcode and operands, checking that they make sense.
+// Errors have the column of the bad part.
+func decode(insn string) ([]string, error) {
+	parts := strings.Split(insn, " ")
+
+	n, ok := operands[parts[0]]
+	if !ok {
+		return nil, input.At(1, fmt.Errorf("unknown instruction %q", parts[0]))
 	}
-	defer f.Close()
 
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if err := do(line); err != nil {
-			return err
-		}
+	if len(parts) != n+1 {
+		return nil, input.At(1, fmt.Errorf("%s takes %d operands, got %d", parts[0], n, len(parts)-1))
 	}
 
-	if err := scanner.Err(); err != nil {
-		return err
+	col := len(parts[0]) + 2
+	if !isRegister(parts[1]) {
+		return nil, input.At(col, fmt.Errorf("unknown register %q", parts[1]))
 	}
+	col += len(parts[1]) + 1
 
-	return nil
-}
+	if n == 2 && !isRegister(parts[2]) {
+		if _, err := strconv.Atoi(parts[2]); err != nil {
+			return nil, input.At(col, fmt.Errorf("%q isn't a register or a number", parts[2]))
+		}
+	}
 
-type ALUState struct {
-	X, Y, Z, W int
+	return parts, nil
 }
 
 func (s *ALUState) getDestination(name string) *int {
@@ -46,7 +67,8 @@ func (s *ALUState) getDestination(name string) *int {
 		return &s.W
 	}
 
-	panic("getDestination" + name)
+	// decode() checks for this
+	return nil
 }
 
 func (s *ALUState) getSource(name string) int {
@@ -61,43 +83,43 @@ func (s *ALUState) getSource(name string) int {
 		return s.W
 	}
 
-	var val int
-	_, err := fmt.Sscanf(name, "%d", &val)
-	if err != nil {
-		panic(err)
-	}
+	// decode() checks this is a number
+	val, _ := strconv.Atoi(name)
 
 	return val
 }
 
 // Returns if input was consumed
-func (s *ALUState) Execute(insn string, input int) bool {
-	ret := false
-	parts := strings.Split(insn, " ")
-	switch parts[0] {
-	case "inp":
+func (s *ALUState) Execute(insn string, input int) (bool, error) {
+	parts, err := decode(insn)
+	if err != nil {
+		return false, err
+	}
+
+	if parts[0] == "inp" {
 		dst := s.getDestination(parts[1])
 		*dst = input
-		ret = true
+		return true, nil
+	}
+
+	a := s.getDestination(parts[1])
+	b := s.getSource(parts[2])
+	switch parts[0] {
 	case "add":
-		a := s.getDestination(parts[1])
-		b := s.getSource(parts[2])
 		*a = *a + b
 	case "mul":
-		a := s.getDestination(parts[1])
-		b := s.getSource(parts[2])
 		*a = *a * b
 	case "div":
-		a := s.getDestination(parts[1])
-		b := s.getSource(parts[2])
+		if b == 0 {
+			return false, fmt.Errorf("%s: divide by zero", insn)
+		}
 		*a = *a / b
 	case "mod":
-		a := s.getDestination(parts[1])
-		b := s.getSource(parts[2])
+		if *a < 0 || b <= 0 {
+			return false, fmt.Errorf("%s: invalid mod %d %% %d", insn, *a, b)
+		}
 		*a = *a % b
 	case "eql":
-		a := s.getDestination(parts[1])
-		b := s.getSource(parts[2])
 		if *a == b {
 			*a = 1
 		} else {
@@ -105,7 +127,7 @@ func (s *ALUState) Execute(insn string, input int) bool {
 		}
 	}
 
-	return ret
+	return false, nil
 }
 
 type Operator string
@@ -137,6 +159,54 @@ func (e Expression) String() string {
 	}
 }
 
+// Eval evaluates the expression with the digits 'in' and the previous stages'
+// results 'outs'. It's an error to divide by zero, or take an invalid mod,
+// just like on the ALU.
+func (e *Expression) Eval(in, outs []int) (int, error) {
+	switch e.Op {
+	case OpLiteral:
+		return e.Val, nil
+	case OpVar:
+		return in[e.Val], nil
+	case OpRes:
+		return outs[e.Val], nil
+	}
+
+	a, err := e.A.Eval(in, outs)
+	if err != nil {
+		return 0, err
+	}
+
+	b, err := e.B.Eval(in, outs)
+	if err != nil {
+		return 0, err
+	}
+
+	switch e.Op {
+	case OpAdd:
+		return a + b, nil
+	case OpMul:
+		return a * b, nil
+	case OpDiv:
+		if b == 0 {
+			return 0, fmt.Errorf("divide by zero")
+		}
+		return a / b, nil
+	case OpMod:
+		if a < 0 || b <= 0 {
+			return 0, fmt.Errorf("invalid mod %d %% %d", a, b)
+		}
+		return a % b, nil
+	case OpEquals:
+		if a == b {
+			return 1, nil
+		}
+		return 0, nil
+	}
+
+	return 0, fmt.Errorf("unknown operator %q", e.Op)
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -153,9 +223,10 @@ func max(a, b int) int {
 
 // An ugly ugly set of hand-crafted optimisation based on the patterns in the
 // input.
-// We track a "Min" and "Max" for each expression, so that we can eliminate
-// 'eql' expressions which will always evaluate to 0.
-func (e *Expression) Simplify() {
+// We track a "Min" and "Max" for each expression (see interval.go), so that we
+// can eliminate 'eql' expressions which always have the same result.
+// Folding two literals fails in the same cases as the ALU does.
+func (e *Expression) Simplify() error {
 	if e.A.Op == OpLiteral && e.B.Op == OpLiteral {
 		switch e.Op {
 		case OpAdd:
@@ -163,8 +234,14 @@ func (e *Expression) Simplify() {
 		case OpMul:
 			e.Val = e.A.Val * e.B.Val
 		case OpDiv:
+			if e.B.Val == 0 {
+				return fmt.Errorf("divide by zero")
+			}
 			e.Val = e.A.Val / e.B.Val
 		case OpMod:
+			if e.A.Val < 0 || e.B.Val <= 0 {
+				return fmt.Errorf("invalid mod %d %% %d", e.A.Val, e.B.Val)
+			}
 			e.Val = e.A.Val % e.B.Val
 		case OpEquals:
 			if e.A.Val == e.B.Val {
@@ -177,24 +254,13 @@ func (e *Expression) Simplify() {
 		e.B = nil
 		e.Op = OpLiteral
 		e.Min, e.Max = e.Val, e.Val
-	} else if e.Op == OpEquals && min(e.A.Max, e.B.Max) < max(e.A.Min, e.B.Min) {
-		e.Val = 0
+	} else if v, ok := forward(e.Op, e.A.interval(), e.B.interval()).Point(); ok && e.Op == OpEquals {
+		// The ranges prove what it is
+		e.Val = v
 		e.A = nil
 		e.B = nil
 		e.Op = OpLiteral
-		e.Min, e.Max = 0, 0
-	} else if e.Op == OpEquals && e.A.Op == OpVar && e.B.Op == OpLiteral && (e.B.Val > 9 || e.B.Val < 1) {
-		e.Val = 0
-		e.A = nil
-		e.B = nil
-		e.Op = OpLiteral
-		e.Min, e.Max = 0, 0
-	} else if e.Op == OpEquals && e.A.Op == OpLiteral && e.B.Op == OpVar && (e.A.Val > 9 || e.A.Val < 1) {
-		e.Val = 0
-		e.A = nil
-		e.B = nil
-		e.Op = OpLiteral
-		e.Min, e.Max = 0, 0
+		e.Min, e.Max = v, v
 	} else if e.Op == OpMul && e.B.Op == OpLiteral && e.B.Val == 0 {
 		e.Val = 0
 		e.A = nil
@@ -233,43 +299,30 @@ func (e *Expression) Simplify() {
 		e.Min, e.Max = e.A.Min, e.A.Max
 		e.A, e.B = e.A.A, e.A.B
 	} else {
-		switch e.Op {
-		case OpVar:
-			e.Min = 1
-			e.Max = 9
-		case OpAdd:
-			e.Min = e.A.Min + e.B.Min
-			e.Max = e.A.Max + e.B.Max
-		case OpMul:
-			e.Min = e.A.Min * e.B.Min
-			e.Max = e.A.Max * e.B.Max
-		case OpDiv:
-			e.Min = e.A.Min / max(1, e.B.Max)
-			e.Max = e.A.Max / max(1, e.B.Min)
-		case OpMod:
-			e.Min = 0
-			e.Max = e.B.Val
-			if e.B.Op != OpLiteral {
-				panic("can't determine range for non-literal mod")
-			}
-		case OpEquals:
-			e.Min = 0
-			e.Max = 1
-		}
+		r := forward(e.Op, e.A.interval(), e.B.interval())
+		e.Min, e.Max = r.Lo, r.Hi
 	}
+
+	return nil
 }
 
 type SymbolicALUState struct {
 	W, X, Y, Z *Expression
 	InpCount   int
+	// Every expression is interned in DAG, so nothing is built twice
+	DAG *DAG
 }
 
 func NewSymbolicAlu() *SymbolicALUState {
+	dag := NewDAG()
+	zero := dag.Intern(&Expression{})
+
 	return &SymbolicALUState{
-		W: &Expression{},
-		X: &Expression{},
-		Y: &Expression{},
-		Z: &Expression{},
+		W: zero,
+		X: zero,
+		Y: zero,
+		Z: zero,
+		DAG: dag,
 	}
 }
 
@@ -290,7 +343,8 @@ func (s *SymbolicALUState) getDestination(name string) **Expression {
 		return &s.W
 	}
 
-	panic("getDestination" + name)
+	// decode() checks for this
+	return nil
 }
 
 func (s *SymbolicALUState) getSource(name string) *Expression {
@@ -305,26 +359,25 @@ func (s *SymbolicALUState) getSource(name string) *Expression {
 		return s.W
 	}
 
-	var val int
-	_, err := fmt.Sscanf(name, "%d", &val)
-	if err != nil {
-		panic(err)
-	}
+	// decode() checks this is a number
+	val, _ := strconv.Atoi(name)
 
-	return &Expression{
+	return s.DAG.Intern(&Expression{
 		Val: val,
 		Min: val,
 		Max: val,
-	}
+	})
 }
 
 // Returns if input was consumed
-func (s *SymbolicALUState) Execute(insn string, input int) bool {
-	ret := false
-	parts := strings.Split(insn, " ")
-	switch parts[0] {
-	case "inp":
-		dst := s.getDestination(parts[1])
+func (s *SymbolicALUState) Execute(insn string, input int) (bool, error) {
+	parts, err := decode(insn)
+	if err != nil {
+		return false, err
+	}
+
+	dst := s.getDestination(parts[1])
+	if parts[0] == "inp" {
 		expr := &Expression{
 			Op: OpVar,
 			Val: s.InpCount,
@@ -332,84 +385,74 @@ func (s *SymbolicALUState) Execute(insn string, input int) bool {
 			Max: 9,
 		}
 		s.InpCount++
-		*dst = expr
-		ret = true
+		*dst = s.DAG.Intern(expr)
+		return true, nil
+	}
+
+	b := s.getSource(parts[2])
+	expr := &Expression{
+		A: *dst,
+		B: b,
+	}
+
+	switch parts[0] {
 	case "add":
-		a := s.getDestination(parts[1])
-		b := s.getSource(parts[2])
-		expr := &Expression{
-			A: *a,
-			B: b,
-			Op: OpAdd,
-		}
-		expr.Simplify()
-		*a = expr
+		expr.Op = OpAdd
 	case "mul":
-		a := s.getDestination(parts[1])
-		b := s.getSource(parts[2])
-		expr := &Expression{
-			A: *a,
-			B: b,
-			Op: OpMul,
-		}
-		expr.Simplify()
-		*a = expr
+		expr.Op = OpMul
 	case "div":
-		a := s.getDestination(parts[1])
-		b := s.getSource(parts[2])
-		expr := &Expression{
-			A: *a,
-			B: b,
-			Op: OpDiv,
-		}
-		expr.Simplify()
-		*a = expr
+		expr.Op = OpDiv
 	case "mod":
-		a := s.getDestination(parts[1])
-		b := s.getSource(parts[2])
-		expr := &Expression{
-			A: *a,
-			B: b,
-			Op: OpMod,
-		}
-		expr.Simplify()
-		*a = expr
+		expr.Op = OpMod
 	case "eql":
-		a := s.getDestination(parts[1])
-		b := s.getSource(parts[2])
-		expr := &Expression{
-			A: *a,
-			B: b,
-			Op: OpEquals,
-		}
-		expr.Simplify()
-		*a = expr
+		expr.Op = OpEquals
+	}
+
+	if expr.Op == OpDiv && b.Op == OpLiteral && b.Val == 0 {
+		return false, fmt.Errorf("%s: divide by zero", insn)
+	} else if expr.Op == OpMod && b.Op == OpLiteral && b.Val <= 0 {
+		return false, fmt.Errorf("%s: invalid mod by %d", insn, b.Val)
 	}
 
-	return ret
+	// Simplify might turn it into one of its operands, or something
+	// which already exists
+	if err := expr.Simplify(); err != nil {
+		return false, fmt.Errorf("%s: %w", insn, err)
+	}
+	*dst = s.DAG.Intern(expr)
+
+	return false, nil
 }
 
 type ALU interface {
-	Execute(string, int) bool
+	Execute(string, int) (bool, error)
 }
 
-func RunProgram(alu ALU, program []string, input []int) {
+// RunProgram runs 'program' on 'alu'. It's an error if the program wants more
+// input than there is.
+func RunProgram(alu ALU, program []string, input []int) error {
 	i := 0
-	for _, insn := range program {
+	for n, insn := range program {
 		var v int
 		if i < len(input) {
 			v = input[i]
 		}
 
-		consumed := alu.Execute(insn, v)
+		consumed, err := alu.Execute(insn, v)
+		if err != nil {
+			return fmt.Errorf("instruction %d: %w", n+1, err)
+		}
+
 		if consumed {
 			if i >= len(input) {
-				panic("input underflow")
+				return fmt.Errorf("instruction %d: input underflow", n+1)
 			}
 
 			i++
 		}
 	}
+
+	return nil
 }
 
 func parseInput(in string) []int {
@@ -421,17 +464,25 @@ func parseInput(in string) []int {
 	return out
 }
 
-func run() error {
+func Parse(rd io.Reader) ([]string, error) {
+	var program []string
 
-	program := []string{}
+	if err := input.DoLines(rd, func(line string) error {
+		if _, err := decode(line); err != nil {
+			return err
+		}
 
-	if err := doLines(os.Args[1], func(line string) error {
 		program = append(program, line)
 		return nil
 	}); err != nil {
-		return err
+		return nil, err
 	}
 
+	return program, nil
+}
+
+// SplitDigits splits the program into chunks, each starting with an 'inp'
+func SplitDigits(program []string) [][]string {
 	digits := [][]string{}
 	digit := []string{}
 	for _, i := range program {
@@ -445,64 +496,73 @@ func run() error {
 	}
 	digits = append(digits, digit)
 
+	return digits
+}
+
+// SymbolicStages evaluates each digit's chunk symbolically, returning the
+// expression for Z at the end of each one
+func SymbolicStages(digits [][]string) ([]*Expression, error) {
+	var stages []*Expression
+
 	// 'in' actually doesn't matter for the symbolic evaluation,
 	// but I need it to keep the interface happy.
-	in := []int{ 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1 }
+	in := make([]int, len(digits))
 	salu := NewSymbolicAlu()
 	for i, p := range digits[:] {
-		RunProgram(salu, p, in[i:])
+		if err := RunProgram(salu, p, in[i:]); err != nil {
+			return nil, fmt.Errorf("digit %d: %w", i, err)
+		}
 
 		// It's quite easy to find/prove that only 'Z' is important
 		// between stages.
-		// To keep the output expressions manageable, we replace each
-		// stage's output with a special "output" expression
-		// Now, just simplify the "outX" expressions by hand 😅
-		fmt.Printf("out%d: %s\n", i, salu.Z)
-		salu.Z = &Expression{
+		// The DAG keeps even the whole program's expression small, but
+		// so that it can be searched one digit at a time, we replace
+		// each stage's output with a special "output" expression.
+		// It keeps the range of Z, so that later stages can still prove
+		// things with it.
+		stages = append(stages, salu.Z)
+		salu.Z = salu.DAG.Intern(&Expression{
 			Op: OpRes,
 			Val: i,
-		}
+			Min: salu.Z.Min,
+			Max: salu.Z.Max,
+		})
 	}
 
-	// Maximum for my input
-	inputVals := []int{ 9, 5, 2, 9, 9, 8, 9, 7, 9, 9, 9, 8, 9, 7 }
-	input := "95299897999897"
-	in = parseInput(input)
-	var alu ALUState
-	for i := range inputVals {
-		RunProgram(&alu, digits[i], in[i:i+1])
-	}
-	fmt.Println("Part 1:", input, "->", alu.Z == 0)
-
-	// Minimum for my input
-	inputVals = []int{ 3, 1, 1, 1, 1, 1, 2, 1, 3, 8, 2, 1, 5, 1 }
-	input = "31111121382151"
-	in = parseInput(input)
-	var alu2 ALUState
-	for i := range inputVals {
-		RunProgram(&alu2, digits[i], in[i:i+1])
-	}
-	fmt.Println("Part 2:", input, "->", alu2.Z == 0)
-
-	return nil
+	return stages, nil
 }
 
-func main() {
-	profileEnv := os.Getenv("PROFILE")
-	if profileEnv != "" {
-		f, err := os.Create(profileEnv)
-		if err != nil {
-			fmt.Println("ERROR:", err)
-			os.Exit(1)
-		}
+// Check returns true if 'input' is a valid model number
+func Check(digits [][]string, input string) (bool, error) {
+	in := parseInput(input)
+	if len(in) != len(digits) {
+		return false, fmt.Errorf("%s should have %d digits", input, len(digits))
+	}
 
-		pprof.StartCPUProfile(f)
-		defer pprof.StopCPUProfile()
+	var program []string
+	for _, d := range digits {
+		program = append(program, d...)
 	}
 
-	err := run()
+	p, err := Assemble(program)
 	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
+		return false, err
 	}
+
+	var alu ALUState
+	if err := p.Run(&alu, in); err != nil {
+		return false, err
+	}
+
+	return alu.Z == 0, nil
+}
+
+// Part1 finds the largest model number
+func Part1(program []string) (string, error) {
+	return ModelNumber(program, true)
+}
+
+// Part2 finds the smallest model number
+func Part2(program []string) (string, error) {
+	return ModelNumber(program, false)
 }
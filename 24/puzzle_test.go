@@ -0,0 +1,168 @@
+package day24
+
+import (
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/usedbytes/aoc2021/golden"
+)
+
+func TestGolden(t *testing.T) {
+	golden.Test(t, 24)
+}
+
+func TestParseErrors(t *testing.T) {
+	golden.ParseErrors(t, 24, []golden.BadInput{
+		{Name: "unknown instruction", Input: "inp w\nfoo x 1\n", Line: 2, Column: 1},
+		{Name: "unknown register", Input: "inp w\nadd q 1\n", Line: 2, Column: 5},
+		{Name: "bad source", Input: "inp w\nadd x q\n", Line: 2, Column: 7},
+		{Name: "missing operand", Input: "add x\n", Line: 1, Column: 1},
+	})
+}
+
+// evaluator evaluates expressions with the digits 'in', and the previous
+// stages' results 'outs'. If 'ranges' is set, it also checks every
+// sub-expression is within its Min..Max.
+type evaluator struct {
+	t        *testing.T
+	in, outs []int
+	ranges   bool
+
+	// Expressions are DAGs, which can be exponentially big as trees
+	memo map[*Expression]int
+}
+
+func newEvaluator(t *testing.T, in, outs []int, ranges bool) *evaluator {
+	return &evaluator{
+		t:      t,
+		in:     in,
+		outs:   outs,
+		ranges: ranges,
+		memo:   make(map[*Expression]int),
+	}
+}
+
+func (ev *evaluator) eval(e *Expression) int {
+	if v, ok := ev.memo[e]; ok {
+		return v
+	}
+
+	var v int
+	switch e.Op {
+	case OpLiteral:
+		v = e.Val
+	case OpVar:
+		v = ev.in[e.Val]
+	case OpRes:
+		// Stage results don't have a range
+		return ev.outs[e.Val]
+	default:
+		a := ev.eval(e.A)
+		b := ev.eval(e.B)
+
+		switch e.Op {
+		case OpAdd:
+			v = a + b
+		case OpMul:
+			v = a * b
+		case OpDiv:
+			if b == 0 {
+				ev.t.Fatalf("%s divides by zero", e.Op)
+			}
+			v = a / b
+		case OpMod:
+			if b == 0 {
+				ev.t.Fatalf("%s divides by zero", e.Op)
+			}
+			v = a % b
+		case OpEquals:
+			if a == b {
+				v = 1
+			}
+		}
+	}
+
+	if ev.ranges && (v < e.Min || v > e.Max) {
+		ev.t.Fatalf("%d %s %d = %d, outside of %d..%d", ev.eval(e.A), e.Op, ev.eval(e.B), v, e.Min, e.Max)
+	}
+
+	ev.memo[e] = v
+	return v
+}
+
+func TestSymbolicStages(t *testing.T) {
+	f, err := os.Open("input.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	program, err := Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	digits := SplitDigits(program)
+	stages, err := SymbolicStages(digits)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rnd := rand.New(rand.NewSource(24))
+	for i := 0; i < 200; i++ {
+		in := make([]int, len(digits))
+		outs := make([]int, len(digits))
+
+		var alu ALUState
+		for d := range digits {
+			in[d] = 1 + rnd.Intn(9)
+			if err := RunProgram(&alu, digits[d], in[d:d+1]); err != nil {
+				t.Fatal(err)
+			}
+
+			outs[d] = newEvaluator(t, in, outs, false).eval(stages[d])
+			if outs[d] != alu.Z {
+				t.Fatalf("%v: stage %d gave %d, want %d", in, d, outs[d], alu.Z)
+			}
+		}
+	}
+}
+
+func TestGenerated(t *testing.T) {
+	golden.Generated(t, 24, 0, 5)
+}
+
+func BenchmarkInput(b *testing.B) {
+	golden.Benchmark(b, 24)
+}
+
+// Programs which crash the ALU should crash the symbolic ALU too, even when
+// Simplify can fold them away
+func TestSymbolicErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		program []string
+	}{
+		{"divide by zero", []string{"inp x", "div x 0"}},
+		{"divide by zero register", []string{"inp x", "div x y"}},
+		{"mod by zero", []string{"inp x", "mod x 0"}},
+		{"mod by negative", []string{"inp x", "mod x -2"}},
+		{"mod negative literal", []string{"add x -3", "mod x 2"}},
+		{"mod negative literal by negative", []string{"add x -3", "mod x -2"}},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var alu ALUState
+			if err := RunProgram(&alu, tc.program, []int{5}); err == nil {
+				t.Fatalf("%q: the ALU didn't fail", tc.program)
+			}
+
+			if err := RunProgram(NewSymbolicAlu(), tc.program, []int{5}); err == nil {
+				t.Errorf("%q: the ALU failed, but the symbolic ALU didn't", tc.program)
+			}
+		})
+	}
+}
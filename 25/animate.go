@@ -0,0 +1,33 @@
+package day25
+
+import (
+	"fmt"
+
+	"github.com/usedbytes/aoc2021/grid"
+	"github.com/usedbytes/aoc2021/solver"
+)
+
+// Animate shows the herds moving, a step at a time, until they stop
+func Animate(scr solver.Screen, bed *Bed) error {
+	if err := scr.Frame("Step 0\n\n" + grid.FormatBytes(bed)); err != nil {
+		return err
+	}
+
+	for step := 1; ; step++ {
+		var moved bool
+		bed, moved = Step(bed)
+
+		status := fmt.Sprintf("Step %d", step)
+		if !moved {
+			status = fmt.Sprintf("Step %d: nothing moved", step)
+		}
+
+		if err := scr.Frame(status + "\n\n" + grid.FormatBytes(bed)); err != nil {
+			return err
+		}
+
+		if !moved {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	day25 "github.com/usedbytes/aoc2021/25"
+	"github.com/usedbytes/aoc2021/input"
+)
+
+func run() error {
+	f, err := input.Open(os.Args[1])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	bed, err := day25.Parse(f)
+	if err != nil {
+		return err
+	}
+
+	// There's no Part 2 on the last day
+	part1, err := day25.Part1(bed)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Part 1:", part1)
+
+	return nil
+}
+
+func main() {
+	err := run()
+	if err != nil {
+		fmt.Println("ERROR:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,52 @@
+package day25
+
+import (
+	"image/color"
+	"io"
+	"time"
+
+	"github.com/usedbytes/aoc2021/visual"
+)
+
+// Draw writes an animated GIF of the herds moving, until they stop. Long
+// runs skip steps, to keep it to 200 frames or so.
+func Draw(w io.Writer, bed *Bed, scale int) error {
+	if scale <= 0 {
+		scale = 3
+	}
+
+	palette := color.Palette{
+		color.RGBA{0x00, 0x20, 0x40, 0xff},
+		color.RGBA{0xff, 0x80, 0x20, 0xff},
+		color.RGBA{0x40, 0xe0, 0x60, 0xff},
+	}
+
+	colour := func(v byte) uint8 {
+		switch v {
+		case '>':
+			return 1
+		case 'v':
+			return 2
+		default:
+			return 0
+		}
+	}
+
+	beds := []*Bed{bed}
+	for moved := true; moved; {
+		bed, moved = Step(bed)
+		beds = append(beds, bed)
+	}
+
+	var anim visual.Animation
+
+	every := visual.Every(len(beds), 200)
+	for i := 0; i < len(beds); i += every {
+		anim.Add(visual.Grid(beds[i], scale, palette, colour), 50*time.Millisecond)
+	}
+
+	// Always finish on the last step, and stay there for a bit
+	anim.Add(visual.Grid(beds[len(beds)-1], scale, palette, colour), 3*time.Second)
+
+	return anim.WriteGIF(w)
+}
@@ -0,0 +1,38 @@
+package day25
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+
+	"github.com/usedbytes/aoc2021/grid"
+)
+
+// Generate writes a 'size' x 'size' sea floor (137 by default), about a third
+// of which is each herd, and where the sea cucumbers stop moving within
+// 10 * 'size' steps
+func Generate(w io.Writer, rnd *rand.Rand, size int) error {
+	if size <= 0 {
+		size = 137
+	}
+
+	for tries := 0; tries < 100; tries++ {
+		bed := grid.NewDense(size, size, byte('.'))
+		bed.Mode = grid.Wrap
+		bed.Each(func(p Point, _ byte) {
+			bed.Set(p, ".>v"[rnd.Intn(3)])
+		})
+
+		sim, moved := bed, true
+		for step := 0; moved && step < 10*size; step++ {
+			sim, moved = Step(sim)
+		}
+
+		if !moved {
+			_, err := fmt.Fprintln(w, grid.FormatBytes(bed))
+			return err
+		}
+	}
+
+	return fmt.Errorf("couldn't find a %dx%d sea floor which stops moving", size, size)
+}
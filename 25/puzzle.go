@@ -1,155 +1,74 @@
-package main
+package day25
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"runtime/pprof"
-)
-
-func doLines(filename string, do func(line string) error) error {
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
+	"io"
 
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if err := do(line); err != nil {
-			return err
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return err
-	}
-
-	return nil
-}
+	"github.com/usedbytes/aoc2021/grid"
+)
 
-func moveEast(bed, newBed map[[2]int]byte, coord, size [2]int) bool {
-	v, ok := bed[coord]
-	if ok && v != '>' {
-		newBed[coord] = v
-		return false
-	}
+type Point = grid.Point
 
-	newPos := [2]int{(coord[0]+1) % size[0], coord[1]}
+// Bed is the sea floor, which wraps around at the edges. Empty spaces are
+// '.', and the sea cucumbers are '>' and 'v'.
+type Bed = grid.Grid[byte]
 
-	//fmt.Println("Move from", coord, "to", newPos, string(v), string(bed[newPos]))
+// Moves every sea cucumber in 'herd' which can move in direction 'dir'
+func moveHerd(bed *Bed, herd byte, dir Point) (*Bed, bool) {
+	newBed := bed.Clone()
+	moved := false
 
-	if _, occupied := bed[newPos]; !occupied {
-		newBed[newPos] = v
-		return true
-	}
+	bed.Each(func(coord Point, v byte) {
+		if v != herd {
+			return
+		}
 
-	newBed[coord] = v
+		newPos := coord.Add(dir)
+		if bed.Get(newPos) == '.' {
+			newBed.Set(coord, '.')
+			newBed.Set(newPos, v)
+			moved = true
+		}
+	})
 
-	return false
+	return newBed, moved
 }
 
-func moveSouth(bed, newBed map[[2]int]byte, coord, size [2]int) bool {
-	v, ok := bed[coord]
-	if ok && v != 'v' {
-		newBed[coord] = v
-		return false
-	}
-
-	newPos := [2]int{coord[0], (coord[1]+1) % size[1]}
+// Step moves the east-facing herd and then the south-facing one, and returns
+// the new bed, and whether anything moved
+func Step(bed *Bed) (*Bed, bool) {
+	bed, movedEast := moveHerd(bed, '>', Point{X: 1, Y: 0})
+	bed, movedSouth := moveHerd(bed, 'v', Point{X: 0, Y: 1})
 
-	if _, occupied := bed[newPos]; !occupied {
-		newBed[newPos] = v
-		return true
-	}
-
-	newBed[coord] = v
-
-	return false
+	return bed, movedEast || movedSouth
 }
 
-func printBed(bed map[[2]int]byte, size [2]int) {
-	for y := 0; y < size[1]; y++ {
-		line := ""
-		for x := 0; x < size[0]; x++ {
-			if v, ok := bed[[2]int{x, y}]; ok {
-				line += string(v)
-			} else {
-				line += "."
-			}
-		}
-		fmt.Println(line)
+func parseCell(c byte) (byte, error) {
+	switch c {
+	case '.', '>', 'v':
+		return c, nil
+	default:
+		return 0, fmt.Errorf("unexpected %q", c)
 	}
 }
 
-func run() error {
-	bed := make(map[[2]int]byte)
-
-	var size [2]int
-	y := 0
-	if err := doLines(os.Args[1], func(line string) error {
-		for x, c := range line {
-			if c == '.' {
-				continue
-			}
-
-			bed[[2]int{x, y}] = byte(c)
-		}
-
-		size[0] = len(line)
-
-		y++
-		return nil
-	}); err != nil {
-		return err
+func Parse(rd io.Reader) (*Bed, error) {
+	bed, err := grid.Parse(rd, parseCell)
+	if err != nil {
+		return nil, err
 	}
+	bed.Mode = grid.Wrap
 
-	size[1] = y
+	return bed, nil
+}
 
+// Part1 counts the steps until the sea cucumbers stop moving
+func Part1(bed *Bed) (int, error) {
 	step := 0
 	canMove := true
 	for step = 0 ; canMove; step++ {
-		newBed := make(map[[2]int]byte)
-		canMove = false
-
-		for k, _ := range bed {
-			move := moveEast(bed, newBed, k, size)
-			canMove = move || canMove
-		}
-
-		bed = newBed
-		newBed = make(map[[2]int]byte)
-
-		for k, _ := range bed {
-			move := moveSouth(bed, newBed, k, size)
-			canMove = move || canMove
-		}
-
-		bed = newBed
-	}
-
-	fmt.Println("Part 1:", step)
-
-	return nil
-}
-
-func main() {
-	profileEnv := os.Getenv("PROFILE")
-	if profileEnv != "" {
-		f, err := os.Create(profileEnv)
-		if err != nil {
-			fmt.Println("ERROR:", err)
-			os.Exit(1)
-		}
-
-		pprof.StartCPUProfile(f)
-		defer pprof.StopCPUProfile()
+		bed, canMove = Step(bed)
 	}
 
-	err := run()
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
+	return step, nil
 }
@@ -0,0 +1,71 @@
+package day25
+
+import (
+	"fmt"
+	"image"
+	"strings"
+	"testing"
+
+	"github.com/usedbytes/aoc2021/golden"
+)
+
+func TestGolden(t *testing.T) {
+	golden.Test(t, 25)
+}
+
+func TestGenerated(t *testing.T) {
+	golden.Generated(t, 25, 30, 5)
+}
+
+func TestDraw(t *testing.T) {
+	// The first frame is the input, with east-facing cucumbers in colour
+	// 1 and south-facing in colour 2. None of them ever disappear.
+	golden.Drawn(t, 25, func(t *testing.T, c golden.Case, frames []*image.Paletted) {
+		bed := c.In.(*Bed)
+		colours := map[byte]uint8{'.': 0, '>': 1, 'v': 2}
+
+		start, _ := bed.Bounds()
+		first := frames[0]
+		for y := 0; y < first.Rect.Dy(); y++ {
+			for x := 0; x < first.Rect.Dx(); x++ {
+				want := colours[bed.Get(Point{X: start.X + x, Y: start.Y + y})]
+				if got := first.ColorIndexAt(x, y); got != want {
+					t.Fatalf("%d,%d is colour %d, want %d", x, y, got, want)
+				}
+			}
+		}
+
+		east, south := bed.Count('>'), bed.Count('v')
+		for i, f := range frames {
+			if golden.Count(f, 1) != east || golden.Count(f, 2) != south {
+				t.Fatalf("frame %d has %d east and %d south, want %d and %d",
+					i, golden.Count(f, 1), golden.Count(f, 2), east, south)
+			}
+		}
+	})
+}
+
+func TestAnimate(t *testing.T) {
+	// It stops on part 1's step, and no cucumbers ever disappear
+	golden.Animated(t, 25, func(t *testing.T, c golden.Case, frames []string) {
+		bed := c.In.(*Bed)
+		east, south := bed.Count('>'), bed.Count('v')
+
+		for i, f := range frames {
+			_, board, _ := strings.Cut(f, "\n\n")
+			if strings.Count(board, ">") != east || strings.Count(board, "v") != south {
+				t.Fatalf("frame %d has %d east and %d south, want %d and %d",
+					i, strings.Count(board, ">"), strings.Count(board, "v"), east, south)
+			}
+		}
+
+		status, _, _ := strings.Cut(frames[len(frames)-1], "\n\n")
+		if want := fmt.Sprintf("Step %s: nothing moved", c.Answers["1"]); status != want {
+			t.Errorf("last status is %q, want %q", status, want)
+		}
+	})
+}
+
+func BenchmarkInput(b *testing.B) {
+	golden.Benchmark(b, 25)
+}
@@ -0,0 +1,10 @@
+package day25
+
+import "github.com/usedbytes/aoc2021/solver"
+
+func init() {
+	s := solver.Register[*Bed, int, int](25, Parse, Part1, nil)
+	s.Generate = Generate
+	solver.SetDrawing(s, "gif", Draw)
+	solver.SetAnimation(s, 20, Animate)
+}
@@ -0,0 +1,139 @@
+package client
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// Cache stores downloaded files on disk, so each one is only fetched once
+type Cache struct {
+	Dir string
+}
+
+// DefaultCache returns the cache for 'session' in the user's cache
+// directory. Each session gets its own directory, because everyone's inputs
+// are different.
+func DefaultCache(session string) (*Cache, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256([]byte(session))
+
+	return &Cache{
+		Dir: filepath.Join(dir, "aoc2021", fmt.Sprintf("%x", sum[:8])),
+	}, nil
+}
+
+// Path returns the path for file 'name' for 'day'
+func (c *Cache) Path(day int, name string) string {
+	return filepath.Join(c.Dir, fmt.Sprintf("%02d", day), name)
+}
+
+// Has returns true if 'name' is already cached for 'day'
+func (c *Cache) Has(day int, name string) bool {
+	_, err := os.Stat(c.Path(day, name))
+	return err == nil
+}
+
+// Get returns the contents of 'name' for 'day'
+func (c *Cache) Get(day int, name string) ([]byte, error) {
+	return os.ReadFile(c.Path(day, name))
+}
+
+// Put stores 'data' as 'name' for 'day'
+func (c *Cache) Put(day int, name string, data []byte) error {
+	path := c.Path(day, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first, so that an interrupted download
+	// doesn't look like it's cached
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+const (
+	InputFile   = "input.txt"
+	PuzzleFile  = "puzzle.html"
+	ExampleFile = "example.txt"
+)
+
+// Fetched is the result of Fetch
+type Fetched struct {
+	// Paths to the files in the cache. Example is empty if one couldn't
+	// be found on the puzzle page.
+	Input   string
+	Example string
+
+	// Downloaded lists what was actually downloaded, rather than already
+	// being in the cache
+	Downloaded []string
+}
+
+// Fetch makes sure the input and example for 'day' are in 'cache',
+// downloading anything which isn't. Files which are already cached are never
+// downloaded again.
+func Fetch(c *Client, cache *Cache, day int) (*Fetched, error) {
+	if day < 1 || day > 25 {
+		return nil, fmt.Errorf("invalid day %d", day)
+	}
+
+	f := &Fetched{}
+
+	if !cache.Has(day, InputFile) {
+		data, err := c.Input(day)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := cache.Put(day, InputFile, data); err != nil {
+			return nil, err
+		}
+		f.Downloaded = append(f.Downloaded, InputFile)
+	}
+	f.Input = cache.Path(day, InputFile)
+
+	if !cache.Has(day, PuzzleFile) {
+		data, err := c.Puzzle(day)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := cache.Put(day, PuzzleFile, data); err != nil {
+			return nil, err
+		}
+		f.Downloaded = append(f.Downloaded, PuzzleFile)
+	}
+
+	if !cache.Has(day, ExampleFile) {
+		page, err := cache.Get(day, PuzzleFile)
+		if err != nil {
+			return nil, err
+		}
+
+		if example, ok := Example(page); ok {
+			if err := cache.Put(day, ExampleFile, example); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if cache.Has(day, ExampleFile) {
+		f.Example = cache.Path(day, ExampleFile)
+	}
+
+	return f, nil
+}
+
+// HistoryPath returns the path of the submission history in the cache
+func (c *Cache) HistoryPath() string {
+	return filepath.Join(c.Dir, HistoryFile)
+}
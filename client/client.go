@@ -0,0 +1,219 @@
+// Package client talks to the Advent of Code website, to download puzzle
+// inputs (and cache them, so that each one is only downloaded once), and to
+// submit answers.
+package client
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultBaseURL = "https://adventofcode.com"
+	Year           = 2021
+
+	// Be nice to the server, it's run by one person
+	DefaultInterval = 5 * time.Second
+
+	userAgent = "github.com/usedbytes/aoc2021"
+)
+
+// ErrNoSession is returned when there's no session cookie to authenticate
+// with
+var ErrNoSession = errors.New("no session cookie, set AOC_SESSION or see LoadSession")
+
+// Doer sends HTTP requests. It's satisfied by *http.Client, and can be
+// replaced for testing.
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Client downloads puzzle inputs for a user, identified by the session
+// cookie from their browser.
+type Client struct {
+	BaseURL string
+	Session string
+	// Contact is added to the User-Agent, so that the website's owner can
+	// get in touch if something goes wrong. It's optional.
+	Contact string
+	HTTP    Doer
+	Limiter *Limiter
+}
+
+// New returns a Client for 'session' using the real website, with the
+// contact from LoadContact
+func New(session string) *Client {
+	return &Client{
+		BaseURL: DefaultBaseURL,
+		Session: session,
+		Contact: LoadContact(),
+		HTTP:    &http.Client{Timeout: 30 * time.Second},
+		Limiter: NewLimiter(DefaultInterval),
+	}
+}
+
+func (c *Client) userAgent() string {
+	if c.Contact == "" {
+		return userAgent
+	}
+
+	return userAgent + " by " + c.Contact
+}
+
+// StatusError is returned for any response other than 200 OK
+type StatusError struct {
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	msg := fmt.Sprintf("%s: %d %s", e.URL, e.StatusCode, http.StatusText(e.StatusCode))
+	if e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusInternalServerError {
+		// This is what you get for an invalid or expired cookie
+		msg += " (is the session cookie valid?)"
+	}
+
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+
+	return msg
+}
+
+func (c *Client) url(day int, path string) string {
+	return fmt.Sprintf("%s/%d/day/%d%s", strings.TrimSuffix(c.BaseURL, "/"), Year, day, path)
+}
+
+// Do sends 'req' with the session cookie, waiting for the rate limit first.
+// Non-200 responses are returned as a *StatusError.
+func (c *Client) Do(req *http.Request) ([]byte, error) {
+	if c.Session == "" {
+		return nil, ErrNoSession
+	}
+
+	if c.Limiter != nil {
+		c.Limiter.Wait()
+	}
+
+	req.Header.Set("User-Agent", c.userAgent())
+	req.AddCookie(&http.Cookie{Name: "session", Value: c.Session})
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		// Only the first line, errors are sometimes a whole page
+		first, _, _ := strings.Cut(strings.TrimSpace(string(body)), "\n")
+		return nil, &StatusError{
+			URL:        req.URL.String(),
+			StatusCode: resp.StatusCode,
+			Body:       first,
+		}
+	}
+
+	return body, nil
+}
+
+func (c *Client) get(day int, path string) ([]byte, error) {
+	req, err := http.NewRequest(http.MethodGet, c.url(day, path), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.Do(req)
+}
+
+// Input downloads the puzzle input for 'day'
+func (c *Client) Input(day int) ([]byte, error) {
+	return c.get(day, "/input")
+}
+
+// Puzzle downloads the puzzle description page (HTML) for 'day'
+func (c *Client) Puzzle(day int) ([]byte, error) {
+	return c.get(day, "")
+}
+
+var preRegexp = regexp.MustCompile(`(?s)<pre><code>(.*?)</code></pre>`)
+var tagRegexp = regexp.MustCompile(`<[^>]*>`)
+
+// Example extracts the first example input from a puzzle page. The example
+// is almost always the first <pre><code> block.
+func Example(page []byte) ([]byte, bool) {
+	m := preRegexp.FindSubmatch(page)
+	if m == nil {
+		return nil, false
+	}
+
+	// Strip any highlighting (<em>) and unescape
+	text := tagRegexp.ReplaceAll(m[1], nil)
+	text = []byte(html.UnescapeString(string(text)))
+
+	if !bytes.HasSuffix(text, []byte("\n")) {
+		text = append(text, '\n')
+	}
+
+	return text, true
+}
+
+// Returns the contents of 'name' in the user's config directory
+// (~/.config/aoc2021 on Linux), or os.ErrNotExist if there isn't a config
+// directory
+func readConfig(name string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", os.ErrNotExist
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "aoc2021", name))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(data)), nil
+}
+
+// LoadSession returns the session cookie from $AOC_SESSION, or from the
+// "session" file in the user's config directory (~/.config/aoc2021/session
+// on Linux)
+func LoadSession() (string, error) {
+	if s := os.Getenv("AOC_SESSION"); s != "" {
+		return s, nil
+	}
+
+	s, err := readConfig("session")
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrNoSession
+	}
+
+	return s, err
+}
+
+// LoadContact returns how to contact the user (an email address, say) from
+// $AOC_CONTACT, or from the "contact" file in the user's config directory.
+// It's "" if neither is set.
+func LoadContact() string {
+	if s := os.Getenv("AOC_CONTACT"); s != "" {
+		return s
+	}
+
+	s, _ := readConfig("contact")
+	return s
+}
@@ -0,0 +1,222 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSession = "53616c7465645f5f"
+
+const testPage = `<html><body><article>
+<p>For example:</p>
+<pre><code>199
+200
+&lt;<em>208</em>&gt;
+</code></pre>
+<p>Another:</p>
+<pre><code>nope</code></pre>
+</article></body></html>`
+
+// A stand-in for the website, which counts the requests it gets
+type fakeSite struct {
+	t        *testing.T
+	requests map[string]int
+}
+
+func (f *fakeSite) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.requests[r.URL.Path]++
+
+	cookie, err := r.Cookie("session")
+	if err != nil || cookie.Value != testSession {
+		http.Error(w, "Puzzle inputs differ by user.  Please log in to get your puzzle input.", http.StatusBadRequest)
+		return
+	}
+
+	if ua := r.Header.Get("User-Agent"); !strings.Contains(ua, "aoc2021") {
+		f.t.Errorf("unexpected User-Agent %q", ua)
+	}
+
+	switch r.URL.Path {
+	case "/2021/day/1/input":
+		fmt.Fprint(w, "1\n2\n3\n")
+	case "/2021/day/1":
+		fmt.Fprint(w, testPage)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newTestClient(t *testing.T, session string) (*Client, *fakeSite) {
+	site := &fakeSite{t: t, requests: make(map[string]int)}
+	srv := httptest.NewServer(site)
+	t.Cleanup(srv.Close)
+
+	c := New(session)
+	c.BaseURL = srv.URL
+	c.HTTP = srv.Client()
+	c.Limiter = nil
+
+	return c, site
+}
+
+func TestFetch(t *testing.T) {
+	c, site := newTestClient(t, testSession)
+	cache := &Cache{Dir: t.TempDir()}
+
+	f, err := Fetch(c, cache, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(f.Downloaded) != 2 {
+		t.Errorf("expected 2 downloads, got %v", f.Downloaded)
+	}
+
+	data, err := os.ReadFile(f.Input)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "1\n2\n3\n" {
+		t.Errorf("wrong input %q", data)
+	}
+
+	data, err = os.ReadFile(f.Example)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "199\n200\n<208>\n" {
+		t.Errorf("wrong example %q", data)
+	}
+
+	// Everything is cached now, so it mustn't go to the server again
+	f, err = Fetch(c, cache, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Downloaded) != 0 {
+		t.Errorf("expected no downloads, got %v", f.Downloaded)
+	}
+
+	for path, n := range site.requests {
+		if n != 1 {
+			t.Errorf("%s requested %d times", path, n)
+		}
+	}
+}
+
+func TestFetchErrors(t *testing.T) {
+	c, _ := newTestClient(t, "expired")
+	cache := &Cache{Dir: t.TempDir()}
+
+	_, err := Fetch(c, cache, 1)
+	var se *StatusError
+	if !errors.As(err, &se) {
+		t.Fatalf("expected a StatusError, got %v", err)
+	}
+	if se.StatusCode != http.StatusBadRequest || !strings.Contains(se.Error(), "session cookie") {
+		t.Errorf("unexpected error %v", se)
+	}
+
+	// Nothing should be cached after a failure
+	if cache.Has(1, InputFile) {
+		t.Error("failed download was cached")
+	}
+
+	c, _ = newTestClient(t, testSession)
+	if _, err := Fetch(c, cache, 2); err == nil {
+		t.Error("expected an error for a missing day")
+	}
+
+	if _, err := Fetch(c, cache, 26); err == nil {
+		t.Error("expected an error for day 26")
+	}
+
+	c.Session = ""
+	if _, err := Fetch(c, cache, 1); err != ErrNoSession {
+		t.Errorf("expected ErrNoSession, got %v", err)
+	}
+}
+
+type doerFunc func(req *http.Request) (*http.Response, error)
+
+func (f doerFunc) Do(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestDoer(t *testing.T) {
+	c := New(testSession)
+	c.Limiter = nil
+
+	errDown := errors.New("network is down")
+	c.HTTP = doerFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.String() != "https://adventofcode.com/2021/day/7/input" {
+			t.Errorf("unexpected URL %s", req.URL)
+		}
+		return nil, errDown
+	})
+
+	if _, err := c.Input(7); !errors.Is(err, errDown) {
+		t.Errorf("expected %v, got %v", errDown, err)
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	// Keep away from the real config
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", "")
+
+	for _, tc := range []struct {
+		contact string
+		want    string
+	}{
+		{"", "github.com/usedbytes/aoc2021"},
+		{"someone@example.com", "github.com/usedbytes/aoc2021 by someone@example.com"},
+	} {
+		t.Setenv("AOC_CONTACT", tc.contact)
+
+		c := New(testSession)
+		c.Limiter = nil
+
+		var got string
+		c.HTTP = doerFunc(func(req *http.Request) (*http.Response, error) {
+			got = req.Header.Get("User-Agent")
+			return nil, errors.New("not really sent")
+		})
+		c.Input(1)
+
+		if got != tc.want {
+			t.Errorf("with contact %q, User-Agent is %q, want %q", tc.contact, got, tc.want)
+		}
+	}
+}
+
+func TestLimiter(t *testing.T) {
+	now := time.Unix(0, 0)
+	var slept []time.Duration
+
+	l := NewLimiter(5 * time.Second)
+	l.now = func() time.Time { return now }
+	l.sleep = func(d time.Duration) {
+		slept = append(slept, d)
+		now = now.Add(d)
+	}
+
+	// The first request doesn't wait
+	l.Wait()
+
+	now = now.Add(2 * time.Second)
+	l.Wait()
+
+	now = now.Add(10 * time.Second)
+	l.Wait()
+
+	if len(slept) != 1 || slept[0] != 3*time.Second {
+		t.Errorf("expected one 3s sleep, got %v", slept)
+	}
+}
@@ -0,0 +1,171 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Attempt is one submitted answer
+type Attempt struct {
+	Day    int       `json:"day"`
+	Part   int       `json:"part"`
+	Answer string    `json:"answer"`
+	Result Result    `json:"result"`
+	Time   time.Time `json:"time"`
+	// When another answer can be submitted, if the server said to wait
+	Until time.Time `json:"until"`
+}
+
+// History is a record of every answer submitted, used to avoid submitting
+// answers which can't be right
+type History struct {
+	Path     string
+	Attempts []Attempt
+}
+
+// HistoryFile is the name of the history file in a Cache
+const HistoryFile = "history.json"
+
+// LoadHistory reads the history from 'path'. It's empty if 'path' doesn't
+// exist yet.
+func LoadHistory(path string) (*History, error) {
+	h := &History{Path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return h, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &h.Attempts); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return h, nil
+}
+
+// Save writes the history back to its file
+func (h *History) Save() error {
+	data, err := json.MarshalIndent(h.Attempts, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.Path), 0700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(h.Path, append(data, '\n'), 0600)
+}
+
+// RefusedError is returned when an answer isn't submitted, because the
+// history shows there's no point
+type RefusedError struct {
+	Reason string
+}
+
+func (e *RefusedError) Error() string {
+	return "not submitting: " + e.Reason
+}
+
+// Bounds returns the range the answer to 'part' of 'day' must be in,
+// according to the "too high" and "too low" responses so far. Either may be
+// nil if there's no bound.
+func (h *History) Bounds(day, part int) (lower, upper *big.Int) {
+	for _, a := range h.Attempts {
+		if a.Day != day || a.Part != part {
+			continue
+		}
+
+		v, ok := new(big.Int).SetString(a.Answer, 10)
+		if !ok {
+			continue
+		}
+
+		switch a.Result {
+		case TooLow:
+			if lower == nil || v.Cmp(lower) > 0 {
+				lower = v
+			}
+		case TooHigh:
+			if upper == nil || v.Cmp(upper) < 0 {
+				upper = v
+			}
+		}
+	}
+
+	return lower, upper
+}
+
+// Check returns a *RefusedError if 'answer' shouldn't be submitted: the part
+// has already been solved (a Correct is on record), 'answer' is known to be
+// wrong or out of bounds, or the server said to wait. A WrongLevel reply
+// doesn't stop another try, as it might have just been too early.
+func (h *History) Check(day, part int, answer string, now time.Time) error {
+	for _, a := range h.Attempts {
+		if a.Day != day || a.Part != part {
+			continue
+		}
+
+		switch {
+		case a.Result == Correct:
+			return &RefusedError{fmt.Sprintf("already solved with %s", a.Answer)}
+		case a.Result.IsWrong() && a.Answer == answer:
+			return &RefusedError{fmt.Sprintf("%s was already submitted, and is wrong", answer)}
+		}
+	}
+
+	if v, ok := new(big.Int).SetString(answer, 10); ok {
+		lower, upper := h.Bounds(day, part)
+		if lower != nil && v.Cmp(lower) <= 0 {
+			return &RefusedError{fmt.Sprintf("%s is too low, it must be more than %s", answer, lower)}
+		}
+		if upper != nil && v.Cmp(upper) >= 0 {
+			return &RefusedError{fmt.Sprintf("%s is too high, it must be less than %s", answer, upper)}
+		}
+	}
+
+	// Waiting applies to every day
+	for _, a := range h.Attempts {
+		if now.Before(a.Until) {
+			return &RefusedError{fmt.Sprintf("need to wait %v", a.Until.Sub(now).Round(time.Second))}
+		}
+	}
+
+	return nil
+}
+
+// Submit checks 'answer' against 'h', and if it might be right submits it
+// with 's'. The attempt is recorded and saved in 'h'.
+func Submit(s Submitter, h *History, day, part int, answer string) (*Response, error) {
+	now := time.Now()
+
+	if err := h.Check(day, part, answer, now); err != nil {
+		return nil, err
+	}
+
+	resp, err := s.Submit(day, part, answer)
+	if err != nil {
+		return nil, err
+	}
+
+	a := Attempt{
+		Day:    day,
+		Part:   part,
+		Answer: answer,
+		Result: resp.Result,
+		Time:   now,
+	}
+	if resp.Wait > 0 {
+		a.Until = now.Add(resp.Wait)
+	}
+
+	h.Attempts = append(h.Attempts, a)
+
+	return resp, h.Save()
+}
@@ -0,0 +1,41 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter enforces a minimum interval between requests
+type Limiter struct {
+	Interval time.Duration
+
+	// For testing
+	now   func() time.Time
+	sleep func(time.Duration)
+
+	mu   sync.Mutex
+	last time.Time
+}
+
+func NewLimiter(interval time.Duration) *Limiter {
+	return &Limiter{
+		Interval: interval,
+		now:      time.Now,
+		sleep:    time.Sleep,
+	}
+}
+
+// Wait blocks until at least Interval has passed since the previous call
+// returned
+func (l *Limiter) Wait() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.last.IsZero() {
+		if wait := l.Interval - l.now().Sub(l.last); wait > 0 {
+			l.sleep(wait)
+		}
+	}
+
+	l.last = l.now()
+}
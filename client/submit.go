@@ -0,0 +1,111 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Result is the outcome of submitting an answer
+type Result string
+
+const (
+	Correct Result = "correct"
+	Wrong   Result = "wrong"
+	TooHigh Result = "too_high"
+	TooLow  Result = "too_low"
+	Wait    Result = "wait"
+	// WrongLevel is the reply for a part which is already solved, or for
+	// part 2 before part 1 is solved, so it doesn't say much
+	WrongLevel Result = "wrong_level"
+	Unknown    Result = "unknown"
+)
+
+// IsWrong returns true for any of the incorrect answer results
+func (r Result) IsWrong() bool {
+	return r == Wrong || r == TooHigh || r == TooLow
+}
+
+// Response is the server's reply to a submission
+type Response struct {
+	Result Result
+	// How long until another answer can be submitted, if known
+	Wait time.Duration
+	// The text of the reply
+	Message string
+}
+
+// Submitter sends answers to be checked. It's implemented by Client, and
+// can be replaced for testing.
+type Submitter interface {
+	Submit(day, part int, answer string) (*Response, error)
+}
+
+var articleRegexp = regexp.MustCompile(`(?s)<article>(.*?)</article>`)
+var waitRegexp = regexp.MustCompile(`(?:(\d+)m )?(\d+)s left to wait`)
+
+// ParseResponse works out the result from the page returned after
+// submitting an answer
+func ParseResponse(page []byte) *Response {
+	text := string(page)
+	if m := articleRegexp.FindStringSubmatch(text); m != nil {
+		text = m[1]
+	}
+	text = strings.TrimSpace(tagRegexp.ReplaceAllString(text, ""))
+
+	r := &Response{Result: Unknown, Message: text}
+
+	switch {
+	case strings.Contains(text, "That's the right answer"):
+		r.Result = Correct
+	case strings.Contains(text, "That's not the right answer"):
+		r.Result = Wrong
+		if strings.Contains(text, "your answer is too high") {
+			r.Result = TooHigh
+		} else if strings.Contains(text, "your answer is too low") {
+			r.Result = TooLow
+		}
+	case strings.Contains(text, "You gave an answer too recently"):
+		r.Result = Wait
+	case strings.Contains(text, "You don't seem to be solving the right level"):
+		r.Result = WrongLevel
+	}
+
+	// Wrong answers also come with a timeout
+	if m := waitRegexp.FindStringSubmatch(text); m != nil {
+		var mins, secs int
+		fmt.Sscan(m[1], &mins)
+		fmt.Sscan(m[2], &secs)
+		r.Wait = time.Duration(mins)*time.Minute + time.Duration(secs)*time.Second
+	} else if strings.Contains(text, "wait one minute") {
+		r.Wait = time.Minute
+	} else if strings.Contains(text, "wait 5 minutes") {
+		r.Wait = 5 * time.Minute
+	}
+
+	return r
+}
+
+// Submit posts 'answer' for 'part' of 'day', and returns the response
+func (c *Client) Submit(day, part int, answer string) (*Response, error) {
+	form := url.Values{
+		"level":  {fmt.Sprint(part)},
+		"answer": {answer},
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.url(day, "/answer"), strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	page, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseResponse(page), nil
+}
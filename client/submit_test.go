@@ -0,0 +1,219 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func page(msg string) []byte {
+	return []byte("<html><body><main>\n<article><p>" + msg + "</p></article>\n</main></body></html>")
+}
+
+func TestParseResponse(t *testing.T) {
+	tests := []struct {
+		page   []byte
+		result Result
+		wait   time.Duration
+	}{
+		{
+			page("That's the right answer!  You are <span class=\"day-success\">one gold star</span> closer to saving your vacation. <a href=\"/2021/day/1#part2\">[Continue to Part Two]</a>"),
+			Correct, 0,
+		},
+		{
+			page("That's not the right answer.  If you're stuck, make sure you're using the full input data; there are also some general tips on the <a href=\"/2021/about\">about page</a>, or you can ask for hints on the <a href=\"https://www.reddit.com/r/adventofcode/\" target=\"_blank\">subreddit</a>.  Please wait one minute before trying again. (You guessed <span style=\"white-space:nowrap;\"><code>1234</code>.)</span> <a href=\"/2021/day/1\">[Return to Day 1]</a>"),
+			Wrong, time.Minute,
+		},
+		{
+			page("That's not the right answer; your answer is too high.  If you're stuck, make sure you're using the full input data.  Please wait one minute before trying again. <a href=\"/2021/day/1\">[Return to Day 1]</a>"),
+			TooHigh, time.Minute,
+		},
+		{
+			page("That's not the right answer; your answer is too low.  Please wait 5 minutes before trying again. <a href=\"/2021/day/1\">[Return to Day 1]</a>"),
+			TooLow, 5 * time.Minute,
+		},
+		{
+			page("You gave an answer too recently; you have to wait after submitting an answer before trying again.  You have 4m 32s left to wait. <a href=\"/2021/day/1\">[Return to Day 1]</a>"),
+			Wait, 4*time.Minute + 32*time.Second,
+		},
+		{
+			page("You gave an answer too recently; you have to wait after submitting an answer before trying again.  You have 12s left to wait. <a href=\"/2021/day/1\">[Return to Day 1]</a>"),
+			Wait, 12 * time.Second,
+		},
+		{
+			page("You don't seem to be solving the right level.  Did you already complete it? <a href=\"/2021/day/1\">[Return to Day 1]</a>"),
+			WrongLevel, 0,
+		},
+		{
+			[]byte("<html>Something else</html>"),
+			Unknown, 0,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			r := ParseResponse(test.page)
+			if r.Result != test.result {
+				t.Errorf("got %v, want %v", r.Result, test.result)
+			}
+			if r.Wait != test.wait {
+				t.Errorf("got wait %v, want %v", r.Wait, test.wait)
+			}
+		})
+	}
+}
+
+func TestClientSubmit(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/2021/day/3/answer" {
+			http.NotFound(w, r)
+			return
+		}
+
+		if r.FormValue("level") != "2" || r.FormValue("answer") != "4321" {
+			t.Errorf("unexpected form %v", r.Form)
+		}
+
+		w.Write(page("That's the right answer!"))
+	}))
+	defer srv.Close()
+
+	c := New(testSession)
+	c.BaseURL = srv.URL
+	c.HTTP = srv.Client()
+	c.Limiter = nil
+
+	r, err := c.Submit(3, 2, "4321")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r.Result != Correct {
+		t.Errorf("got %v, want %v", r.Result, Correct)
+	}
+}
+
+// A fake server, which knows the answer is 50
+type fakeSubmitter struct {
+	submitted []string
+}
+
+func (f *fakeSubmitter) Submit(day, part int, answer string) (*Response, error) {
+	f.submitted = append(f.submitted, answer)
+
+	var v int
+	fmt.Sscan(answer, &v)
+
+	switch {
+	case v > 50:
+		return &Response{Result: TooHigh}, nil
+	case v < 50:
+		return &Response{Result: TooLow}, nil
+	default:
+		return &Response{Result: Correct}, nil
+	}
+}
+
+func TestSubmit(t *testing.T) {
+	s := &fakeSubmitter{}
+	path := filepath.Join(t.TempDir(), "history.json")
+	h, err := LoadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	submit := func(answer string) (*Response, error) {
+		return Submit(s, h, 1, 1, answer)
+	}
+
+	refused := func(answer string) {
+		t.Helper()
+		_, err := submit(answer)
+
+		var re *RefusedError
+		if !errors.As(err, &re) {
+			t.Errorf("%s: expected a RefusedError, got %v", answer, err)
+		}
+	}
+
+	for _, answer := range []string{"100", "10"} {
+		if _, err := submit(answer); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Already known wrong, or out of the bounds
+	refused("100")
+	refused("10")
+	refused("5")
+	refused("200")
+
+	// Another part is unaffected
+	if _, err := Submit(s, h, 1, 2, "200"); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := submit("50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Result != Correct {
+		t.Errorf("expected correct, got %v", r.Result)
+	}
+
+	// Once it's solved, there's nothing more to submit
+	refused("50")
+
+	want := []string{"100", "10", "200", "50"}
+	if fmt.Sprint(s.submitted) != fmt.Sprint(want) {
+		t.Errorf("submitted %v, want %v", s.submitted, want)
+	}
+
+	// The history should have been saved
+	h, err = LoadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(h.Attempts) != 4 {
+		t.Errorf("expected 4 attempts in the history, got %d", len(h.Attempts))
+	}
+}
+
+func TestCheckWrongLevel(t *testing.T) {
+	// Part 2 submitted before part 1 was solved
+	h := &History{
+		Attempts: []Attempt{
+			{Day: 4, Part: 2, Answer: "12", Result: WrongLevel},
+		},
+	}
+
+	if err := h.Check(4, 2, "12", time.Now()); err != nil {
+		t.Errorf("expected to be able to try again, got %v", err)
+	}
+
+	h.Attempts = append(h.Attempts, Attempt{Day: 4, Part: 2, Answer: "12", Result: Correct})
+	if err := h.Check(4, 2, "13", time.Now()); err == nil {
+		t.Error("expected a refusal once it's correct")
+	}
+}
+
+func TestCheckWait(t *testing.T) {
+	now := time.Now()
+	h := &History{
+		Attempts: []Attempt{
+			{Day: 2, Part: 1, Answer: "1", Result: Wait, Time: now, Until: now.Add(time.Minute)},
+		},
+	}
+
+	if err := h.Check(3, 1, "7", now.Add(30*time.Second)); err == nil {
+		t.Error("expected to have to wait")
+	}
+
+	if err := h.Check(3, 1, "7", now.Add(2*time.Minute)); err != nil {
+		t.Errorf("expected no error after waiting, got %v", err)
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/usedbytes/aoc2021/solver"
+)
+
+// dayResult is the outcome of running one day with --all
+type dayResult struct {
+	day     int
+	results []*result
+	// The parts which weren't run because they're slow
+	skipped  []int
+	duration time.Duration
+	err      error
+}
+
+func (d *dayResult) status() string {
+	switch {
+	case d.err == nil:
+		return "ok"
+	case errors.Is(d.err, context.DeadlineExceeded):
+		return "timeout"
+	case errors.Is(d.err, context.Canceled):
+		return "cancelled"
+	default:
+		return "error"
+	}
+}
+
+// answer returns the answer to 'part', "slow" if it was skipped, or "-" if
+// there isn't one
+func (d *dayResult) answer(part int) string {
+	for _, r := range d.results {
+		if r.Part == part {
+			return r.Answer
+		}
+	}
+
+	for _, n := range d.skipped {
+		if n == part {
+			return "slow"
+		}
+	}
+
+	return "-"
+}
+
+// runDay solves 's' on 'inputFile', giving up after 'timeout' (if it's not 0).
+// Unless 'slow' is set, parts marked as slow are skipped.
+func runDay(ctx context.Context, s *solver.Solver, inputFile string, part int, timeout time.Duration, slow bool) dayResult {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	d := dayResult{day: s.Day}
+	if !slow {
+		d.skipped = slowParts(s, part)
+	}
+
+	j := job{
+		solver:    s,
+		inputFile: inputFile,
+		part:      part,
+		skipSlow:  !slow,
+	}
+
+	start := time.Now()
+	d.err = solve(ctx, j, func(name string, f func() error) error {
+		return f()
+	}, func(r *result) {
+		d.results = append(d.results, r)
+	})
+	d.duration = time.Since(start)
+
+	return d
+}
+
+// slowParts returns the parts of 's' which are slow, out of 'part' (0 for
+// all of them)
+func slowParts(s *solver.Solver, part int) []int {
+	var slow []int
+	for n := 1; n <= len(s.Parts); n++ {
+		if (part == 0 || part == n) && s.Part(n) != nil && s.IsSlow(n) {
+			slow = append(slow, n)
+		}
+	}
+
+	return slow
+}
+
+// runAll runs 'solvers' with a pool of 'jobs' workers, and prints a summary
+// once they've all finished (or been cancelled). Days which time out carry
+// on running in the background if they don't pay attention to their context,
+// so they still take up a worker's worth of CPU until they finish. Parts
+// marked as slow are skipped unless 'slow' is set.
+func runAll(ctx context.Context, solvers []*solver.Solver, part, jobs int, timeout time.Duration, slow bool, format string) error {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	// Skip days which don't have the part asked for (Day 25 has no part 2)
+	if part != 0 {
+		var filtered []*solver.Solver
+		for _, s := range solvers {
+			if s.Part(part) != nil {
+				filtered = append(filtered, s)
+			}
+		}
+		solvers = filtered
+	}
+
+	days := make([]dayResult, len(solvers))
+	work := make(chan int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range work {
+				days[i] = runDay(ctx, solvers[i], defaultInput(solvers[i].Day), part, timeout, slow)
+				fmt.Fprintf(os.Stderr, "Day %d: %s (%v)\n", days[i].day, days[i].status(), days[i].duration.Round(time.Millisecond))
+				if len(days[i].skipped) > 0 {
+					fmt.Fprintf(os.Stderr, "Day %d: skipped slow part(s) %v, use --slow to run them\n", days[i].day, days[i].skipped)
+				}
+			}
+		}()
+	}
+
+	for i := range solvers {
+		work <- i
+	}
+	close(work)
+	wg.Wait()
+
+	if format == "json" {
+		printAllJSON(os.Stdout, days)
+	} else {
+		printSummary(os.Stdout, os.Stderr, days)
+	}
+
+	return failures(days)
+}
+
+// failures returns an error saying how many of 'days' failed, or nil if they
+// all worked. It's what makes run --all exit with an error.
+func failures(days []dayResult) error {
+	failed := 0
+	for _, d := range days {
+		if d.err != nil {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d days failed", failed, len(days))
+	}
+
+	return nil
+}
+
+// printSummary prints a table of the answers, durations and statuses to
+// 'out', and each day's error to 'errOut'. Multi-line answers don't fit in
+// the table, so they're printed after it.
+func printSummary(out, errOut io.Writer, days []dayResult) {
+	var long []*result
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Day\tPart 1\tPart 2\tTime\tStatus\t")
+
+	for i := range days {
+		d := &days[i]
+
+		answers := [2]string{}
+		for n := 1; n <= 2; n++ {
+			answers[n-1] = d.answer(n)
+			if strings.Contains(answers[n-1], "\n") {
+				answers[n-1] = "(below)"
+			}
+		}
+		for _, r := range d.results {
+			if strings.Contains(r.Answer, "\n") {
+				long = append(long, r)
+			}
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%v\t%s\t\n", d.day, answers[0], answers[1],
+			d.duration.Round(time.Millisecond), d.status())
+	}
+	w.Flush()
+
+	for _, r := range long {
+		fmt.Fprintf(out, "\nDay %d %s:\n%s\n", r.Day, r.label(), r.Answer)
+	}
+
+	for _, d := range days {
+		if d.err != nil {
+			fmt.Fprintf(errOut, "\nDay %d: %v\n", d.day, d.err)
+		}
+	}
+}
+
+// printAllJSON prints every answer to 'w' as a line of JSON, like run
+// --format json, plus a line with the error for each day which failed
+func printAllJSON(w io.Writer, days []dayResult) {
+	for _, d := range days {
+		for _, r := range d.results {
+			r.printJSON(w)
+		}
+
+		if d.err != nil {
+			r := &result{
+				Day:      d.day,
+				Duration: d.duration,
+				Error:    d.err.Error(),
+			}
+			r.printJSON(w)
+		}
+	}
+}
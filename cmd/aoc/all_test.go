@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/usedbytes/aoc2021/solver"
+)
+
+func TestRunDay(t *testing.T) {
+	filename := writeInput(t, "1\n2\n3\n")
+
+	// Part 2 fails, after part 1's answer
+	d := runDay(context.Background(), summer, filename, 0, time.Minute, false)
+	if d.status() != "error" || d.answer(1) != "6" || d.answer(2) != "-" {
+		t.Errorf("got status %s, answers %q and %q, want error, 6 and -", d.status(), d.answer(1), d.answer(2))
+	}
+
+	d = runDay(context.Background(), summer, filename, 1, time.Minute, false)
+	if d.status() != "ok" || d.answer(1) != "6" {
+		t.Errorf("part 1 got status %s, answer %q, want ok and 6", d.status(), d.answer(1))
+	}
+
+	// Part 2 isn't run at all when it's slow
+	slow := *summer
+	slow.Slow = [2]bool{false, true}
+
+	d = runDay(context.Background(), &slow, filename, 0, time.Minute, false)
+	if d.status() != "ok" || d.answer(2) != "slow" {
+		t.Errorf("slow part 2 got status %s, answer %q, want ok and slow", d.status(), d.answer(2))
+	}
+
+	d = runDay(context.Background(), &slow, filename, 0, time.Minute, true)
+	if d.status() != "error" {
+		t.Errorf("with slow, got status %s, want error", d.status())
+	}
+}
+
+func TestRunDayTimeout(t *testing.T) {
+	stuck := &solver.Solver{
+		Day:   2,
+		Parse: summer.Parse,
+		Parts: [2]solver.Part{
+			func(ctx context.Context, _ interface{}) (interface{}, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+		},
+	}
+
+	d := runDay(context.Background(), stuck, writeInput(t, "1\n"), 0, 10*time.Millisecond, false)
+	if d.status() != "timeout" {
+		t.Errorf("got status %s (%v), want timeout", d.status(), d.err)
+	}
+}
+
+func TestPrintSummary(t *testing.T) {
+	days := []dayResult{
+		{
+			day: 1,
+			results: []*result{
+				{Day: 1, Part: 1, Answer: "7"},
+				{Day: 1, Part: 2, Answer: "5"},
+			},
+			duration: time.Millisecond,
+		},
+		{
+			day: 13,
+			results: []*result{
+				{Day: 13, Part: 1, Answer: "17"},
+				{Day: 13, Part: 2, Answer: "#.\n.#"},
+			},
+		},
+		{
+			day:     19,
+			skipped: []int{1, 2},
+		},
+		{
+			day:     22,
+			results: []*result{{Day: 22, Part: 1, Answer: "39"}},
+			err:     fmt.Errorf("part 2: %w", context.DeadlineExceeded),
+		},
+	}
+
+	var out, errOut bytes.Buffer
+	printSummary(&out, &errOut, days)
+
+	lines := strings.Split(out.String(), "\n")
+	want := [][]string{
+		{"Day", "Part", "1", "Part", "2", "Time", "Status"},
+		{"1", "7", "5", "1ms", "ok"},
+		{"13", "17", "(below)", "0s", "ok"},
+		{"19", "slow", "slow", "0s", "ok"},
+		{"22", "39", "-", "0s", "timeout"},
+	}
+	for i, fields := range want {
+		if got := strings.Fields(lines[i]); strings.Join(got, " ") != strings.Join(fields, " ") {
+			t.Errorf("line %d is %q, want %q", i+1, lines[i], strings.Join(fields, " "))
+		}
+	}
+
+	if !strings.Contains(out.String(), "\nDay 13 Part 2:\n#.\n.#\n") {
+		t.Errorf("Day 13's answer isn't below the table:\n%s", out.String())
+	}
+
+	if got, want := errOut.String(), "\nDay 22: part 2: context deadline exceeded\n"; got != want {
+		t.Errorf("errors are %q, want %q", got, want)
+	}
+
+	if err := failures(days); err == nil || err.Error() != "1 of 4 days failed" {
+		t.Errorf("got %v, want 1 of 4 days failed", err)
+	}
+
+	if err := failures(days[:3]); err != nil {
+		t.Errorf("got %v, want no error", err)
+	}
+}
+
+func TestPrintAllJSON(t *testing.T) {
+	days := []dayResult{
+		{day: 1, results: []*result{{Day: 1, Part: 1, Answer: "7"}}},
+		{day: 3, err: fmt.Errorf("oops")},
+	}
+
+	var buf bytes.Buffer
+	printAllJSON(&buf, days)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2:\n%s", len(lines), buf.String())
+	}
+
+	if !strings.Contains(lines[0], `"answer":"7"`) {
+		t.Errorf("first line is %s, want the answer", lines[0])
+	}
+
+	if !strings.Contains(lines[1], `"day":3`) || !strings.Contains(lines[1], `"error":"oops"`) {
+		t.Errorf("second line is %s, want Day 3's error", lines[1])
+	}
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	day24 "github.com/usedbytes/aoc2021/24"
+)
+
+func aluCmd(args []string) error {
+	if len(args) == 0 || args[0] != "debug" {
+		return fmt.Errorf("usage: aoc alu debug [flags]")
+	}
+
+	fs := flag.NewFlagSet("alu debug", flag.ExitOnError)
+	inputFile := fs.String("input", defaultInput(24), "ALU program, or - for stdin")
+	digits := fs.String("digits", "", "the input digits, like a model number")
+	fs.Parse(args[1:])
+
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	data, err := readInput(*inputFile)
+	if err != nil {
+		return err
+	}
+
+	program, err := day24.Parse(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", *inputFile, err)
+	}
+
+	in, err := parseDigits(*digits)
+	if err != nil {
+		return err
+	}
+
+	return debugALU(day24.NewDebugger(program, in), os.Stdin, os.Stdout)
+}
+
+func parseDigits(s string) ([]int, error) {
+	in := make([]int, len(s))
+	for i, c := range s {
+		if c < '0' || c > '9' {
+			return nil, fmt.Errorf("%q isn't a digit", c)
+		}
+		in[i] = int(c - '0')
+	}
+
+	return in, nil
+}
+
+const aluHelp = `step [n]       run the next n instructions (s)
+back [n]       undo the last n instructions (b)
+continue       run until a breakpoint, a watch or the end (c)
+reverse        run backwards until a breakpoint, a watch or the start (rc)
+break N|inp    stop before instruction N, or every inp
+delete N|inp   remove a breakpoint
+watch REG      stop when register w, x, y or z changes
+unwatch REG    stop watching a register
+info           show the breakpoints and watches
+regs           show the registers (r)
+sym            show the registers as expressions of the digits
+list [N]       show the instructions around N, or the next one (l)
+digits DIGITS  start again with different input
+restart        start again
+quit           (q)
+
+An empty line repeats the last command.
+`
+
+// Returns the argument as a count, 1 if there isn't one
+func count(args []string) (int, error) {
+	if len(args) == 0 {
+		return 1, nil
+	}
+
+	n, err := strconv.Atoi(args[0])
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%q isn't a count", args[0])
+	}
+
+	return n, nil
+}
+
+func register(args []string) (day24.Register, error) {
+	if len(args) != 1 || len(args[0]) != 1 || !strings.Contains("wxyz", args[0]) {
+		return 0, fmt.Errorf("which register, w, x, y or z?")
+	}
+
+	return day24.Register(strings.Index("wxyz", args[0])), nil
+}
+
+// Shows where the debugger is, and the registers
+func where(d *day24.Debugger, out io.Writer) {
+	if insn, ok := d.Instruction(d.PC()); ok {
+		fmt.Fprintf(out, "=> %d: %s\n", d.PC(), insn)
+	} else {
+		fmt.Fprintln(out, "=> end of the program")
+	}
+
+	s := d.State()
+	fmt.Fprintf(out, "   w=%d x=%d y=%d z=%d\n", s.W, s.X, s.Y, s.Z)
+}
+
+// Runs one command, returning false to quit
+func aluCommand(d *day24.Debugger, cmd string, args []string, out io.Writer) (bool, error) {
+	switch cmd {
+	case "s", "step":
+		n, err := count(args)
+		if err != nil {
+			return true, err
+		}
+
+		for i := 0; i < n && !d.Done(); i++ {
+			if err := d.Step(); err != nil {
+				where(d, out)
+				return true, err
+			}
+		}
+		where(d, out)
+	case "b", "back":
+		n, err := count(args)
+		if err != nil {
+			return true, err
+		}
+
+		for i := 0; i < n && d.Back(); i++ {
+		}
+		where(d, out)
+	case "c", "continue":
+		why, err := d.Continue()
+		if err != nil {
+			where(d, out)
+			return true, err
+		}
+		fmt.Fprintln(out, "stopped:", why)
+		where(d, out)
+	case "rc", "reverse":
+		fmt.Fprintln(out, "stopped:", d.ReverseContinue())
+		where(d, out)
+	case "break", "delete":
+		on := cmd == "break"
+		if len(args) != 1 {
+			return true, fmt.Errorf("%s what, an instruction number or inp?", cmd)
+		}
+
+		if args[0] == "inp" {
+			d.BreakInp(on)
+			return true, nil
+		}
+
+		n, err := strconv.Atoi(args[0])
+		if err != nil {
+			return true, fmt.Errorf("%q isn't an instruction number", args[0])
+		}
+		return true, d.Break(n, on)
+	case "watch", "unwatch":
+		r, err := register(args)
+		if err != nil {
+			return true, err
+		}
+		d.Watch(r, cmd == "watch")
+	case "info":
+		fmt.Fprintln(out, "breakpoints:", d.Breakpoints())
+		fmt.Fprintln(out, "watching:", d.Watches())
+		fmt.Fprintf(out, "%d instructions run, of %d\n", d.Steps(), d.Len())
+	case "r", "regs":
+		where(d, out)
+	case "sym":
+		return true, d.WriteSymbolic(out)
+	case "l", "list":
+		at := d.PC()
+		if len(args) > 0 {
+			n, err := strconv.Atoi(args[0])
+			if err != nil {
+				return true, fmt.Errorf("%q isn't an instruction number", args[0])
+			}
+			at = n
+		}
+
+		for n := at - 5; n <= at+5; n++ {
+			insn, ok := d.Instruction(n)
+			if !ok {
+				continue
+			}
+
+			mark := "  "
+			if n == d.PC() {
+				mark = "=>"
+			}
+			fmt.Fprintf(out, "%s %d: %s\n", mark, n, insn)
+		}
+	case "digits":
+		if len(args) != 1 {
+			return true, fmt.Errorf("digits what?")
+		}
+
+		in, err := parseDigits(args[0])
+		if err != nil {
+			return true, err
+		}
+		d.Restart(in)
+		where(d, out)
+	case "restart":
+		d.Restart(d.Input())
+		where(d, out)
+	case "h", "help":
+		fmt.Fprint(out, aluHelp)
+	case "q", "quit":
+		return false, nil
+	default:
+		return true, fmt.Errorf("unknown command %q, try help", cmd)
+	}
+
+	return true, nil
+}
+
+// debugALU runs the debugger's REPL, reading commands from 'in'
+func debugALU(d *day24.Debugger, in io.Reader, out io.Writer) error {
+	fmt.Fprintf(out, "%d instructions, type help for the commands\n", d.Len())
+	where(d, out)
+
+	var last []string
+	sc := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(out, "(alu) ")
+		if !sc.Scan() {
+			fmt.Fprintln(out)
+			return sc.Err()
+		}
+
+		fields := strings.Fields(sc.Text())
+		if len(fields) == 0 {
+			fields = last
+		}
+		if len(fields) == 0 {
+			continue
+		}
+		last = fields
+
+		more, err := aluCommand(d, fields[0], fields[1:], out)
+		if err != nil {
+			fmt.Fprintln(out, "error:", err)
+		}
+		if !more {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/usedbytes/aoc2021/solver"
+	"github.com/usedbytes/aoc2021/term"
+)
+
+func animateCmd(args []string) error {
+	fs := flag.NewFlagSet("animate", flag.ExitOnError)
+	day := fs.Int("day", 0, "day to animate")
+	inputFile := fs.String("input", "", "input file, or - for stdin (default NN/input.txt)")
+	fps := fs.Int("fps", 0, "frames per second (default depends on the day)")
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	s := solver.Get(*day)
+	if s == nil {
+		return fmt.Errorf("no solver for day %d", *day)
+	}
+
+	if s.Animation == nil {
+		var days []int
+		for _, s := range solver.All() {
+			if s.Animation != nil {
+				days = append(days, s.Day)
+			}
+		}
+		return fmt.Errorf("day %d can't be animated, only days %v", *day, days)
+	}
+
+	if *inputFile == "" {
+		*inputFile = defaultInput(s.Day)
+	}
+
+	if *fps == 0 {
+		*fps = s.Animation.FPS
+	}
+
+	data, err := readInput(*inputFile)
+	if err != nil {
+		return err
+	}
+
+	in, err := s.Parse(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", *inputFile, err)
+	}
+
+	return s.Animation.Animate(term.New(os.Stdout, *fps), in)
+}
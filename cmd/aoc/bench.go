@@ -0,0 +1,337 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+	"text/tabwriter"
+	"time"
+
+	"github.com/usedbytes/aoc2021/solver"
+)
+
+// timing is the cost of one stage (parse, part1 or part2) of a day
+type timing struct {
+	Duration time.Duration `json:"duration"`
+	Allocs   uint64        `json:"allocs"`
+	Bytes    uint64        `json:"bytes"`
+}
+
+// baseline maps "day/stage" to a previous timing
+type baseline map[string]timing
+
+func stageKey(day int, stage string) string {
+	return fmt.Sprintf("%d/%s", day, stage)
+}
+
+func loadBaseline(filename string) (baseline, error) {
+	b := make(baseline)
+
+	data, err := os.ReadFile(filename)
+	if os.IsNotExist(err) {
+		return b, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	return b, nil
+}
+
+func (b baseline) save(filename string) error {
+	data, err := json.MarshalIndent(b, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, append(data, '\n'), 0644)
+}
+
+func measure(f func() error) (timing, error) {
+	var before, after runtime.MemStats
+
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	start := time.Now()
+	err := f()
+	d := time.Since(start)
+
+	runtime.ReadMemStats(&after)
+
+	return timing{
+		Duration: d,
+		Allocs:   after.Mallocs - before.Mallocs,
+		Bytes:    after.TotalAlloc - before.TotalAlloc,
+	}, err
+}
+
+// Runs 'f' 'count' times, and returns the fastest. 'setup' (if not nil) is
+// run before each one, and isn't measured.
+func measureBest(count int, setup, f func() error) (timing, error) {
+	var best timing
+	for i := 0; i < count; i++ {
+		if setup != nil {
+			if err := setup(); err != nil {
+				return timing{}, err
+			}
+		}
+
+		t, err := measure(f)
+		if err != nil {
+			return t, err
+		}
+
+		if i == 0 || t.Duration < best.Duration {
+			best = t
+		}
+	}
+
+	return best, nil
+}
+
+func formatDuration(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(time.Microsecond).String()
+	default:
+		return d.String()
+	}
+}
+
+func formatBytes(b uint64) string {
+	units := []string{"B", "KiB", "MiB", "GiB"}
+
+	v := float64(b)
+	i := 0
+	for v >= 1024 && i < len(units)-1 {
+		v /= 1024
+		i++
+	}
+
+	if i == 0 {
+		return fmt.Sprintf("%d B", b)
+	}
+
+	return fmt.Sprintf("%.1f %s", v, units[i])
+}
+
+// benchDay measures each stage of 's' on 'filename'. Parts which are marked
+// as slow are skipped unless 'slow' is set, and parts which run out of time
+// are skipped too. The second map says why each skipped part was skipped.
+//
+// Most parts don't pay attention to their context, so one which times out
+// carries on in the background, and would slow down anything measured after
+// it. The rest of the day's parts are skipped after a timeout, and the
+// caller shouldn't measure anything else either.
+func benchDay(ctx context.Context, s *solver.Solver, filename string, count int, slow bool) (map[string]timing, map[string]string, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parse := func() (interface{}, error) {
+		return s.Parse(bytes.NewReader(data))
+	}
+
+	results := make(map[string]timing)
+	skipped := make(map[string]string)
+
+	t, err := measureBest(count, nil, func() error {
+		_, err := parse()
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("parsing %s: %w", filename, err)
+	}
+	results["parse"] = t
+
+	gaveUp := false
+	for n := 1; n <= len(s.Parts); n++ {
+		part := s.Part(n)
+		if part == nil {
+			continue
+		}
+
+		stage := fmt.Sprintf("part%d", n)
+		if gaveUp {
+			skipped[stage] = "skipped"
+			continue
+		} else if !slow && s.IsSlow(n) {
+			skipped[stage] = "slow"
+			continue
+		}
+
+		// Each run gets a fresh input, in case the part modifies it
+		var in interface{}
+		t, err := measureBest(count, func() error {
+			var err error
+			in, err = parse()
+			return err
+		}, func() error {
+			return solver.WithContext(ctx, func() error {
+				_, err := part(ctx, in)
+				return err
+			})
+		})
+		if errors.Is(err, context.DeadlineExceeded) {
+			skipped[stage] = "timeout"
+			gaveUp = true
+			continue
+		} else if err != nil {
+			return nil, nil, err
+		}
+		results[stage] = t
+	}
+
+	return results, skipped, nil
+}
+
+// report writes a row to 'w' for each stage of 'day', comparing its timing
+// with the one in 'base'. It returns how many stages are more than
+// 'threshold' percent slower than their baseline.
+func report(w io.Writer, day int, results map[string]timing, skipped map[string]string, base baseline, threshold float64) int {
+	regressions := 0
+
+	for _, stage := range []string{"parse", "part1", "part2"} {
+		if why, ok := skipped[stage]; ok {
+			fmt.Fprintf(w, "%d\t%s\t%s\t-\t-\t-\t-\t\n", day, stage, why)
+			continue
+		}
+
+		t, ok := results[stage]
+		if !ok {
+			continue
+		}
+
+		prev, ok := base[stageKey(day, stage)]
+		baseStr, change := "-", "-"
+		if ok && prev.Duration > 0 {
+			pct := 100 * (float64(t.Duration) - float64(prev.Duration)) / float64(prev.Duration)
+			baseStr = formatDuration(prev.Duration)
+			change = fmt.Sprintf("%+.1f%%", pct)
+			if pct > threshold {
+				change += " !"
+				regressions++
+			}
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%d\t%s\t%s\t%s\t\n", day, stage,
+			formatDuration(t.Duration), t.Allocs, formatBytes(t.Bytes),
+			baseStr, change)
+	}
+
+	return regressions
+}
+
+func benchCmd(args []string) error {
+	fs := flag.NewFlagSet("bench", flag.ExitOnError)
+	day := fs.Int("day", 0, "day to benchmark, or 0 for all")
+	count := fs.Int("count", 1, "number of times to run each stage, the fastest is reported")
+	baselineFile := fs.String("baseline", "bench.json", "file of previous timings to compare against")
+	save := fs.Bool("save", false, "save the timings to the baseline file")
+	threshold := fs.Float64("threshold", 10, "percentage slowdown to flag as a regression")
+	timeout := fs.Duration("timeout", time.Minute, "time limit for each day, or 0 for none")
+	slow := fs.Bool("slow", false, "also benchmark the parts which take minutes")
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	if *count < 1 {
+		return fmt.Errorf("invalid count %d", *count)
+	}
+
+	solvers := solver.All()
+	if *day != 0 {
+		s := solver.Get(*day)
+		if s == nil {
+			return fmt.Errorf("no solver for day %d", *day)
+		}
+		solvers = []*solver.Solver{s}
+	}
+
+	base, err := loadBaseline(*baselineFile)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "Day\tStage\tTime\tAllocs\tBytes\tBaseline\tChange\t")
+
+	// Ctrl-C stops the day being benchmarked
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// The day which timed out, if any, which is where benchmarking stops
+	stopped := 0
+
+	regressions := 0
+	for _, s := range solvers {
+		fmt.Fprintf(os.Stderr, "Day %d...\n", s.Day)
+
+		dayCtx, cancel := ctx, context.CancelFunc(func() {})
+		if *timeout > 0 {
+			dayCtx, cancel = context.WithTimeout(ctx, *timeout)
+		}
+		results, skipped, err := benchDay(dayCtx, s, defaultInput(s.Day), *count, *slow)
+		cancel()
+		if err != nil {
+			return fmt.Errorf("day %d: %w", s.Day, err)
+		}
+
+		regressions += report(w, s.Day, results, skipped, base, *threshold)
+
+		if *save {
+			for stage, t := range results {
+				base[stageKey(s.Day, stage)] = t
+			}
+		}
+
+		if timedOut(skipped) {
+			stopped = s.Day
+			break
+		}
+	}
+	w.Flush()
+
+	if regressions > 0 {
+		fmt.Printf("%d stage(s) more than %.0f%% slower than the baseline\n", regressions, *threshold)
+	}
+
+	if *save {
+		if err := base.save(*baselineFile); err != nil {
+			return err
+		}
+	}
+
+	if stopped != 0 {
+		return fmt.Errorf("day %d timed out and may still be running, so the days after it weren't benchmarked", stopped)
+	}
+
+	return nil
+}
+
+// timedOut returns true if any of the parts in 'skipped' timed out
+func timedOut(skipped map[string]string) bool {
+	for _, why := range skipped {
+		if why == "timeout" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/usedbytes/aoc2021/solver"
+)
+
+func TestBaseline(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "bench.json")
+
+	// A missing baseline is just empty
+	b, err := loadBaseline(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) != 0 {
+		t.Errorf("got %v, want an empty baseline", b)
+	}
+
+	b[stageKey(1, "part1")] = timing{Duration: time.Millisecond, Allocs: 3, Bytes: 100}
+	b[stageKey(2, "parse")] = timing{Duration: time.Second}
+	if err := b.save(filename); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := loadBaseline(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, b) {
+		t.Errorf("loaded %v, want %v", got, b)
+	}
+
+	if err := os.WriteFile(filename, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadBaseline(filename); err == nil || !strings.Contains(err.Error(), filename) {
+		t.Errorf("got %v, want an error about %s", err, filename)
+	}
+}
+
+func TestReport(t *testing.T) {
+	base := baseline{
+		stageKey(5, "parse"): {Duration: 100 * time.Millisecond},
+		stageKey(5, "part1"): {Duration: 100 * time.Millisecond},
+		// Other days' timings don't matter
+		stageKey(6, "part2"): {Duration: time.Nanosecond},
+	}
+
+	results := map[string]timing{
+		"parse": {Duration: 105 * time.Millisecond, Allocs: 10, Bytes: 2048},
+		"part1": {Duration: 150 * time.Millisecond, Allocs: 20, Bytes: 10},
+	}
+	skipped := map[string]string{"part2": "slow"}
+
+	var buf bytes.Buffer
+	regressions := report(&buf, 5, results, skipped, base, 10)
+	if regressions != 1 {
+		t.Errorf("got %d regressions, want 1", regressions)
+	}
+
+	want := []string{
+		"5\tparse\t105ms\t10\t2.0 KiB\t100ms\t+5.0%\t",
+		"5\tpart1\t150ms\t20\t10 B\t100ms\t+50.0% !\t",
+		"5\tpart2\tslow\t-\t-\t-\t-\t",
+	}
+	if got := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n"); !reflect.DeepEqual(got, want) {
+		t.Errorf("got rows\n%q\nwant\n%q", got, want)
+	}
+
+	// With no baseline, there's nothing to compare with
+	buf.Reset()
+	if regressions := report(&buf, 5, results, nil, baseline{}, 10); regressions != 0 {
+		t.Errorf("got %d regressions with no baseline, want 0", regressions)
+	}
+	if !strings.Contains(buf.String(), "5\tpart1\t150ms\t20\t10 B\t-\t-\t") {
+		t.Errorf("got rows\n%s\nwant part 1 without a baseline", buf.String())
+	}
+}
+
+func TestBenchDay(t *testing.T) {
+	filename := writeInput(t, "1\n2\n3\n")
+
+	slow := *summer
+	slow.Slow = [2]bool{false, true}
+
+	results, skipped, err := benchDay(context.Background(), &slow, filename, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := results["parse"]; !ok {
+		t.Error("parse wasn't measured")
+	}
+	if _, ok := results["part1"]; !ok {
+		t.Error("part 1 wasn't measured")
+	}
+	if why := skipped["part2"]; why != "slow" {
+		t.Errorf("part 2 was skipped because %q, want slow", why)
+	}
+
+	// Without skipping it, part 2's error stops the benchmark
+	if _, _, err := benchDay(context.Background(), &slow, filename, 1, true); err == nil {
+		t.Error("expected part 2's error")
+	}
+}
+
+func TestBenchDayTimeout(t *testing.T) {
+	stuck := *summer
+	stuck.Parts = [2]solver.Part{
+		func(ctx context.Context, _ interface{}) (interface{}, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		},
+		summer.Parts[0],
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	results, skipped, err := benchDay(ctx, &stuck, writeInput(t, "1\n"), 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Part 2 would be measured while part 1 is still running
+	want := map[string]string{"part1": "timeout", "part2": "skipped"}
+	if !reflect.DeepEqual(skipped, want) {
+		t.Errorf("skipped %v, want %v", skipped, want)
+	}
+
+	if _, ok := results["part2"]; ok {
+		t.Error("part 2 was measured after part 1 timed out")
+	}
+
+	if !timedOut(skipped) {
+		t.Error("timedOut is false")
+	}
+}
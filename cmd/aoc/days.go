@@ -0,0 +1,32 @@
+// Code generated by "aoc new". DO NOT EDIT.
+
+package main
+
+// Importing each day registers it with the solver package
+import (
+	_ "github.com/usedbytes/aoc2021/01"
+	_ "github.com/usedbytes/aoc2021/02"
+	_ "github.com/usedbytes/aoc2021/03"
+	_ "github.com/usedbytes/aoc2021/04"
+	_ "github.com/usedbytes/aoc2021/05"
+	_ "github.com/usedbytes/aoc2021/06"
+	_ "github.com/usedbytes/aoc2021/07"
+	_ "github.com/usedbytes/aoc2021/08"
+	_ "github.com/usedbytes/aoc2021/09"
+	_ "github.com/usedbytes/aoc2021/10"
+	_ "github.com/usedbytes/aoc2021/11"
+	_ "github.com/usedbytes/aoc2021/12"
+	_ "github.com/usedbytes/aoc2021/13"
+	_ "github.com/usedbytes/aoc2021/14"
+	_ "github.com/usedbytes/aoc2021/15"
+	_ "github.com/usedbytes/aoc2021/16"
+	_ "github.com/usedbytes/aoc2021/17"
+	_ "github.com/usedbytes/aoc2021/18"
+	_ "github.com/usedbytes/aoc2021/19"
+	_ "github.com/usedbytes/aoc2021/20"
+	_ "github.com/usedbytes/aoc2021/21"
+	_ "github.com/usedbytes/aoc2021/22"
+	_ "github.com/usedbytes/aoc2021/23"
+	_ "github.com/usedbytes/aoc2021/24"
+	_ "github.com/usedbytes/aoc2021/25"
+)
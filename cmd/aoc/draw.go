@@ -0,0 +1,80 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/usedbytes/aoc2021/solver"
+)
+
+func drawCmd(args []string) error {
+	fs := flag.NewFlagSet("draw", flag.ExitOnError)
+	day := fs.Int("day", 0, "day to draw")
+	inputFile := fs.String("input", "", "input file, or - for stdin (default NN/input.txt)")
+	out := fs.String("out", "", "file to write the image to (default dayNN.png or dayNN.gif)")
+	scale := fs.Int("scale", 0, "size of each cell in pixels (default depends on the day)")
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	s := solver.Get(*day)
+	if s == nil {
+		return fmt.Errorf("no solver for day %d", *day)
+	}
+
+	if s.Drawing == nil {
+		var days []int
+		for _, s := range solver.All() {
+			if s.Drawing != nil {
+				days = append(days, s.Day)
+			}
+		}
+		return fmt.Errorf("day %d can't be drawn, only days %v", *day, days)
+	}
+
+	if *inputFile == "" {
+		*inputFile = defaultInput(s.Day)
+	}
+
+	if *out == "" {
+		*out = fmt.Sprintf("day%02d.%s", s.Day, s.Drawing.Format)
+	}
+
+	data, err := readInput(*inputFile)
+	if err != nil {
+		return err
+	}
+
+	in, err := s.Parse(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("parsing %s: %w", *inputFile, err)
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	if err := s.Drawing.Draw(w, in, *scale); err != nil {
+		return err
+	}
+
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(os.Stderr, "Wrote", *out)
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/usedbytes/aoc2021/client"
+)
+
+// Copies 'src' to 'dst', unless 'dst' already exists
+func copyIfMissing(src, dst string) (bool, error) {
+	if _, err := os.Stat(dst); err == nil {
+		return false, nil
+	}
+
+	data, err := os.ReadFile(src)
+	if err != nil {
+		return false, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return false, err
+	}
+
+	return true, os.WriteFile(dst, data, 0644)
+}
+
+func fetchCmd(args []string) error {
+	fs := flag.NewFlagSet("fetch", flag.ExitOnError)
+	day := fs.Int("day", 0, "day to fetch, or 0 for all")
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	days := []int{*day}
+	if *day == 0 {
+		days = nil
+		for d := 1; d <= 25; d++ {
+			days = append(days, d)
+		}
+	}
+
+	session, err := client.LoadSession()
+	if err != nil {
+		return err
+	}
+
+	c := client.New(session)
+	cache, err := client.DefaultCache(session)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range days {
+		f, err := client.Fetch(c, cache, d)
+		if err != nil {
+			return fmt.Errorf("day %d: %w", d, err)
+		}
+
+		status := "cached"
+		if len(f.Downloaded) > 0 {
+			status = "downloaded"
+		}
+		fmt.Printf("Day %d: %s (%s)\n", d, f.Input, status)
+		if f.Example != "" {
+			fmt.Printf("        %s\n", f.Example)
+		}
+
+		// Put the input where 'aoc run' expects it, but never overwrite
+		// one which is already there
+		dst := defaultInput(d)
+		copied, err := copyIfMissing(f.Input, dst)
+		if err != nil {
+			return err
+		}
+		if copied {
+			fmt.Printf("        copied to %s\n", dst)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/usedbytes/aoc2021/solver"
+)
+
+// generator returns the solver for 'day', if it has a generator
+func generator(day int) (*solver.Solver, error) {
+	s := solver.Get(day)
+	if s == nil {
+		return nil, fmt.Errorf("no solver for day %d", day)
+	}
+
+	if s.Generate == nil {
+		return nil, fmt.Errorf("day %d has no generator", day)
+	}
+
+	return s, nil
+}
+
+func genCmd(args []string) error {
+	fs := flag.NewFlagSet("gen", flag.ExitOnError)
+	day := fs.Int("day", 0, "day to generate an input for (1-25)")
+	size := fs.Int("size", 0, "size of the input, which depends on the day (default about the same as a real input)")
+	seed := fs.Int64("seed", 1, "random seed")
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	s, err := generator(*day)
+	if err != nil {
+		return err
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	if err := s.Generate(w, rand.New(rand.NewSource(*seed)), *size); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
+
+// generateFile writes the input for 'seed' to a file in 'dir', and returns
+// its name
+func generateFile(s *solver.Solver, dir string, size int, seed int64) (string, error) {
+	name := filepath.Join(dir, fmt.Sprintf("day%02d-size%d-seed%d.txt", s.Day, size, seed))
+
+	f, err := os.Create(name)
+	if err != nil {
+		return "", err
+	}
+
+	w := bufio.NewWriter(f)
+	err = s.Generate(w, rand.New(rand.NewSource(seed)), size)
+	if err == nil {
+		err = w.Flush()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	return name, err
+}
+
+func stressCmd(args []string) error {
+	fs := flag.NewFlagSet("stress", flag.ExitOnError)
+	day := fs.Int("day", 0, "day to stress test (1-25)")
+	part := fs.Int("part", 0, "part to run (1 or 2), or 0 for both")
+	size := fs.Int("size", 0, "size of the inputs, which depends on the day (default about the same as a real input)")
+	seed := fs.Int64("seed", 1, "random seed of the first input, the rest count up from it")
+	count := fs.Int("count", 100, "number of inputs to run")
+	timeout := fs.Duration("timeout", 10*time.Second, "time limit for each input, or 0 for none")
+	dir := fs.String("dir", filepath.Join(os.TempDir(), "aoc-stress"), "directory to save failing inputs in")
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	s, err := generator(*day)
+	if err != nil {
+		return err
+	}
+
+	if *part < 0 || *part > len(s.Parts) {
+		return fmt.Errorf("invalid part %d", *part)
+	}
+
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	type failure struct {
+		seed  int64
+		input string
+		d     dayResult
+	}
+
+	var failures []failure
+	times := make(map[int][]time.Duration)
+
+	for i := 0; i < *count && ctx.Err() == nil; i++ {
+		seed := *seed + int64(i)
+
+		var d dayResult
+		name, err := generateFile(s, *dir, *size, seed)
+		if err != nil {
+			d = dayResult{day: s.Day, err: fmt.Errorf("generating: %w", err)}
+		} else {
+			d = runDay(ctx, s, name, *part, *timeout, true)
+		}
+
+		fmt.Fprintf(os.Stderr, "Seed %d: %s (%v)\n", seed, d.status(), d.duration.Round(time.Millisecond))
+
+		if d.err != nil {
+			failures = append(failures, failure{seed, name, d})
+			continue
+		}
+
+		for _, r := range d.results {
+			times[r.Part] = append(times[r.Part], r.Duration)
+		}
+		os.Remove(name)
+	}
+
+	// The spread of times for each part, over the inputs which passed
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Part\tInputs\tMin\tMedian\tMax\t")
+	for n := 1; n <= len(s.Parts); n++ {
+		t := times[n]
+		if len(t) == 0 {
+			continue
+		}
+		sort.Slice(t, func(i, j int) bool { return t[i] < t[j] })
+
+		fmt.Fprintf(w, "%d\t%d\t%v\t%v\t%v\t\n", n, len(t), t[0].Round(time.Microsecond),
+			t[len(t)/2].Round(time.Microsecond), t[len(t)-1].Round(time.Microsecond))
+	}
+	w.Flush()
+
+	for _, f := range failures {
+		fmt.Printf("\nSeed %d: %v\n", f.seed, f.d.err)
+		if f.input != "" {
+			fmt.Printf("Input saved as %s\n", f.input)
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("%d inputs failed", len(failures))
+	}
+
+	return ctx.Err()
+}
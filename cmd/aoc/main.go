@@ -0,0 +1,65 @@
+// Command aoc runs the solvers for any day
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands []command
+
+func init() {
+	commands = []command{
+		{"run", "run a day's solver", runCmd},
+		{"list", "list the days and their options", listCmd},
+		{"bench", "time each day, and compare against a baseline", benchCmd},
+		{"draw", "draw a day's puzzle as a PNG or GIF", drawCmd},
+		{"animate", "animate a day's puzzle in the terminal", animateCmd},
+		{"gen", "generate a random input for a day", genCmd},
+		{"stress", "run a day on lots of generated inputs", stressCmd},
+		{"serve", "answer puzzles over HTTP", serveCmd},
+		{"alu", "step through an ALU program (alu debug)", aluCmd},
+		{"fetch", "download and cache puzzle inputs", fetchCmd},
+		{"submit", "submit an answer", submitCmd},
+		{"new", "create a new day from template.go", newCmd},
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: aoc <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", c.name, c.usage)
+	}
+}
+
+func run() error {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			return c.run(os.Args[2:])
+		}
+	}
+
+	usage()
+	return fmt.Errorf("unknown command %q", os.Args[1])
+}
+
+func main() {
+	err := run()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ERROR:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/usedbytes/aoc2021/client"
+	"github.com/usedbytes/aoc2021/scaffold"
+)
+
+// Finds the example for 'day', from 'filename' or else the fetch cache
+func findExample(day int, filename string) (string, error) {
+	if filename != "" {
+		data, err := os.ReadFile(filename)
+		return string(data), err
+	}
+
+	session, err := client.LoadSession()
+	if err != nil {
+		return "", nil
+	}
+
+	cache, err := client.DefaultCache(session)
+	if err != nil || !cache.Has(day, client.ExampleFile) {
+		return "", nil
+	}
+
+	data, err := cache.Get(day, client.ExampleFile)
+	return string(data), err
+}
+
+func newCmd(args []string) error {
+	fs := flag.NewFlagSet("new", flag.ExitOnError)
+	day := fs.Int("day", 0, "day to create (1-25)")
+	example := fs.String("example", "", "example input for the tests (default: from 'aoc fetch', if there is one)")
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	if *day < 1 || *day > 25 {
+		return fmt.Errorf("invalid day %d", *day)
+	}
+
+	d := scaffold.NewDay(*day)
+
+	var err error
+	d.Example, err = findExample(*day, *example)
+	if err != nil {
+		return err
+	}
+
+	files, err := scaffold.Generate(".", d)
+	for _, f := range files {
+		fmt.Println("Wrote", f)
+	}
+
+	return err
+}
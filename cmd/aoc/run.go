@@ -0,0 +1,398 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/usedbytes/aoc2021/debug"
+	"github.com/usedbytes/aoc2021/input"
+	"github.com/usedbytes/aoc2021/profile"
+	"github.com/usedbytes/aoc2021/progress"
+	"github.com/usedbytes/aoc2021/solver"
+)
+
+func defaultInput(day int) string {
+	return fmt.Sprintf("%02d/input.txt", day)
+}
+
+// result is the answer to one part (or option) of a day
+type result struct {
+	Day      int           `json:"day"`
+	Part     int           `json:"part,omitempty"`
+	Option   string        `json:"option,omitempty"`
+	Answer   string        `json:"answer"`
+	Duration time.Duration `json:"duration"`
+	InputSHA string        `json:"input_sha,omitempty"`
+	Error    string        `json:"error,omitempty"` // Only for run --all
+	// Progress is the last progress the solver reported, if any
+	Progress *progress.Snapshot `json:"progress,omitempty"`
+}
+
+func (r *result) label() string {
+	if r.Option != "" {
+		return r.Option
+	}
+
+	return fmt.Sprintf("Part %d", r.Part)
+}
+
+// printText prints 'r' to 'w' in the same format as the individual days.
+// Multi-line answers (like Day 13's letters) go on their own lines.
+func (r *result) printText(w io.Writer) {
+	if strings.Contains(r.Answer, "\n") {
+		fmt.Fprintln(w, r.label()+":")
+		fmt.Fprintln(w, r.Answer)
+	} else {
+		fmt.Fprintln(w, r.label()+":", r.Answer)
+	}
+}
+
+// printJSON prints 'r' to 'w' as one line of JSON
+func (r *result) printJSON(w io.Writer) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		// Can't happen, all of the fields are plain values
+		panic(err)
+	}
+
+	fmt.Fprintln(w, string(data))
+}
+
+// resultPrinter returns a function which prints results to 'w' in 'format'
+func resultPrinter(w io.Writer, format string) (func(r *result), error) {
+	switch format {
+	case "text":
+		return func(r *result) { r.printText(w) }, nil
+	case "json":
+		return func(r *result) { r.printJSON(w) }, nil
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// job is a day to solve, and how
+type job struct {
+	solver    *solver.Solver
+	inputFile string
+	// Part to run, or 0 for all of them
+	part int
+	// Don't run parts which are marked as slow
+	skipSlow bool
+	// Options to run instead of the parts, sorted by name
+	opts   []string
+	values map[string]int
+	// If not nil, progress is shown here while each part runs
+	progress io.Writer
+}
+
+func runCmd(args []string) error {
+	fs := flag.NewFlagSet("run", flag.ExitOnError)
+	day := fs.Int("day", 0, "day to run (1-25)")
+	part := fs.Int("part", 0, "part to run (1 or 2), or 0 for both")
+	inputFile := fs.String("input", "", "input file, or - for stdin (default NN/input.txt)")
+	format := fs.String("format", "text", "output format, text or json")
+	verbose := fs.Bool("v", false, "print debug output to stderr")
+	all := fs.Bool("all", false, "run every day, in parallel")
+	jobs := fs.Int("jobs", runtime.NumCPU(), "with --all, the number of days to run at once")
+	timeout := fs.Duration("timeout", 0, "time limit for each day, or 0 for none (default 1m with --all)")
+	slow := fs.Bool("slow", false, "with --all, also run the parts which take minutes")
+
+	var prof profile.Config
+	prof.AddFlags(fs)
+
+	// Every day's options are available as flags, but can only be used
+	// with that day
+	optValues := make(map[string]*int)
+	for _, s := range solver.All() {
+		for _, o := range s.Options {
+			if _, ok := optValues[o.Name]; ok {
+				continue
+			}
+			optValues[o.Name] = fs.Int(o.Name, 0, fmt.Sprintf("day %d: %s", s.Day, o.Usage))
+		}
+	}
+
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	emit, err := resultPrinter(os.Stdout, *format)
+	if err != nil {
+		return err
+	}
+
+	if *verbose {
+		debug.Output = os.Stderr
+	}
+
+	// Ctrl-C cancels whatever's running
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if *all {
+		var conflict error
+		fs.Visit(func(f *flag.Flag) {
+			if f.Name == "day" || f.Name == "input" || optValues[f.Name] != nil {
+				conflict = fmt.Errorf("--%s can't be used with --all", f.Name)
+			}
+		})
+		if conflict != nil {
+			return conflict
+		}
+
+		if prof.Enabled() {
+			return fmt.Errorf("profiling can't be used with --all")
+		}
+
+		if *part < 0 || *part > 2 {
+			return fmt.Errorf("invalid part %d", *part)
+		}
+
+		limit := *timeout
+		if !flagSet(fs, "timeout") {
+			limit = time.Minute
+		}
+
+		return runAll(ctx, solver.All(), *part, *jobs, limit, *slow, *format)
+	}
+
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	s := solver.Get(*day)
+	if s == nil {
+		return fmt.Errorf("no solver for day %d", *day)
+	}
+
+	if *part < 0 || *part > len(s.Parts) {
+		return fmt.Errorf("invalid part %d", *part)
+	}
+
+	var opts []string
+	var optErr error
+	fs.Visit(func(f *flag.Flag) {
+		if _, ok := optValues[f.Name]; !ok {
+			return
+		}
+
+		if s.Option(f.Name) == nil {
+			optErr = fmt.Errorf("--%s isn't an option for day %d", f.Name, s.Day)
+		}
+		opts = append(opts, f.Name)
+	})
+	if optErr != nil {
+		return optErr
+	}
+	sort.Strings(opts)
+
+	if *inputFile == "" {
+		*inputFile = defaultInput(s.Day)
+	}
+
+	j := job{
+		solver:    s,
+		inputFile: *inputFile,
+		part:      *part,
+		opts:      opts,
+		values:    make(map[string]int),
+		progress:  os.Stderr,
+	}
+	for _, name := range opts {
+		j.values[name] = *optValues[name]
+	}
+
+	// Either profile everything at once, or each stage separately
+	stage := func(name string, f func() error) error {
+		if prof.PerPart {
+			return withProfile(&prof, name, f)
+		}
+		return f()
+	}
+	if !prof.PerPart {
+		return withProfile(&prof, "", func() error {
+			return solve(ctx, j, stage, emit)
+		})
+	}
+
+	return solve(ctx, j, stage, emit)
+}
+
+// flagSet returns true if the flag called 'name' was given
+func flagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+
+	return set
+}
+
+// Runs 'f' with the profiles in 'c' running, with 'suffix' added to their
+// filenames
+func withProfile(c *profile.Config, suffix string, f func() error) error {
+	if !c.Enabled() {
+		return f()
+	}
+
+	p, err := c.Start(suffix)
+	if err != nil {
+		return err
+	}
+
+	err = f()
+	if perr := p.Stop(); err == nil {
+		err = perr
+	}
+
+	return err
+}
+
+func readInput(filename string) ([]byte, error) {
+	f, err := input.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return io.ReadAll(f)
+}
+
+// solve runs 'j', calling 'emit' with each answer. Each stage (parse, part1,
+// part2 or an option) is run by 'stage', so it can be wrapped. If 'ctx' is
+// cancelled, solve gives up and returns its error.
+func solve(ctx context.Context, j job, stage func(name string, f func() error) error, emit func(r *result)) error {
+	s := j.solver
+
+	data, err := readInput(j.inputFile)
+	if err != nil {
+		return err
+	}
+	sha := fmt.Sprintf("%x", sha256.Sum256(data))
+
+	var in interface{}
+	if err := stage("parse", func() error {
+		return solver.WithContext(ctx, func() error {
+			var err error
+			in, err = s.Parse(bytes.NewReader(data))
+			return err
+		})
+	}); err != nil {
+		return fmt.Errorf("parsing %s: %w", j.inputFile, err)
+	}
+
+	// Runs one part or option, and emits its result
+	do := func(name string, r *result, f func(ctx context.Context) (interface{}, error)) error {
+		return stage(name, func() error {
+			tracker := progress.NewTracker()
+			ctx := progress.With(ctx, tracker)
+
+			stop := func() {}
+			if j.progress != nil {
+				stop = progress.Show(j.progress, r.label()+": ", tracker)
+			}
+
+			start := time.Now()
+
+			var answer interface{}
+			err := solver.WithContext(ctx, func() error {
+				var err error
+				answer, err = f(ctx)
+				return err
+			})
+			stop()
+			if err != nil {
+				return err
+			}
+
+			r.Progress = tracker.Snapshot()
+
+			r.Day = s.Day
+			r.Answer = fmt.Sprint(answer)
+			r.Duration = time.Since(start)
+			r.InputSHA = sha
+			emit(r)
+
+			return nil
+		})
+	}
+
+	// Options replace the normal parts
+	if len(j.opts) > 0 {
+		for _, name := range j.opts {
+			o := s.Option(name)
+			value := j.values[name]
+
+			r := &result{Option: fmt.Sprintf("%s=%d", name, value)}
+			if err := do(name, r, func(ctx context.Context) (interface{}, error) {
+				return o.Run(ctx, in, value)
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for n := 1; n <= len(s.Parts); n++ {
+		if j.part != 0 && j.part != n {
+			continue
+		}
+
+		if j.skipSlow && s.IsSlow(n) {
+			continue
+		}
+
+		p := s.Part(n)
+		if p == nil {
+			if j.part == n {
+				return fmt.Errorf("day %d has no part %d", s.Day, n)
+			}
+			continue
+		}
+
+		r := &result{Part: n}
+		if err := do(fmt.Sprintf("part%d", n), r, func(ctx context.Context) (interface{}, error) {
+			return p(ctx, in)
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func listCmd(args []string) error {
+	for _, s := range solver.All() {
+		parts := []string{}
+		for n := 1; n <= len(s.Parts); n++ {
+			if s.Part(n) != nil {
+				parts = append(parts, fmt.Sprint(n))
+			}
+		}
+
+		fmt.Printf("Day %2d: parts %s\n", s.Day, strings.Join(parts, ", "))
+		for _, o := range s.Options {
+			fmt.Printf("        --%s N: %s\n", o.Name, o.Usage)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/usedbytes/aoc2021/input"
+	"github.com/usedbytes/aoc2021/progress"
+	"github.com/usedbytes/aoc2021/solver"
+)
+
+// A fake day, which sums the numbers in its input for part 1, and fails
+// part 2
+var summer = &solver.Solver{
+	Day: 1,
+	Parse: func(rd io.Reader) (interface{}, error) {
+		return input.Ints(rd)
+	},
+	Parts: [2]solver.Part{
+		func(_ context.Context, in interface{}) (interface{}, error) {
+			sum := 0
+			for _, v := range in.([]int) {
+				sum += v
+			}
+			return sum, nil
+		},
+		func(_ context.Context, _ interface{}) (interface{}, error) {
+			return nil, fmt.Errorf("no part 2")
+		},
+	},
+}
+
+// Writes 'data' to a file in a temporary directory, and returns its name
+func writeInput(t *testing.T, data string) string {
+	t.Helper()
+
+	filename := filepath.Join(t.TempDir(), "input.txt")
+	if err := os.WriteFile(filename, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return filename
+}
+
+// Returns the keys of the JSON object in 'line'
+func jsonKeys(t *testing.T, line string) []string {
+	t.Helper()
+
+	var obj map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &obj); err != nil {
+		t.Fatalf("%q isn't a JSON object: %v", line, err)
+	}
+
+	var keys []string
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func TestPrintJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		r    result
+		keys []string
+	}{
+		{
+			"part",
+			result{Day: 1, Part: 2, Answer: "5", Duration: time.Millisecond, InputSHA: "abc"},
+			[]string{"answer", "day", "duration", "input_sha", "part"},
+		},
+		{
+			"option",
+			result{Day: 6, Option: "days=18", Answer: "26"},
+			[]string{"answer", "day", "duration", "option"},
+		},
+		{
+			"error",
+			result{Day: 3, Error: "oops"},
+			[]string{"answer", "day", "duration", "error"},
+		},
+		{
+			"progress",
+			result{Day: 19, Part: 1, Answer: "79", Progress: &progress.Snapshot{Phase: "scanners located", Done: 5, Total: 5}},
+			[]string{"answer", "day", "duration", "part", "progress"},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tc.r.printJSON(&buf)
+
+			out := buf.String()
+			if strings.Count(out, "\n") != 1 || !strings.HasSuffix(out, "\n") {
+				t.Fatalf("want one line, got %q", out)
+			}
+
+			if keys := jsonKeys(t, out); !reflect.DeepEqual(keys, tc.keys) {
+				t.Errorf("got keys %v, want %v", keys, tc.keys)
+			}
+
+			var got result
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tc.r) {
+				t.Errorf("got %+v back, want %+v", got, tc.r)
+			}
+		})
+	}
+}
+
+func TestPrintText(t *testing.T) {
+	var buf bytes.Buffer
+	emit, err := resultPrinter(&buf, "text")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	emit(&result{Part: 1, Answer: "7"})
+	emit(&result{Part: 2, Answer: "#.\n.#"})
+	emit(&result{Option: "days=18", Answer: "26"})
+
+	want := "Part 1: 7\nPart 2:\n#.\n.#\ndays=18: 26\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err := resultPrinter(&buf, "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestSolveJSON(t *testing.T) {
+	data := "1\n2\n3\n"
+	j := job{
+		solver:    summer,
+		inputFile: writeInput(t, data),
+		part:      1,
+	}
+
+	var buf bytes.Buffer
+	emit, err := resultPrinter(&buf, "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := solve(context.Background(), j, func(name string, f func() error) error {
+		return f()
+	}, emit); err != nil {
+		t.Fatal(err)
+	}
+
+	var r result
+	if err := json.Unmarshal(buf.Bytes(), &r); err != nil {
+		t.Fatalf("%q: %v", buf.String(), err)
+	}
+
+	if r.Day != 1 || r.Part != 1 || r.Answer != "6" {
+		t.Errorf("got day %d part %d answer %q, want day 1 part 1 answer 6", r.Day, r.Part, r.Answer)
+	}
+
+	if want := fmt.Sprintf("%x", sha256.Sum256([]byte(data))); r.InputSHA != want {
+		t.Errorf("input_sha is %q, want %q", r.InputSHA, want)
+	}
+
+	// The fake doesn't report its progress
+	if r.Progress != nil {
+		t.Errorf("unexpected progress %+v", r.Progress)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"runtime"
+	"time"
+
+	"github.com/usedbytes/aoc2021/server"
+)
+
+func serveCmd(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", "localhost:8021", "address to listen on")
+	maxInput := fs.Int64("max-input", server.DefaultMaxInput, "largest input accepted, in bytes")
+	timeout := fs.Duration("timeout", server.DefaultTimeout, "time limit for each request")
+	maxSolves := fs.Int("max-solves", runtime.NumCPU(), "most requests to solve at once, any more get a 503")
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	srv := &http.Server{
+		Addr: *addr,
+		Handler: &server.Server{
+			MaxInput:  *maxInput,
+			Timeout:   *timeout,
+			MaxSolves: *maxSolves,
+		},
+		// Leave time to upload the input, and to write the answer once
+		// it's found
+		ReadTimeout:  30 * time.Second,
+		WriteTimeout: *timeout + 30*time.Second,
+	}
+
+	// Ctrl-C lets the requests in progress finish, then exits
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+
+	fmt.Fprintf(os.Stderr, "Listening on http://%s/days/{n}/parts/{p}\n", *addr)
+
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+
+	fmt.Fprintln(os.Stderr, "Shutting down")
+	if err := srv.Shutdown(context.Background()); err != nil {
+		return err
+	}
+
+	if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
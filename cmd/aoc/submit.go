@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/usedbytes/aoc2021/client"
+	"github.com/usedbytes/aoc2021/solver"
+)
+
+// Runs the solver for one part, and returns its answer
+func computeAnswer(s *solver.Solver, inputFile string, part int) (string, error) {
+	j := job{
+		solver:    s,
+		inputFile: inputFile,
+		part:      part,
+	}
+
+	var answer string
+	err := solve(context.Background(), j, func(name string, f func() error) error {
+		return f()
+	}, func(r *result) {
+		answer = r.Answer
+	})
+
+	return answer, err
+}
+
+func submitCmd(args []string) error {
+	fs := flag.NewFlagSet("submit", flag.ExitOnError)
+	day := fs.Int("day", 0, "day to submit (1-25)")
+	part := fs.Int("part", 0, "part to submit (1 or 2)")
+	answer := fs.String("answer", "", "answer to submit (default: run the solver)")
+	inputFile := fs.String("input", "", "input file to solve, if --answer isn't given (default NN/input.txt)")
+	fs.Parse(args)
+
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	if *day < 1 || *day > 25 {
+		return fmt.Errorf("invalid day %d", *day)
+	}
+
+	if *part != 1 && *part != 2 {
+		return fmt.Errorf("invalid part %d", *part)
+	}
+
+	if *answer == "" {
+		s := solver.Get(*day)
+		if s == nil {
+			return fmt.Errorf("no solver for day %d", *day)
+		}
+
+		if s.Part(*part) == nil {
+			return fmt.Errorf("day %d has no part %d", *day, *part)
+		}
+
+		if *inputFile == "" {
+			*inputFile = defaultInput(*day)
+		}
+
+		var err error
+		*answer, err = computeAnswer(s, *inputFile, *part)
+		if err != nil {
+			return err
+		}
+	}
+
+	session, err := client.LoadSession()
+	if err != nil {
+		return err
+	}
+
+	cache, err := client.DefaultCache(session)
+	if err != nil {
+		return err
+	}
+
+	history, err := client.LoadHistory(cache.HistoryPath())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Submitting %s for day %d part %d\n", *answer, *day, *part)
+
+	resp, err := client.Submit(client.New(session), history, *day, *part, *answer)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(resp.Message)
+
+	switch resp.Result {
+	case client.Correct:
+		fmt.Println("Correct!")
+	case client.TooHigh:
+		fmt.Println("Wrong, too high")
+	case client.TooLow:
+		fmt.Println("Wrong, too low")
+	case client.Wrong:
+		fmt.Println("Wrong")
+	case client.Wait:
+		fmt.Printf("Not checked, wait %v\n", resp.Wait)
+	case client.WrongLevel:
+		fmt.Println("Wrong level: already solved, or part 1 isn't solved yet")
+	default:
+		fmt.Println("Couldn't understand the response")
+	}
+
+	return nil
+}
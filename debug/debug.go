@@ -0,0 +1,28 @@
+// Package debug is for the solvers' diagnostic output, which is discarded
+// unless a command enables it. It's kept separate from the answers, so that
+// they can be parsed by scripts.
+package debug
+
+import (
+	"fmt"
+	"io"
+)
+
+// Output is where debug output goes. It's discarded by default.
+var Output io.Writer = io.Discard
+
+// Enabled returns true if debug output isn't being discarded, for when it's
+// expensive to produce
+func Enabled() bool {
+	return Output != io.Discard
+}
+
+// Printf writes formatted debug output
+func Printf(format string, a ...interface{}) {
+	fmt.Fprintf(Output, format, a...)
+}
+
+// Println writes a line of debug output
+func Println(a ...interface{}) {
+	fmt.Fprintln(Output, a...)
+}
@@ -0,0 +1,3 @@
+module github.com/usedbytes/aoc2021
+
+go 1.18
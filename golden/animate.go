@@ -0,0 +1,64 @@
+package golden
+
+import (
+	"testing"
+
+	"github.com/usedbytes/aoc2021/solver"
+)
+
+// frames is a screen which just keeps the frames
+type frames []string
+
+func (f *frames) Frame(text string) error {
+	*f = append(*f, text)
+	return nil
+}
+
+// Animated checks that the day's animation of each of its input files runs,
+// and shows more than one frame, without waiting between them, then calls
+// 'check' to look at the frames. input.txt is skipped with -short.
+func Animated(t *testing.T, day int, check func(t *testing.T, c Case, frames []string)) {
+	s := solver.Get(day)
+	if s == nil {
+		t.Fatalf("day %d isn't registered", day)
+	}
+
+	if s.Animation == nil {
+		t.Fatalf("day %d can't be animated", day)
+	}
+
+	files, err := Files()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	answers, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		file := file
+		t.Run(file, func(t *testing.T) {
+			if file == "input.txt" && testing.Short() {
+				t.Skip("skipping input.txt in short mode")
+			}
+
+			in, err := parseFile(s, file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var f frames
+			if err := s.Animation.Animate(&f, in); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(f) < 2 {
+				t.Fatalf("only got %d frames: %q", len(f), f)
+			}
+
+			check(t, Case{File: file, In: in, Answers: answers[file]}, f)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package golden
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/usedbytes/aoc2021/solver"
+)
+
+// Benchmark runs sub-benchmarks for parsing and each part of 'day', on the
+// day's input.txt. Like Test, it's expected to be called from the day's
+// package. Cases marked as slow are skipped unless -slow is given, and
+// cases marked with Skip are always skipped.
+//
+// Each part gets a freshly parsed input on every iteration, in case it
+// modifies it. The parsing isn't included in the part's time.
+func Benchmark(b *testing.B, day int, opts ...Option) {
+	c := newConfig(opts)
+
+	s := solver.Get(day)
+	if s == nil {
+		b.Fatalf("day %d isn't registered", day)
+	}
+
+	data, err := os.ReadFile("input.txt")
+	if err != nil {
+		b.Skip(err)
+	}
+
+	parse := func(b *testing.B) interface{} {
+		in, err := s.Parse(bytes.NewReader(data))
+		if err != nil {
+			b.Fatal(err)
+		}
+		return in
+	}
+
+	b.Run("parse", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			parse(b)
+		}
+	})
+
+	for n := 1; n <= len(s.Parts); n++ {
+		part := s.Part(n)
+		if part == nil {
+			continue
+		}
+
+		name := caseName("input.txt", n)
+		b.Run(fmt.Sprintf("part%d", n), func(b *testing.B) {
+			c.skipCase(b, name)
+
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				in := parse(b)
+				b.StartTimer()
+
+				if _, err := part(context.Background(), in); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package golden
+
+import (
+	"bytes"
+	"image"
+	"image/gif"
+	"image/png"
+	"testing"
+
+	"github.com/usedbytes/aoc2021/solver"
+)
+
+// Case is one of a day's input files, parsed, with its golden answers
+type Case struct {
+	File string
+	In   interface{}
+	// Answers are the golden answers for File, by part ("1" or "2")
+	Answers map[string]string
+}
+
+// Count returns the number of pixels in 'img' which are colour 'c' of its
+// palette
+func Count(img *image.Paletted, c uint8) int {
+	n := 0
+	for _, p := range img.Pix {
+		if p == c {
+			n++
+		}
+	}
+
+	return n
+}
+
+// Decodes a drawing in 'format' into its frames
+func decodeFrames(data []byte, format string) ([]*image.Paletted, error) {
+	if format == "gif" {
+		g, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		return g.Image, nil
+	}
+
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	p, ok := img.(*image.Paletted)
+	if !ok {
+		return nil, image.ErrFormat
+	}
+
+	return []*image.Paletted{p}, nil
+}
+
+// Drawn checks that the day's drawing of each of its input files can be
+// decoded, in the format it says it is, then calls 'check' to look at what's
+// in it. Each one is drawn with a scale of 1, so every cell is one pixel,
+// and a PNG has just one frame. input.txt is skipped with -short.
+func Drawn(t *testing.T, day int, check func(t *testing.T, c Case, frames []*image.Paletted)) {
+	s := solver.Get(day)
+	if s == nil {
+		t.Fatalf("day %d isn't registered", day)
+	}
+
+	if s.Drawing == nil {
+		t.Fatalf("day %d can't be drawn", day)
+	}
+
+	files, err := Files()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	answers, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		file := file
+		t.Run(file, func(t *testing.T) {
+			if file == "input.txt" && testing.Short() {
+				t.Skip("skipping input.txt in short mode")
+			}
+
+			in, err := parseFile(s, file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var buf bytes.Buffer
+			if err := s.Drawing.Draw(&buf, in, 1); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, format, err := image.DecodeConfig(bytes.NewReader(buf.Bytes()))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if format != s.Drawing.Format {
+				t.Fatalf("drew a %s, want a %s", format, s.Drawing.Format)
+			}
+
+			if cfg.Width == 0 || cfg.Height == 0 {
+				t.Fatalf("drew an empty %dx%d image", cfg.Width, cfg.Height)
+			}
+
+			frames, err := decodeFrames(buf.Bytes(), format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			check(t, Case{File: file, In: in, Answers: answers[file]}, frames)
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package golden
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/usedbytes/aoc2021/input"
+	"github.com/usedbytes/aoc2021/solver"
+)
+
+// BadInput is an input which shouldn't parse, and where the error should be.
+// Line and Column are 1-based, or 0 if the error shouldn't have one.
+type BadInput struct {
+	Name   string
+	Input  string
+	Line   int
+	Column int
+}
+
+// ParseErrors checks that each of 'inputs' fails to parse with an
+// input.ParseError at the right position
+func ParseErrors(t *testing.T, day int, inputs []BadInput) {
+	s := solver.Get(day)
+	if s == nil {
+		t.Fatalf("day %d isn't registered", day)
+	}
+
+	for _, in := range inputs {
+		in := in
+		t.Run(in.Name, func(t *testing.T) {
+			_, err := s.Parse(strings.NewReader(in.Input))
+
+			var pe *input.ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("expected a ParseError, got %v", err)
+			}
+
+			if pe.Line != in.Line || pe.Column != in.Column {
+				t.Errorf("got error at %d:%d, want %d:%d (%v)", pe.Line, pe.Column, in.Line, in.Column, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package golden
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/usedbytes/aoc2021/solver"
+)
+
+// Generated checks that 'count' inputs from the day's generator, of the
+// given size, are the same each time for the same seed, parse, and can be
+// solved without an error. The seeds are 1 to 'count', so a failure can be
+// reproduced with "aoc gen".
+func Generated(t *testing.T, day, size, count int) {
+	s := solver.Get(day)
+	if s == nil {
+		t.Fatalf("day %d isn't registered", day)
+	}
+
+	if s.Generate == nil {
+		t.Fatalf("day %d has no generator", day)
+	}
+
+	generate := func(t *testing.T, seed int64) []byte {
+		var buf bytes.Buffer
+		if err := s.Generate(&buf, rand.New(rand.NewSource(seed)), size); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	for seed := int64(1); seed <= int64(count); seed++ {
+		seed := seed
+		t.Run(fmt.Sprintf("seed%d", seed), func(t *testing.T) {
+			data := generate(t, seed)
+			if !bytes.Equal(data, generate(t, seed)) {
+				t.Fatal("generated a different input the second time")
+			}
+
+			for n := 1; n <= len(s.Parts); n++ {
+				part := s.Part(n)
+				if part == nil {
+					continue
+				}
+
+				// Parts might modify their input, so parse it
+				// again for each one
+				in, err := s.Parse(bytes.NewReader(data))
+				if err != nil {
+					t.Fatalf("generated input doesn't parse: %v\n%s", err, data)
+				}
+
+				if _, err := part(context.Background(), in); err != nil {
+					t.Errorf("part %d: %v", n, err)
+				}
+			}
+		})
+	}
+}
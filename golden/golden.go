@@ -0,0 +1,214 @@
+// Package golden is a test harness which checks a day's answers against
+// recorded "golden" answers, for the day's input.txt and for each example
+// input in its testdata directory.
+//
+// The answers are stored in testdata/golden.json. To record new answers, run
+// the day's tests with -update, and check the diff:
+//
+//	go test ./15 -update
+//
+// Cases marked as slow are skipped unless -slow is given, cases marked with
+// Skip are always skipped, and input.txt is skipped with -short.
+//
+// Benchmark does the same for the time taken to parse and solve input.txt:
+//
+//	go test ./15 -run NONE -bench .
+package golden
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/usedbytes/aoc2021/input"
+	"github.com/usedbytes/aoc2021/solver"
+)
+
+var update = flag.Bool("update", false, "record new golden answers")
+var slow = flag.Bool("slow", false, "run cases which are marked as slow")
+
+const goldenFile = "testdata/golden.json"
+
+// Answers maps from input file, to part, to answer
+type Answers map[string]map[string]string
+
+// Option modifies how a day is tested
+type Option func(c *config)
+
+type config struct {
+	slow map[string]bool
+}
+
+func newConfig(opts []Option) config {
+	c := config{
+		slow: make(map[string]bool),
+	}
+	for _, o := range opts {
+		o(&c)
+	}
+
+	return c
+}
+
+func caseName(file string, part int) string {
+	return fmt.Sprintf("%s/part%d", file, part)
+}
+
+// Slow marks 'part' on 'file' as slow, so it is only run (or benchmarked)
+// with -slow
+func Slow(file string, part int) Option {
+	return func(c *config) {
+		c.slow[caseName(file, part)] = true
+	}
+}
+
+// skipCase skips 'name' if it's slow and -slow wasn't given
+func (c *config) skipCase(tb testing.TB, name string) {
+	tb.Helper()
+
+	if c.slow[name] && !*slow {
+		tb.Skip("slow, run with -slow")
+	}
+}
+
+// Files returns the input files for a day: input.txt (if present) and the
+// examples in testdata
+func Files() ([]string, error) {
+	var files []string
+
+	if _, err := os.Stat("input.txt"); err == nil {
+		files = append(files, "input.txt")
+	}
+
+	examples, err := filepath.Glob("testdata/*.txt")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(examples)
+
+	return append(files, examples...), nil
+}
+
+// Load reads the golden answers for the day in the current directory
+func Load() (Answers, error) {
+	answers := make(Answers)
+
+	data, err := os.ReadFile(goldenFile)
+	if os.IsNotExist(err) {
+		return answers, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &answers); err != nil {
+		return nil, fmt.Errorf("%s: %w", goldenFile, err)
+	}
+
+	return answers, nil
+}
+
+func save(answers Answers) error {
+	data, err := json.MarshalIndent(answers, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(goldenFile), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(goldenFile, append(data, '\n'), 0644)
+}
+
+func parseFile(s *solver.Solver, file string) (interface{}, error) {
+	f, err := input.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return s.Parse(f)
+}
+
+// Test runs all of the parts of 'day' against all of its input files.
+// It's expected to be called from the day's package, so that the paths are
+// relative to the day's directory.
+func Test(t *testing.T, day int, opts ...Option) {
+	c := newConfig(opts)
+
+	s := solver.Get(day)
+	if s == nil {
+		t.Fatalf("day %d isn't registered", day)
+	}
+
+	files, err := Files()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	answers, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range files {
+		file := file
+		t.Run(file, func(t *testing.T) {
+			if file == "input.txt" && testing.Short() {
+				t.Skip("skipping input.txt in short mode")
+			}
+
+			in, err := parseFile(s, file)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for n := 1; n <= len(s.Parts); n++ {
+				part := s.Part(n)
+				if part == nil {
+					continue
+				}
+
+				name := caseName(file, n)
+				key := fmt.Sprint(n)
+				t.Run(fmt.Sprintf("part%d", n), func(t *testing.T) {
+					c.skipCase(t, name)
+
+					answer, err := part(context.Background(), in)
+					if err != nil {
+						t.Fatal(err)
+					}
+					got := fmt.Sprint(answer)
+
+					if *update {
+						if answers[file] == nil {
+							answers[file] = make(map[string]string)
+						}
+						answers[file][key] = got
+						return
+					}
+
+					want, ok := answers[file][key]
+					if !ok {
+						t.Fatalf("no golden answer, got %q. Run with -update to record it", got)
+					}
+
+					if got != want {
+						t.Errorf("got %q, want %q", got, want)
+					}
+				})
+			}
+		})
+	}
+
+	if *update {
+		if err := save(answers); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
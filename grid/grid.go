@@ -0,0 +1,366 @@
+// Package grid is a 2D grid of values, for the puzzles which have a map or
+// an image.
+//
+// A Grid has bounds, and a Mode which decides what happens outside of them:
+// Bounded grids just return the background value, Wrap grids wrap around
+// (like a torus), and Infinite grids extend forever, filled with the
+// background value.
+//
+// Grids are either dense (a slice, good for small, full grids) or sparse (a
+// map, which only stores points which have changed from the value the grid
+// was created with).
+package grid
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Point struct {
+	X, Y int
+}
+
+func (p Point) Add(q Point) Point {
+	return Point{p.X + q.X, p.Y + q.Y}
+}
+
+// Dirs4 are the offsets to the four orthogonal neighbours
+var Dirs4 = []Point{
+	{0, -1},
+	{-1, 0},
+	{1, 0},
+	{0, 1},
+}
+
+// Dirs8 are the offsets to all eight neighbours, including diagonals
+var Dirs8 = []Point{
+	{-1, -1},
+	{0, -1},
+	{1, -1},
+	{-1, 0},
+	{1, 0},
+	{-1, 1},
+	{0, 1},
+	{1, 1},
+}
+
+type Mode int
+
+const (
+	// Outside the bounds is the background value, and has no neighbours
+	Bounded Mode = iota
+	// Points outside the bounds wrap around to the other side
+	Wrap
+	// The grid extends forever, outside the bounds is the background value
+	Infinite
+)
+
+type Grid[T comparable] struct {
+	Mode Mode
+	// Background is the value outside of the bounds
+	Background T
+
+	min, max Point
+	// The value every point starts with. Sparse grids only store points
+	// with a different value.
+	fill T
+
+	// Exactly one of these is used
+	dense  []T
+	sparse map[Point]T
+}
+
+func newGrid[T comparable](min, max Point, fill T, sparse bool) *Grid[T] {
+	if max.X < min.X || max.Y < min.Y {
+		panic(fmt.Sprintf("invalid bounds %v - %v", min, max))
+	}
+
+	g := &Grid[T]{
+		Background: fill,
+		min:        min,
+		max:        max,
+		fill:       fill,
+	}
+
+	if sparse {
+		g.sparse = make(map[Point]T)
+	} else {
+		g.dense = make([]T, g.Width()*g.Height())
+		for i := range g.dense {
+			g.dense[i] = fill
+		}
+	}
+
+	return g
+}
+
+// NewDense returns a 'width' x 'height' grid stored as a slice, with every
+// point (and the background) set to 'fill'
+func NewDense[T comparable](width, height int, fill T) *Grid[T] {
+	return newGrid(Point{0, 0}, Point{width - 1, height - 1}, fill, false)
+}
+
+// NewSparse returns a 'width' x 'height' grid stored as a map, with every
+// point (and the background) set to 'fill'. Only points with a different
+// value take up any space.
+func NewSparse[T comparable](width, height int, fill T) *Grid[T] {
+	return newGrid(Point{0, 0}, Point{width - 1, height - 1}, fill, true)
+}
+
+// Sparse returns true if the grid is stored as a map
+func (g *Grid[T]) Sparse() bool {
+	return g.sparse != nil
+}
+
+// Bounds returns the (inclusive) minimum and maximum points of the grid
+func (g *Grid[T]) Bounds() (min, max Point) {
+	return g.min, g.max
+}
+
+func (g *Grid[T]) Width() int {
+	return g.max.X - g.min.X + 1
+}
+
+func (g *Grid[T]) Height() int {
+	return g.max.Y - g.min.Y + 1
+}
+
+// In returns true if 'p' is within the bounds
+func (g *Grid[T]) In(p Point) bool {
+	return p.X >= g.min.X && p.X <= g.max.X && p.Y >= g.min.Y && p.Y <= g.max.Y
+}
+
+func mod(a, b int) int {
+	return ((a % b) + b) % b
+}
+
+// Canon returns where 'p' is in the grid: wrapped into the bounds for Wrap
+// grids, or just 'p' for the others
+func (g *Grid[T]) Canon(p Point) Point {
+	if g.Mode != Wrap {
+		return p
+	}
+
+	return Point{
+		g.min.X + mod(p.X-g.min.X, g.Width()),
+		g.min.Y + mod(p.Y-g.min.Y, g.Height()),
+	}
+}
+
+func (g *Grid[T]) index(p Point) int {
+	return (p.Y-g.min.Y)*g.Width() + (p.X - g.min.X)
+}
+
+// Get returns the value at 'p', which is the background value outside of the
+// bounds (unless the grid wraps)
+func (g *Grid[T]) Get(p Point) T {
+	p = g.Canon(p)
+	if !g.In(p) {
+		return g.Background
+	}
+
+	if g.sparse != nil {
+		if v, ok := g.sparse[p]; ok {
+			return v
+		}
+		return g.fill
+	}
+
+	return g.dense[g.index(p)]
+}
+
+// Set sets the value at 'p'. Infinite grids grow to include 'p' if it's
+// outside the bounds, which copies the whole grid, so it's best to Grow them
+// first. Bounded grids panic if 'p' is outside the bounds.
+func (g *Grid[T]) Set(p Point, v T) {
+	p = g.Canon(p)
+	if !g.In(p) {
+		if g.Mode != Infinite {
+			panic(fmt.Sprintf("%v is outside the grid %v - %v", p, g.min, g.max))
+		}
+		g.extend(p)
+	}
+
+	if g.sparse != nil {
+		if v == g.fill {
+			delete(g.sparse, p)
+		} else {
+			g.sparse[p] = v
+		}
+		return
+	}
+
+	g.dense[g.index(p)] = v
+}
+
+// Grows the bounds to include 'p', in place
+func (g *Grid[T]) extend(p Point) {
+	min, max := g.min, g.max
+	if p.X < min.X {
+		min.X = p.X
+	} else if p.X > max.X {
+		max.X = p.X
+	}
+	if p.Y < min.Y {
+		min.Y = p.Y
+	} else if p.Y > max.Y {
+		max.Y = p.Y
+	}
+
+	*g = *g.Resize(min, max)
+}
+
+// Each calls 'fn' for every point within the bounds, in row order
+func (g *Grid[T]) Each(fn func(p Point, v T)) {
+	for y := g.min.Y; y <= g.max.Y; y++ {
+		for x := g.min.X; x <= g.max.X; x++ {
+			p := Point{x, y}
+			fn(p, g.Get(p))
+		}
+	}
+}
+
+// EachSet calls 'fn' for every point which doesn't have the value the grid
+// was created with, in no particular order. It's much faster than Each for
+// sparse grids.
+func (g *Grid[T]) EachSet(fn func(p Point, v T)) {
+	if g.sparse != nil {
+		for p, v := range g.sparse {
+			fn(p, v)
+		}
+		return
+	}
+
+	g.Each(func(p Point, v T) {
+		if v != g.fill {
+			fn(p, v)
+		}
+	})
+}
+
+// Count returns the number of points within the bounds with value 'v'
+func (g *Grid[T]) Count(v T) int {
+	n := 0
+	if g.sparse != nil && v != g.fill {
+		for _, sv := range g.sparse {
+			if sv == v {
+				n++
+			}
+		}
+		return n
+	}
+
+	g.Each(func(p Point, pv T) {
+		if pv == v {
+			n++
+		}
+	})
+
+	return n
+}
+
+func (g *Grid[T]) neighbours(p Point, dirs []Point, fn func(p Point, v T)) {
+	for _, d := range dirs {
+		np := g.Canon(p.Add(d))
+		if g.Mode == Bounded && !g.In(np) {
+			continue
+		}
+
+		fn(np, g.Get(np))
+	}
+}
+
+// Neighbours4 calls 'fn' for each of the orthogonal neighbours of 'p'.
+// Bounded grids skip neighbours outside the bounds, and Wrap grids give the
+// wrapped point.
+func (g *Grid[T]) Neighbours4(p Point, fn func(p Point, v T)) {
+	g.neighbours(p, Dirs4, fn)
+}
+
+// Neighbours8 is like Neighbours4, but includes diagonals
+func (g *Grid[T]) Neighbours8(p Point, fn func(p Point, v T)) {
+	g.neighbours(p, Dirs8, fn)
+}
+
+// Clone returns a copy of the grid
+func (g *Grid[T]) Clone() *Grid[T] {
+	c := *g
+
+	if g.sparse != nil {
+		c.sparse = make(map[Point]T, len(g.sparse))
+		for p, v := range g.sparse {
+			c.sparse[p] = v
+		}
+	} else {
+		c.dense = make([]T, len(g.dense))
+		copy(c.dense, g.dense)
+	}
+
+	return &c
+}
+
+// AsSparse returns a sparse copy of the grid
+func (g *Grid[T]) AsSparse() *Grid[T] {
+	s := newGrid(g.min, g.max, g.fill, true)
+	s.Mode = g.Mode
+	s.Background = g.Background
+
+	g.EachSet(s.Set)
+
+	return s
+}
+
+// Resize returns a copy of the grid with new bounds. Points which are in the
+// new bounds keep their value, and new points get the background value.
+func (g *Grid[T]) Resize(min, max Point) *Grid[T] {
+	n := newGrid(min, max, g.fill, g.sparse != nil)
+	n.Mode = g.Mode
+	n.Background = g.Background
+
+	g.EachSet(func(p Point, v T) {
+		if n.In(p) {
+			n.Set(p, v)
+		}
+	})
+
+	if g.Background != g.fill {
+		n.Each(func(p Point, v T) {
+			if !g.In(p) {
+				n.Set(p, g.Background)
+			}
+		})
+	}
+
+	return n
+}
+
+// Grow returns a copy of the grid with its bounds extended by 'n' on all
+// sides
+func (g *Grid[T]) Grow(n int) *Grid[T] {
+	return g.Resize(Point{g.min.X - n, g.min.Y - n}, Point{g.max.X + n, g.max.Y + n})
+}
+
+// Format returns the grid as text, one line per row, with each value
+// converted by 'fn'
+func (g *Grid[T]) Format(fn func(v T) string) string {
+	var sb strings.Builder
+
+	for y := g.min.Y; y <= g.max.Y; y++ {
+		if y != g.min.Y {
+			sb.WriteByte('\n')
+		}
+		for x := g.min.X; x <= g.max.X; x++ {
+			sb.WriteString(fn(g.Get(Point{x, y})))
+		}
+	}
+
+	return sb.String()
+}
+
+// String formats each value with fmt.Sprint, which works well for grids of
+// digits
+func (g *Grid[T]) String() string {
+	return g.Format(func(v T) string {
+		return fmt.Sprint(v)
+	})
+}
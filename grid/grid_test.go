@@ -0,0 +1,249 @@
+package grid
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/usedbytes/aoc2021/input"
+)
+
+// Runs 'fn' with a dense and a sparse grid
+func backends(t *testing.T, fn func(t *testing.T, g *Grid[int])) {
+	t.Run("dense", func(t *testing.T) {
+		fn(t, NewDense(4, 3, 0))
+	})
+	t.Run("sparse", func(t *testing.T) {
+		fn(t, NewSparse(4, 3, 0))
+	})
+}
+
+func TestGetSet(t *testing.T) {
+	backends(t, func(t *testing.T, g *Grid[int]) {
+		g.Set(Point{1, 2}, 5)
+		g.Set(Point{3, 0}, 7)
+
+		if v := g.Get(Point{1, 2}); v != 5 {
+			t.Errorf("got %d, want 5", v)
+		}
+
+		if v := g.Get(Point{2, 2}); v != 0 {
+			t.Errorf("got %d, want 0", v)
+		}
+
+		n := 0
+		g.EachSet(func(p Point, v int) {
+			n++
+		})
+		if n != 2 {
+			t.Errorf("got %d set points, want 2", n)
+		}
+
+		if n := g.Count(7); n != 1 {
+			t.Errorf("got count %d, want 1", n)
+		}
+
+		if n := g.Count(0); n != 10 {
+			t.Errorf("got count %d, want 10", n)
+		}
+
+		// Outside is the background
+		g.Background = -1
+		if v := g.Get(Point{4, 0}); v != -1 {
+			t.Errorf("got %d, want -1", v)
+		}
+
+		defer func() {
+			if recover() == nil {
+				t.Error("expected a panic setting outside the bounds")
+			}
+		}()
+		g.Set(Point{-1, 0}, 1)
+	})
+}
+
+func TestWrap(t *testing.T) {
+	backends(t, func(t *testing.T, g *Grid[int]) {
+		g.Mode = Wrap
+
+		g.Set(Point{-1, -1}, 9)
+		if v := g.Get(Point{3, 2}); v != 9 {
+			t.Errorf("got %d, want 9", v)
+		}
+
+		if v := g.Get(Point{7, 5}); v != 9 {
+			t.Errorf("got %d, want 9", v)
+		}
+
+		var got []Point
+		g.Neighbours4(Point{0, 0}, func(p Point, v int) {
+			got = append(got, p)
+		})
+
+		want := []Point{{0, 2}, {3, 0}, {1, 0}, {0, 1}}
+		if len(got) != len(want) {
+			t.Fatalf("got %v, want %v", got, want)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		}
+	})
+}
+
+func TestNeighbours(t *testing.T) {
+	backends(t, func(t *testing.T, g *Grid[int]) {
+		count := func(p Point, fn func(Point, func(Point, int))) int {
+			n := 0
+			fn(p, func(Point, int) { n++ })
+			return n
+		}
+
+		// Corners have fewer neighbours in a bounded grid
+		if n := count(Point{0, 0}, g.Neighbours4); n != 2 {
+			t.Errorf("got %d neighbours, want 2", n)
+		}
+		if n := count(Point{0, 0}, g.Neighbours8); n != 3 {
+			t.Errorf("got %d neighbours, want 3", n)
+		}
+		if n := count(Point{1, 1}, g.Neighbours8); n != 8 {
+			t.Errorf("got %d neighbours, want 8", n)
+		}
+
+		// But not when it's infinite
+		g.Mode = Infinite
+		g.Background = 3
+		sum := 0
+		g.Neighbours8(Point{0, 0}, func(p Point, v int) { sum += v })
+		if sum != 5*3 {
+			t.Errorf("got sum %d, want 15", sum)
+		}
+	})
+}
+
+func TestInfiniteSet(t *testing.T) {
+	backends(t, func(t *testing.T, g *Grid[int]) {
+		g.Mode = Infinite
+		g.Background = 5
+		g.Set(Point{1, 1}, 1)
+
+		// Setting outside the bounds grows them
+		g.Set(Point{-2, 4}, 9)
+		min, max := g.Bounds()
+		if min != (Point{-2, 0}) || max != (Point{3, 4}) {
+			t.Errorf("wrong bounds %v - %v", min, max)
+		}
+
+		if v := g.Get(Point{-2, 4}); v != 9 {
+			t.Errorf("got %d, want 9", v)
+		}
+		if v := g.Get(Point{1, 1}); v != 1 {
+			t.Errorf("got %d, want 1", v)
+		}
+
+		// What was outside is still the background
+		if v := g.Get(Point{-1, 3}); v != 5 {
+			t.Errorf("got %d, want 5", v)
+		}
+		if v := g.Get(Point{2, 2}); v != 0 {
+			t.Errorf("got %d, want 0", v)
+		}
+	})
+}
+
+func TestResize(t *testing.T) {
+	backends(t, func(t *testing.T, g *Grid[int]) {
+		g.Set(Point{0, 0}, 1)
+		g.Set(Point{3, 2}, 2)
+
+		big := g.Grow(1)
+		min, max := big.Bounds()
+		if min != (Point{-1, -1}) || max != (Point{4, 3}) {
+			t.Errorf("wrong bounds %v - %v", min, max)
+		}
+
+		if big.Get(Point{0, 0}) != 1 || big.Get(Point{3, 2}) != 2 {
+			t.Error("values weren't kept")
+		}
+
+		// New points are the background
+		g.Background = 7
+		if v := g.Grow(2).Get(Point{-2, 4}); v != 7 {
+			t.Errorf("got %d, want 7", v)
+		}
+		g.Background = 0
+
+		small := g.Resize(Point{1, 1}, Point{3, 2})
+		if small.Width() != 3 || small.Height() != 2 || small.Get(Point{3, 2}) != 2 {
+			t.Errorf("wrong resize:\n%s", small)
+		}
+
+		sparse := g.AsSparse()
+		if !sparse.Sparse() || sparse.String() != g.String() {
+			t.Errorf("wrong sparse copy:\n%s", sparse)
+		}
+
+		// Clones are independent
+		c := g.Clone()
+		c.Set(Point{0, 0}, 5)
+		if g.Get(Point{0, 0}) != 1 {
+			t.Error("clone modified the original")
+		}
+	})
+}
+
+func TestParse(t *testing.T) {
+	text := "123\n456\n"
+	g, err := ParseDigits(strings.NewReader(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if g.Width() != 3 || g.Height() != 2 || g.Get(Point{2, 1}) != 6 {
+		t.Errorf("wrong grid:\n%s", g)
+	}
+
+	if s := g.String(); s+"\n" != text {
+		t.Errorf("got %q, want %q", s, text)
+	}
+
+	// Trailing blank lines are ignored, like input.DigitGrid
+	g, err = ParseDigits(strings.NewReader(text + "\n  \n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Width() != 3 || g.Height() != 2 {
+		t.Errorf("got a %dx%d grid, want 3x2", g.Width(), g.Height())
+	}
+
+	b, err := ParseBytes(strings.NewReader("#.\n.#\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := FormatBytes(b); s != "#.\n.#" {
+		t.Errorf("got %q", s)
+	}
+
+	tests := []struct {
+		text         string
+		line, column int
+	}{
+		{"123\n4x6\n", 2, 2},
+		{"123\n45\n", 2, 0},
+		{"123\n\n456\n", 2, 0},
+	}
+
+	for _, test := range tests {
+		_, err := ParseDigits(strings.NewReader(test.text))
+		var pe *input.ParseError
+		if !errors.As(err, &pe) {
+			t.Errorf("%q: expected a ParseError, got %v", test.text, err)
+			continue
+		}
+
+		if pe.Line != test.line || pe.Column != test.column {
+			t.Errorf("%q: got %d:%d, want %d:%d", test.text, pe.Line, pe.Column, test.line, test.column)
+		}
+	}
+}
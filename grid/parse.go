@@ -0,0 +1,84 @@
+package grid
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/usedbytes/aoc2021/input"
+)
+
+// FromLines builds a dense grid from 'lines', one row per line, converting
+// each character with 'conv'. All the lines must be the same length. Errors
+// have the line (relative to 'lines') and column.
+func FromLines[T comparable](lines []string, conv func(c byte) (T, error)) (*Grid[T], error) {
+	if len(lines) == 0 || len(lines[0]) == 0 {
+		return nil, fmt.Errorf("empty grid")
+	}
+
+	var zero T
+	g := NewDense(len(lines[0]), len(lines), zero)
+
+	for y, line := range lines {
+		if len(line) != g.Width() {
+			return nil, input.AtLine(y+1, 0, fmt.Errorf("expected %d characters, got %d", g.Width(), len(line)))
+		}
+
+		for x := 0; x < len(line); x++ {
+			v, err := conv(line[x])
+			if err != nil {
+				return nil, input.AtLine(y+1, x+1, err)
+			}
+			g.Set(Point{x, y}, v)
+		}
+	}
+
+	return g, nil
+}
+
+// Parse reads a dense grid from 'rd', like FromLines. Blank lines are allowed
+// at the end, like input.DigitGrid.
+func Parse[T comparable](rd io.Reader, conv func(c byte) (T, error)) (*Grid[T], error) {
+	lines, err := input.Lines(rd)
+	if err != nil {
+		return nil, err
+	}
+
+	for len(lines) > 0 && len(strings.TrimSpace(lines[len(lines)-1])) == 0 {
+		lines = lines[:len(lines)-1]
+	}
+
+	return FromLines(lines, conv)
+}
+
+// Digit converts a character '0'-'9' to its value
+func Digit(c byte) (int, error) {
+	if c < '0' || c > '9' {
+		return 0, fmt.Errorf("expected digit, got %q", c)
+	}
+
+	return int(c - '0'), nil
+}
+
+// Byte keeps each character as it is
+func Byte(c byte) (byte, error) {
+	return c, nil
+}
+
+// ParseDigits reads a grid of single digits
+func ParseDigits(rd io.Reader) (*Grid[int], error) {
+	return Parse(rd, Digit)
+}
+
+// ParseBytes reads a grid of characters
+func ParseBytes(rd io.Reader) (*Grid[byte], error) {
+	return Parse(rd, Byte)
+}
+
+// FormatBytes formats a grid of characters, which is usually what it was
+// parsed from
+func FormatBytes(g *Grid[byte]) string {
+	return g.Format(func(v byte) string {
+		return string(v)
+	})
+}
@@ -0,0 +1,295 @@
+// Package input provides helpers for reading the common shapes of puzzle
+// input: lines, blank-line-separated blocks, comma-separated integers and
+// grids of digits.
+package input
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseError records where in the input something went wrong. Line and
+// Column are 1-based, and zero if not known.
+type ParseError struct {
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	if e.Line == 0 {
+		return e.Err.Error()
+	} else if e.Column == 0 {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+
+	return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// At annotates 'err' with a column number. The line number will be filled in
+// by DoLines or DoBlocks.
+func At(column int, err error) error {
+	return AtLine(0, column, err)
+}
+
+// AtLine annotates 'err' with a line and column number. When returned from a
+// DoBlocks callback, 'line' is relative to the start of the block. Positions
+// which 'err' already has are kept.
+func AtLine(line, column int, err error) error {
+	if pe, ok := err.(*ParseError); ok {
+		if pe.Line == 0 {
+			pe.Line = line
+		}
+		if pe.Column == 0 {
+			pe.Column = column
+		}
+		return pe
+	}
+
+	return &ParseError{
+		Line:   line,
+		Column: column,
+		Err:    err,
+	}
+}
+
+// Open opens 'filename' for reading, or returns stdin if it is "-"
+func Open(filename string) (io.ReadCloser, error) {
+	if filename == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+
+	return os.Open(filename)
+}
+
+// Adds 'line' to 'err', offsetting any line number it already has
+func withLine(line int, err error) error {
+	if pe, ok := err.(*ParseError); ok {
+		if pe.Line == 0 {
+			pe.Line = line
+		} else {
+			pe.Line += line - 1
+		}
+		return pe
+	}
+
+	return &ParseError{
+		Line: line,
+		Err:  err,
+	}
+}
+
+// DoLines calls 'do' for each line of 'rd'. Errors returned by 'do' are
+// annotated with the line number.
+func DoLines(rd io.Reader, do func(line string) error) error {
+	scanner := bufio.NewScanner(rd)
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := scanner.Text()
+		if err := do(line); err != nil {
+			return withLine(n, err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Lines returns all the lines of 'rd'
+func Lines(rd io.Reader) ([]string, error) {
+	var lines []string
+
+	if err := DoLines(rd, func(line string) error {
+		lines = append(lines, line)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// Line returns the first line of 'rd', which must not be empty
+func Line(rd io.Reader) (string, error) {
+	scanner := bufio.NewScanner(rd)
+	if !scanner.Scan() {
+		if err := scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", &ParseError{Line: 1, Err: io.ErrUnexpectedEOF}
+	}
+
+	line := strings.TrimSpace(scanner.Text())
+	if len(line) == 0 {
+		return "", &ParseError{Line: 1, Err: fmt.Errorf("empty line")}
+	}
+
+	return line, nil
+}
+
+// DoBlocks calls 'do' for each group of lines separated by one or more blank
+// lines. Errors returned by 'do' are annotated with the line number.
+func DoBlocks(rd io.Reader, do func(block []string) error) error {
+	var block []string
+	start := 0
+
+	flush := func() error {
+		if len(block) == 0 {
+			return nil
+		}
+
+		if err := do(block); err != nil {
+			return withLine(start, err)
+		}
+		block = nil
+
+		return nil
+	}
+
+	scanner := bufio.NewScanner(rd)
+	n := 0
+	for scanner.Scan() {
+		n++
+		line := scanner.Text()
+		if len(strings.TrimSpace(line)) == 0 {
+			if err := flush(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if len(block) == 0 {
+			start = n
+		}
+		block = append(block, line)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	return flush()
+}
+
+// Blocks returns all the groups of lines separated by blank lines in 'rd'
+func Blocks(rd io.Reader) ([][]string, error) {
+	var blocks [][]string
+
+	if err := DoBlocks(rd, func(block []string) error {
+		blocks = append(blocks, block)
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+// CommaInts parses a single line of comma-separated integers
+func CommaInts(rd io.Reader) ([]int, error) {
+	line, err := Line(rd)
+	if err != nil {
+		return nil, err
+	}
+
+	vals, err := SplitInts(line, ",")
+	if err != nil {
+		return nil, withLine(1, err)
+	}
+
+	return vals, nil
+}
+
+// SplitInts parses the integers in 's' separated by 'sep'. Errors are
+// annotated with the column where the bad integer starts.
+func SplitInts(s, sep string) ([]int, error) {
+	strs := strings.Split(s, sep)
+	vals := make([]int, len(strs))
+
+	col := 1
+	for i, str := range strs {
+		v, err := strconv.Atoi(strings.TrimSpace(str))
+		if err != nil {
+			// Skip the leading space, unless there's nothing else
+			start := len(str) - len(strings.TrimLeftFunc(str, unicode.IsSpace))
+			if start == len(str) {
+				start = 0
+			}
+			return nil, At(col+start, err)
+		}
+
+		vals[i] = v
+		col += len(str) + len(sep)
+	}
+
+	return vals, nil
+}
+
+// Ints parses one integer per line
+func Ints(rd io.Reader) ([]int, error) {
+	var vals []int
+
+	if err := DoLines(rd, func(line string) error {
+		v, err := strconv.Atoi(line)
+		if err != nil {
+			return err
+		}
+
+		vals = append(vals, v)
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return vals, nil
+}
+
+// DigitGrid parses a grid of single digits, returned indexed by [y][x].
+// Blank lines are allowed at the end, but not in between the rows.
+func DigitGrid(rd io.Reader) ([][]int, error) {
+	var grid [][]int
+	blank := false
+
+	if err := DoLines(rd, func(line string) error {
+		if len(strings.TrimSpace(line)) == 0 {
+			blank = true
+			return nil
+		} else if blank {
+			return fmt.Errorf("expected the end of the grid after a blank line")
+		}
+
+		row := make([]int, len(line))
+		for i := 0; i < len(line); i++ {
+			if line[i] < '0' || line[i] > '9' {
+				return At(i+1, fmt.Errorf("expected digit, got %q", line[i]))
+			}
+			row[i] = int(line[i] - '0')
+		}
+
+		if len(grid) > 0 && len(row) != len(grid[0]) {
+			return fmt.Errorf("expected %d digits, got %d", len(grid[0]), len(row))
+		}
+
+		grid = append(grid, row)
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	return grid, nil
+}
@@ -0,0 +1,185 @@
+package input
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Checks that 'err' is a ParseError at 'line' and 'column', or that it's nil
+// if 'line' is 0
+func checkPosition(t *testing.T, err error, line, column int) {
+	t.Helper()
+
+	if line == 0 {
+		if err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+		return
+	}
+
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("got %v, want a ParseError at %d:%d", err, line, column)
+	}
+
+	if pe.Line != line || pe.Column != column {
+		t.Errorf("error at %d:%d, want %d:%d (%v)", pe.Line, pe.Column, line, column, err)
+	}
+}
+
+// Fails lines with an 'x' in, at the x's column, and lines starting with '!'
+// without a column
+func checkLine(line string) error {
+	if strings.HasPrefix(line, "!") {
+		return fmt.Errorf("bad line")
+	} else if i := strings.IndexByte(line, 'x'); i >= 0 {
+		return At(i+1, fmt.Errorf("bad x"))
+	}
+
+	return nil
+}
+
+func TestDoLines(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		want   []string
+		line   int
+		column int
+	}{
+		{"ok", "a\nb\nc\n", []string{"a", "b", "c"}, 0, 0},
+		{"no trailing newline", "a\nb", []string{"a", "b"}, 0, 0},
+		{"error", "a\nb\n!\n", nil, 3, 0},
+		{"error with column", "a\nbcdx\n", nil, 2, 4},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var got []string
+			err := DoLines(strings.NewReader(tc.in), func(line string) error {
+				if err := checkLine(line); err != nil {
+					return err
+				}
+				got = append(got, line)
+				return nil
+			})
+
+			checkPosition(t, err, tc.line, tc.column)
+			if err == nil && !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDoBlocks(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		want   [][]string
+		line   int
+		column int
+	}{
+		{"ok", "a\nb\n\nc\n", [][]string{{"a", "b"}, {"c"}}, 0, 0},
+		{"extra blank lines", "\n\na\n\n\n\nb\n\n", [][]string{{"a"}, {"b"}}, 0, 0},
+		{"error in first block", "!\na\n\nb\n", nil, 1, 0},
+		{"error in later block", "a\n\nb\n\n\n!\n", nil, 6, 0},
+		// Lines from AtLine are relative to the start of the block
+		{"error inside block", "a\n\nb\nc\nabx\n", nil, 5, 3},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var got [][]string
+			err := DoBlocks(strings.NewReader(tc.in), func(block []string) error {
+				for i, line := range block {
+					if err := checkLine(line); err != nil {
+						return AtLine(i+1, 0, err)
+					}
+				}
+				got = append(got, block)
+				return nil
+			})
+
+			checkPosition(t, err, tc.line, tc.column)
+			if err == nil && !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestSplitInts(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		sep    string
+		want   []int
+		column int
+	}{
+		{"commas", "3,4,-5", ",", []int{3, 4, -5}, 0},
+		{"spaces around", " 3 , 4,5 ", ",", []int{3, 4, 5}, 0},
+		{"arrow", "0,9 -> 5,9", " -> ", nil, 1},
+		{"bad first", "x,2", ",", nil, 1},
+		{"bad later", "1,22,x", ",", nil, 6},
+		{"leading space", "1,  x", ",", nil, 5},
+		{"empty", "1,,3", ",", nil, 3},
+		{"long separator", "1 -> 2 -> z", " -> ", nil, 11},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := SplitInts(tc.in, tc.sep)
+			if tc.column == 0 {
+				checkPosition(t, err, 0, 0)
+				if !reflect.DeepEqual(got, tc.want) {
+					t.Errorf("got %v, want %v", got, tc.want)
+				}
+				return
+			}
+
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("got %v, want a ParseError", err)
+			}
+			if pe.Column != tc.column {
+				t.Errorf("error at column %d, want %d (%v)", pe.Column, tc.column, err)
+			}
+		})
+	}
+}
+
+func TestDigitGrid(t *testing.T) {
+	tests := []struct {
+		name   string
+		in     string
+		want   [][]int
+		line   int
+		column int
+	}{
+		{"ok", "123\n456\n", [][]int{{1, 2, 3}, {4, 5, 6}}, 0, 0},
+		{"trailing blank line", "12\n34\n\n", [][]int{{1, 2}, {3, 4}}, 0, 0},
+		{"trailing blank lines", "12\n34\n\n  \n", [][]int{{1, 2}, {3, 4}}, 0, 0},
+		{"not a digit", "123\n4x6\n", nil, 2, 2},
+		{"short row", "123\n45\n", nil, 2, 0},
+		{"blank line in between", "12\n\n34\n", nil, 3, 0},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := DigitGrid(strings.NewReader(tc.in))
+
+			checkPosition(t, err, tc.line, tc.column)
+			if err == nil && !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
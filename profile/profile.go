@@ -0,0 +1,178 @@
+// Package profile writes the runtime profiles (CPU, heap, allocs, mutex,
+// block and execution trace) selected by command-line flags.
+package profile
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"strings"
+)
+
+// Config holds the filename for each type of profile. Empty filenames are
+// disabled.
+type Config struct {
+	CPU    string
+	Heap   string
+	Allocs string
+	Mutex  string
+	Block  string
+	Trace  string
+
+	// PerPart means a separate set of profiles is written for each part,
+	// with the part added to the filenames (e.g. cpu.part1.prof)
+	PerPart bool
+}
+
+// AddFlags registers flags for each profile on 'fs'
+func (c *Config) AddFlags(fs *flag.FlagSet) {
+	fs.StringVar(&c.CPU, "cpuprofile", "", "write a CPU profile to `file`")
+	fs.StringVar(&c.Heap, "heapprofile", "", "write a heap profile to `file`")
+	fs.StringVar(&c.Allocs, "allocsprofile", "", "write an allocation profile to `file`")
+	fs.StringVar(&c.Mutex, "mutexprofile", "", "write a mutex contention profile to `file`")
+	fs.StringVar(&c.Block, "blockprofile", "", "write a goroutine blocking profile to `file`")
+	fs.StringVar(&c.Trace, "trace", "", "write an execution trace to `file`")
+	fs.BoolVar(&c.PerPart, "profile-per-part", false, "write separate profiles for each part")
+}
+
+// Enabled returns true if any profile is selected
+func (c *Config) Enabled() bool {
+	return c.CPU != "" || c.Heap != "" || c.Allocs != "" ||
+		c.Mutex != "" || c.Block != "" || c.Trace != ""
+}
+
+// Adds 'suffix' before the extension of 'filename': cpu.prof -> cpu.part1.prof
+func withSuffix(filename, suffix string) string {
+	if filename == "" || suffix == "" {
+		return filename
+	}
+
+	ext := filepath.Ext(filename)
+	return strings.TrimSuffix(filename, ext) + "." + suffix + ext
+}
+
+func writeProfile(name, filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	if err := pprof.Lookup(name).WriteTo(f, 0); err != nil {
+		f.Close()
+		return fmt.Errorf("writing %s profile: %w", name, err)
+	}
+
+	return f.Close()
+}
+
+// Profiler is a running set of profiles
+type Profiler struct {
+	heap, allocs, mutex, block string
+	cpu, trace                 *os.File
+}
+
+// Start starts the profiles selected in 'c'. If 'suffix' isn't empty, it's
+// added to each filename. Stop must be called to write them out.
+//
+// The heap, allocs, mutex and block profiles are cumulative over the whole
+// program, so with per-part profiles, use 'go tool pprof -diff_base' to see
+// just one part.
+func (c *Config) Start(suffix string) (*Profiler, error) {
+	p := &Profiler{}
+
+	if c.CPU != "" {
+		f, err := os.Create(withSuffix(c.CPU, suffix))
+		if err != nil {
+			return nil, err
+		}
+
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return nil, err
+		}
+		p.cpu = f
+	}
+
+	if c.Trace != "" {
+		f, err := os.Create(withSuffix(c.Trace, suffix))
+		if err != nil {
+			p.Stop()
+			return nil, err
+		}
+
+		if err := trace.Start(f); err != nil {
+			f.Close()
+			p.Stop()
+			return nil, err
+		}
+		p.trace = f
+	}
+
+	p.heap = withSuffix(c.Heap, suffix)
+	p.allocs = withSuffix(c.Allocs, suffix)
+	p.mutex = withSuffix(c.Mutex, suffix)
+	p.block = withSuffix(c.Block, suffix)
+
+	if p.mutex != "" {
+		runtime.SetMutexProfileFraction(1)
+	}
+
+	if p.block != "" {
+		runtime.SetBlockProfileRate(1)
+	}
+
+	return p, nil
+}
+
+// Stop stops the profiles and writes them out. It returns the first error.
+func (p *Profiler) Stop() error {
+	var errs []error
+
+	if p.cpu != nil {
+		pprof.StopCPUProfile()
+		errs = append(errs, p.cpu.Close())
+		p.cpu = nil
+	}
+
+	if p.trace != nil {
+		trace.Stop()
+		errs = append(errs, p.trace.Close())
+		p.trace = nil
+	}
+
+	if p.mutex != "" {
+		errs = append(errs, writeProfile("mutex", p.mutex))
+		runtime.SetMutexProfileFraction(0)
+		p.mutex = ""
+	}
+
+	if p.block != "" {
+		errs = append(errs, writeProfile("block", p.block))
+		runtime.SetBlockProfileRate(0)
+		p.block = ""
+	}
+
+	if p.heap != "" {
+		// Make sure the heap profile is up-to-date
+		runtime.GC()
+		errs = append(errs, writeProfile("heap", p.heap))
+		p.heap = ""
+	}
+
+	if p.allocs != "" {
+		errs = append(errs, writeProfile("allocs", p.allocs))
+		p.allocs = ""
+	}
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
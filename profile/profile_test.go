@@ -0,0 +1,90 @@
+package profile
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWithSuffix(t *testing.T) {
+	tests := []struct {
+		filename, suffix, want string
+	}{
+		{"cpu.prof", "part1", "cpu.part1.prof"},
+		{"out/heap", "part2", "out/heap.part2"},
+		{"cpu.prof", "", "cpu.prof"},
+		{"", "part1", ""},
+	}
+
+	for _, tc := range tests {
+		if got := withSuffix(tc.filename, tc.suffix); got != tc.want {
+			t.Errorf("withSuffix(%q, %q) = %q, want %q", tc.filename, tc.suffix, got, tc.want)
+		}
+	}
+}
+
+func TestStartStop(t *testing.T) {
+	dir := t.TempDir()
+
+	var c Config
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	c.AddFlags(fs)
+
+	if c.Enabled() {
+		t.Fatal("profiles are enabled without any flags")
+	}
+
+	if err := fs.Parse([]string{
+		"-cpuprofile", filepath.Join(dir, "cpu.prof"),
+		"-heapprofile", filepath.Join(dir, "heap.prof"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !c.Enabled() {
+		t.Fatal("profiles aren't enabled")
+	}
+
+	p, err := c.Start("part1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Give the profiles something to see
+	var keep [][]byte
+	for i := 0; i < 1000; i++ {
+		keep = append(keep, make([]byte, 1024))
+	}
+	_ = keep
+
+	if err := p.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, name := range []string{"cpu.part1.prof", "heap.part1.prof"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+
+		if info.Size() == 0 {
+			t.Errorf("%s is empty", name)
+		}
+	}
+
+	// Only what was asked for is written
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("got %d files, want 2", len(entries))
+	}
+
+	// Stopping again does nothing
+	if err := p.Stop(); err != nil {
+		t.Errorf("second Stop: %v", err)
+	}
+}
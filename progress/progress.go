@@ -0,0 +1,167 @@
+// Package progress lets long-running solvers say how they're getting on.
+//
+// Solvers get a Reporter from their context, which does nothing unless
+// whatever's running them asked for progress, so tests and benchmarks stay
+// quiet. aoc gives them a Tracker, and shows it on the terminal.
+package progress
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/usedbytes/aoc2021/term"
+)
+
+// Reporter is told how much work a solver has done. It must be safe to call
+// from multiple goroutines.
+type Reporter interface {
+	// Phase starts a new phase of the work, which has 'total' steps, or 0
+	// if it isn't known
+	Phase(name string, total int64)
+	// Add records that 'n' more steps of the current phase are done
+	Add(n int64)
+}
+
+type discard struct{}
+
+func (discard) Phase(string, int64) {}
+func (discard) Add(int64)           {}
+
+// Discard is a Reporter which ignores everything
+var Discard Reporter = discard{}
+
+type key struct{}
+
+// With returns a context carrying 'r', for From
+func With(ctx context.Context, r Reporter) context.Context {
+	return context.WithValue(ctx, key{}, r)
+}
+
+// From returns the Reporter in 'ctx', or Discard if there isn't one
+func From(ctx context.Context) Reporter {
+	if r, ok := ctx.Value(key{}).(Reporter); ok {
+		return r
+	}
+
+	return Discard
+}
+
+// Snapshot is how far a Tracker has got. Durations are in nanoseconds.
+type Snapshot struct {
+	Phase string `json:"phase"`
+	Done  int64  `json:"done"`
+	Total int64  `json:"total,omitempty"`
+	// Elapsed is the time spent in this phase so far
+	Elapsed time.Duration `json:"elapsed"`
+	// ETA is how much longer the phase will take, if it carries on at
+	// the same rate, or 0 if the total isn't known
+	ETA time.Duration `json:"eta,omitempty"`
+}
+
+func (s *Snapshot) String() string {
+	elapsed := s.Elapsed.Round(100 * time.Millisecond)
+
+	if s.Total == 0 {
+		return fmt.Sprintf("%s: %d done, %v", s.Phase, s.Done, elapsed)
+	}
+
+	str := fmt.Sprintf("%s: %d/%d (%d%%), %v", s.Phase, s.Done, s.Total, s.Done*100/s.Total, elapsed)
+	if s.ETA > 0 {
+		str += fmt.Sprintf(", ETA %v", s.ETA.Round(time.Second))
+	}
+
+	return str
+}
+
+// Tracker is a Reporter which keeps count, so that it can be shown
+type Tracker struct {
+	// Accessed atomically, so it's first to keep it aligned
+	done int64
+
+	mu    sync.Mutex
+	phase string
+	total int64
+	start time.Time
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{}
+}
+
+func (t *Tracker) Phase(name string, total int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.phase = name
+	t.total = total
+	t.start = time.Now()
+	atomic.StoreInt64(&t.done, 0)
+}
+
+func (t *Tracker) Add(n int64) {
+	atomic.AddInt64(&t.done, n)
+}
+
+// Snapshot returns the progress so far, or nil if the solver hasn't reported
+// any
+func (t *Tracker) Snapshot() *Snapshot {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.phase == "" {
+		return nil
+	}
+
+	s := &Snapshot{
+		Phase:   t.phase,
+		Done:    atomic.LoadInt64(&t.done),
+		Total:   t.total,
+		Elapsed: time.Since(t.start),
+	}
+
+	if s.Total > 0 && s.Done > 0 && s.Done < s.Total {
+		s.ETA = time.Duration(int64(s.Elapsed) / s.Done * (s.Total - s.Done))
+	}
+
+	return s
+}
+
+// Show keeps a line on 'w' up to date with the progress of 't', after
+// 'label', until 'stop' is called. It doesn't show anything if 'w' isn't a
+// terminal.
+func Show(w io.Writer, label string, t *Tracker) (stop func()) {
+	line := term.NewLine(w)
+	if !line.TTY() {
+		return func() {}
+	}
+
+	done := make(chan bool)
+	finished := make(chan bool)
+	go func() {
+		defer close(finished)
+
+		tick := time.NewTicker(100 * time.Millisecond)
+		defer tick.Stop()
+
+		for {
+			select {
+			case <-done:
+				line.Clear()
+				return
+			case <-tick.C:
+				if s := t.Snapshot(); s != nil {
+					line.Update(label + s.String())
+				}
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+	}
+}
@@ -0,0 +1,76 @@
+package progress
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFrom(t *testing.T) {
+	if r := From(context.Background()); r != Discard {
+		t.Errorf("got %v without a reporter, want Discard", r)
+	}
+
+	tr := NewTracker()
+	if r := From(With(context.Background(), tr)); r != tr {
+		t.Errorf("got %v, want the tracker", r)
+	}
+}
+
+func TestTracker(t *testing.T) {
+	tr := NewTracker()
+	if s := tr.Snapshot(); s != nil {
+		t.Fatalf("got %+v before any progress", s)
+	}
+
+	tr.Phase("first", 0)
+	tr.Add(3)
+	tr.Add(2)
+
+	s := tr.Snapshot()
+	if s.Phase != "first" || s.Done != 5 || s.Total != 0 || s.ETA != 0 {
+		t.Errorf("got %+v, want 5 done in first, with no ETA", s)
+	}
+
+	// A new phase starts from 0
+	tr.Phase("second", 4)
+	time.Sleep(10 * time.Millisecond)
+	tr.Add(1)
+
+	s = tr.Snapshot()
+	if s.Phase != "second" || s.Done != 1 || s.Total != 4 {
+		t.Errorf("got %+v, want 1/4 done in second", s)
+	}
+
+	// 1 step took at least 10ms, so 3 more should take at least 30ms
+	if s.ETA < 30*time.Millisecond {
+		t.Errorf("ETA is %v, want at least 30ms", s.ETA)
+	}
+
+	if str := s.String(); !strings.HasPrefix(str, "second: 1/4 (25%)") {
+		t.Errorf("String is %q", str)
+	}
+}
+
+func TestConcurrent(t *testing.T) {
+	tr := NewTracker()
+	tr.Phase("adding", 100)
+
+	done := make(chan bool)
+	for i := 0; i < 10; i++ {
+		go func() {
+			for j := 0; j < 10; j++ {
+				tr.Add(1)
+			}
+			done <- true
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		<-done
+	}
+
+	if s := tr.Snapshot(); s.Done != 100 || s.ETA != 0 {
+		t.Errorf("got %+v, want all 100 done", s)
+	}
+}
@@ -0,0 +1,216 @@
+// Package scaffold generates the skeleton for a new day: the solver (from
+// template.go), its registration, a command to run it, and tests.
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+const module = "github.com/usedbytes/aoc2021"
+
+// TemplateFile is the solver skeleton, relative to the repository root
+const TemplateFile = "template.go"
+
+// DaysFile imports every day into the aoc command, relative to the
+// repository root
+const DaysFile = "cmd/aoc/days.go"
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+var templates = template.Must(template.New("").Funcs(template.FuncMap{
+	"quote": quote,
+}).ParseFS(templateFS, "templates/*.tmpl"))
+
+// Day is what the templates know about a day
+type Day struct {
+	Day     int
+	Dir     string
+	Package string
+	Import  string
+	// Example input from the puzzle, if there is one
+	Example string
+}
+
+func NewDay(day int) Day {
+	dir := fmt.Sprintf("%02d", day)
+
+	return Day{
+		Day:     day,
+		Dir:     dir,
+		Package: "day" + dir,
+		Import:  module + "/" + dir,
+	}
+}
+
+// Strings are quoted as raw strings where possible, to keep examples readable
+func quote(s string) string {
+	if strings.Contains(s, "`") {
+		return strconv.Quote(s)
+	}
+
+	return "`" + s + "`"
+}
+
+func execute(name string, data interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return src, nil
+}
+
+// Solver generates the solver for 'd' from template.go: it's the same, but
+// in the day's package and without the build constraint.
+func Solver(templateSrc []byte, d Day) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, TemplateFile, templateSrc, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	f.Name.Name = d.Package
+
+	// Drop the "//go:build ignore", which keeps template.go out of the
+	// build
+	var comments []*ast.CommentGroup
+	for _, cg := range f.Comments {
+		if !strings.HasPrefix(cg.List[0].Text, "//go:build") {
+			comments = append(comments, cg)
+		}
+	}
+	f.Comments = comments
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, f); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func writeNew(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// Generate creates the directory for 'd' in the repository at 'root', and
+// registers it with the aoc command. It refuses to touch a day which already
+// exists. It returns the files which were written.
+func Generate(root string, d Day) ([]string, error) {
+	dir := filepath.Join(root, d.Dir)
+	if _, err := os.Stat(dir); err == nil {
+		return nil, fmt.Errorf("%s already exists", dir)
+	}
+
+	templateSrc, err := os.ReadFile(filepath.Join(root, TemplateFile))
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+
+	files["puzzle.go"], err = Solver(templateSrc, d)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, tmpl := range map[string]string{
+		"register.go":    "register.go.tmpl",
+		"puzzle_test.go": "puzzle_test.go.tmpl",
+		"cmd/main.go":    "main.go.tmpl",
+	} {
+		files[name], err = execute(tmpl, d)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var written []string
+	for _, name := range []string{"puzzle.go", "register.go", "puzzle_test.go", "cmd/main.go"} {
+		path := filepath.Join(dir, name)
+		if err := writeNew(path, files[name]); err != nil {
+			return written, err
+		}
+		written = append(written, path)
+	}
+
+	if err := WriteDays(root); err != nil {
+		return written, err
+	}
+
+	return append(written, filepath.Join(root, DaysFile)), nil
+}
+
+var dayDir = regexp.MustCompile(`^[0-9][0-9]$`)
+
+// Days returns all the days in the repository at 'root', which are the
+// directories with a register.go
+func Days(root string) ([]Day, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var days []Day
+	for _, e := range entries {
+		if !e.IsDir() || !dayDir.MatchString(e.Name()) {
+			continue
+		}
+
+		if _, err := os.Stat(filepath.Join(root, e.Name(), "register.go")); err != nil {
+			continue
+		}
+
+		n, _ := strconv.Atoi(e.Name())
+		days = append(days, NewDay(n))
+	}
+
+	return days, nil
+}
+
+// WriteDays regenerates cmd/aoc/days.go, to import every day
+func WriteDays(root string) error {
+	days, err := Days(root)
+	if err != nil {
+		return err
+	}
+
+	src, err := execute("days.go.tmpl", days)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filepath.Join(root, DaysFile), src, 0644)
+}
@@ -0,0 +1,129 @@
+package scaffold
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Copies the files 'rel' from the repository root to 'dst'
+func copyFiles(t *testing.T, dst string, rel ...string) {
+	t.Helper()
+
+	for _, r := range rel {
+		matches, err := filepath.Glob(filepath.Join("..", r))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, m := range matches {
+			data, err := os.ReadFile(m)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			path := filepath.Join(dst, strings.TrimPrefix(m, ".."+string(filepath.Separator)))
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func imports(t *testing.T, filename string) []string {
+	t.Helper()
+
+	f, err := parser.ParseFile(token.NewFileSet(), filename, nil, parser.ImportsOnly)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var paths []string
+	for _, imp := range f.Imports {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		paths = append(paths, p)
+	}
+
+	return paths
+}
+
+func TestGenerate(t *testing.T) {
+	root := t.TempDir()
+	copyFiles(t, root, "go.mod", "template.go", "01/*.go", "cmd/aoc/days.go",
+		"debug/*.go", "input/*.go", "solver/*.go", "golden/*.go")
+
+	d := NewDay(7)
+	d.Example = "16,1,2,0,4,2,7,1,2,14\n"
+
+	files, err := Generate(root, d)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, f := range files {
+		if _, err := parser.ParseFile(token.NewFileSet(), f, nil, 0); err != nil {
+			t.Errorf("generated invalid code: %v", err)
+		}
+	}
+
+	src, err := os.ReadFile(filepath.Join(root, "07", "puzzle.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(src), "package day07\n") {
+		t.Errorf("puzzle.go should start with the package, got:\n%s", src)
+	}
+
+	src, err = os.ReadFile(filepath.Join(root, "07", "puzzle_test.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(src), "`"+d.Example+"`") {
+		t.Errorf("example missing from puzzle_test.go:\n%s", src)
+	}
+
+	// Only the days which exist in 'root' are registered
+	got := strings.Join(imports(t, filepath.Join(root, DaysFile)), " ")
+	want := "github.com/usedbytes/aoc2021/01 github.com/usedbytes/aoc2021/07"
+	if got != want {
+		t.Errorf("days.go imports %s, want %s", got, want)
+	}
+
+	if _, err := Generate(root, d); err == nil {
+		t.Error("expected an error generating a day which already exists")
+	}
+
+	// Make sure it all actually builds, and the generated tests pass
+	if testing.Short() {
+		return
+	}
+
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("no go tool")
+	}
+
+	cmd := exec.Command(goTool, "test", "./07/...")
+	cmd.Dir = root
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("go test failed: %v\n%s", err, out)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	for _, s := range []string{"abc\n", "a`b"} {
+		q := quote(s)
+		got, err := strconv.Unquote(q)
+		if err != nil || got != s {
+			t.Errorf("quote(%q) = %s", s, q)
+		}
+	}
+}
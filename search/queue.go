@@ -0,0 +1,47 @@
+package search
+
+import "container/heap"
+
+type item[T any] struct {
+	value    T
+	priority int
+}
+
+type items[T any] []item[T]
+
+func (q items[T]) Len() int            { return len(q) }
+func (q items[T]) Less(i, j int) bool  { return q[i].priority < q[j].priority }
+func (q items[T]) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *items[T]) Push(x interface{}) { *q = append(*q, x.(item[T])) }
+
+func (q *items[T]) Pop() interface{} {
+	old := *q
+	n := len(old)
+	it := old[n-1]
+	*q = old[:n-1]
+	return it
+}
+
+// Queue is a priority queue, which pops the lowest priority first.
+//
+// There's no way to change the priority of something already in the queue,
+// just push it again. The searches skip anything they've already finished
+// with.
+type Queue[T any] struct {
+	items items[T]
+}
+
+func (q *Queue[T]) Len() int {
+	return q.items.Len()
+}
+
+func (q *Queue[T]) Push(v T, priority int) {
+	heap.Push(&q.items, item[T]{v, priority})
+}
+
+// Pop removes and returns the item with the lowest priority. It panics if
+// the queue is empty.
+func (q *Queue[T]) Pop() (T, int) {
+	it := heap.Pop(&q.items).(item[T])
+	return it.value, it.priority
+}
@@ -0,0 +1,196 @@
+// Package search has generic graph searches: Dijkstra, A* and BFS for
+// finding the cheapest path to a goal, and counting all the paths to it.
+//
+// The graph is never built explicitly, it's described by a function giving
+// the neighbours of each state, so states can be anything comparable: a
+// point, a string, or a whole puzzle board.
+package search
+
+// Search describes the graph to search, and the goal
+type Search[S comparable] struct {
+	// Neighbours calls 'yield' for each state which can be reached from
+	// 's', with the cost of moving there
+	Neighbours func(s S, yield func(next S, cost int))
+
+	// IsGoal returns true if 's' is a goal
+	IsGoal func(s S) bool
+
+	// Heuristic estimates the cost from 's' to the nearest goal, for A*.
+	// To find the cheapest path it has to be consistent: it mustn't
+	// overestimate, and it can't drop by more than the cost of any move.
+	// States are never revisited once expanded, so a heuristic which is
+	// admissible but not consistent can give a path which is too
+	// expensive. If it's nil, A* is just Dijkstra.
+	Heuristic func(s S) int
+}
+
+// Result is the outcome of a search
+type Result[S comparable] struct {
+	// Found is false if no goal could be reached
+	Found bool
+	// The goal which was reached, and the cost of getting there
+	Goal S
+	Cost int
+	// The number of states which were expanded
+	Expanded int
+
+	start S
+	prev  map[S]S
+}
+
+// Path returns the states from the start to the goal, inclusive, or nil if
+// no goal was found
+func (r *Result[S]) Path() []S {
+	if !r.Found {
+		return nil
+	}
+
+	path := []S{r.Goal}
+	for s := r.Goal; s != r.start; {
+		s = r.prev[s]
+		path = append(path, s)
+	}
+
+	// Reverse, so that it starts from the start
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+
+	return path
+}
+
+func zeroHeuristic[S any](S) int {
+	return 0
+}
+
+// Dijkstra finds the cheapest path from 'start' to a goal. Costs must not be
+// negative.
+func (s *Search[S]) Dijkstra(start S) *Result[S] {
+	return s.bestFirst(start, zeroHeuristic[S])
+}
+
+// AStar finds the cheapest path from 'start' to a goal, exploring the most
+// promising states first according to the Heuristic, which must be
+// consistent. Without one it's the same as Dijkstra.
+func (s *Search[S]) AStar(start S) *Result[S] {
+	if s.Heuristic == nil {
+		return s.Dijkstra(start)
+	}
+
+	return s.bestFirst(start, s.Heuristic)
+}
+
+func (s *Search[S]) bestFirst(start S, heuristic func(S) int) *Result[S] {
+	r := &Result[S]{
+		start: start,
+		prev:  make(map[S]S),
+	}
+
+	// Best known cost to reach each state
+	costs := map[S]int{start: 0}
+	done := make(map[S]bool)
+
+	var queue Queue[S]
+	queue.Push(start, heuristic(start))
+
+	for queue.Len() > 0 {
+		current, _ := queue.Pop()
+		if done[current] {
+			// A stale entry, it was already reached more cheaply
+			continue
+		}
+		done[current] = true
+		r.Expanded++
+
+		cost := costs[current]
+		if s.IsGoal(current) {
+			r.Found = true
+			r.Goal = current
+			r.Cost = cost
+			return r
+		}
+
+		s.Neighbours(current, func(next S, step int) {
+			if done[next] {
+				return
+			}
+
+			newCost := cost + step
+			if old, ok := costs[next]; ok && old <= newCost {
+				return
+			}
+
+			// This is the best route to 'next' found so far
+			costs[next] = newCost
+			r.prev[next] = current
+			queue.Push(next, newCost+heuristic(next))
+		})
+	}
+
+	return r
+}
+
+// BFS finds the path from 'start' to a goal with the fewest steps, ignoring
+// the costs. Result.Cost is the number of steps.
+func (s *Search[S]) BFS(start S) *Result[S] {
+	r := &Result[S]{
+		start: start,
+		prev:  make(map[S]S),
+	}
+
+	steps := map[S]int{start: 0}
+	queue := []S{start}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		r.Expanded++
+
+		if s.IsGoal(current) {
+			r.Found = true
+			r.Goal = current
+			r.Cost = steps[current]
+			return r
+		}
+
+		s.Neighbours(current, func(next S, _ int) {
+			if _, seen := steps[next]; seen {
+				return
+			}
+
+			steps[next] = steps[current] + 1
+			r.prev[next] = current
+			queue = append(queue, next)
+		})
+	}
+
+	return r
+}
+
+// CountPaths returns the number of different paths from 'start' to any goal.
+// Paths stop at the first goal they reach. The graph must not have any
+// cycles which can reach a goal, or there would be infinitely many paths.
+func (s *Search[S]) CountPaths(start S) int {
+	memo := make(map[S]int)
+
+	var count func(S) int
+	count = func(current S) int {
+		if s.IsGoal(current) {
+			return 1
+		}
+
+		if n, ok := memo[current]; ok {
+			return n
+		}
+
+		n := 0
+		s.Neighbours(current, func(next S, _ int) {
+			n += count(next)
+		})
+		memo[current] = n
+
+		return n
+	}
+
+	return count(start)
+}
@@ -0,0 +1,185 @@
+package search
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type point struct {
+	x, y int
+}
+
+// The example from Day 15
+var riskMap = []string{
+	"1163751742",
+	"1381373672",
+	"2136511328",
+	"3694931569",
+	"7463417111",
+	"1319128137",
+	"1359912421",
+	"3125421639",
+	"1293138521",
+	"2311944581",
+}
+
+func gridSearch(rows []string) *Search[point] {
+	goal := point{len(rows[0]) - 1, len(rows) - 1}
+
+	return &Search[point]{
+		Neighbours: func(p point, yield func(point, int)) {
+			for _, d := range []point{{0, -1}, {-1, 0}, {1, 0}, {0, 1}} {
+				n := point{p.x + d.x, p.y + d.y}
+				if n.x < 0 || n.y < 0 || n.x > goal.x || n.y > goal.y {
+					continue
+				}
+
+				c := rows[n.y][n.x]
+				if c == '#' {
+					continue
+				}
+				yield(n, int(c-'0'))
+			}
+		},
+		IsGoal: func(p point) bool {
+			return p == goal
+		},
+		Heuristic: func(p point) int {
+			return (goal.x - p.x) + (goal.y - p.y)
+		},
+	}
+}
+
+func checkPath(t *testing.T, s *Search[point], r *Result[point], start point) {
+	t.Helper()
+
+	path := r.Path()
+	if path[0] != start || path[len(path)-1] != r.Goal {
+		t.Fatalf("path doesn't go from start to goal: %v", path)
+	}
+
+	// The path's cost should add up
+	cost := 0
+	for i := 1; i < len(path); i++ {
+		found := false
+		s.Neighbours(path[i-1], func(n point, c int) {
+			if n == path[i] {
+				found = true
+				cost += c
+			}
+		})
+		if !found {
+			t.Fatalf("%v isn't a neighbour of %v", path[i], path[i-1])
+		}
+	}
+
+	if cost != r.Cost {
+		t.Errorf("path costs %d, but result is %d", cost, r.Cost)
+	}
+}
+
+func TestDijkstraAStar(t *testing.T) {
+	s := gridSearch(riskMap)
+	start := point{0, 0}
+
+	d := s.Dijkstra(start)
+	a := s.AStar(start)
+
+	for _, r := range []*Result[point]{d, a} {
+		if !r.Found || r.Cost != 40 {
+			t.Errorf("expected cost 40, got %v %d", r.Found, r.Cost)
+		}
+		checkPath(t, s, r, start)
+	}
+
+	if a.Expanded > d.Expanded {
+		t.Errorf("A* expanded more (%d) than Dijkstra (%d)", a.Expanded, d.Expanded)
+	}
+}
+
+func TestAStarNoHeuristic(t *testing.T) {
+	s := gridSearch(riskMap)
+	s.Heuristic = nil
+	start := point{0, 0}
+
+	r := s.AStar(start)
+	if !r.Found || r.Cost != 40 {
+		t.Errorf("expected cost 40, got %v %d", r.Found, r.Cost)
+	}
+	checkPath(t, s, r, start)
+}
+
+func TestBFS(t *testing.T) {
+	maze := []string{
+		"1#111",
+		"1#1#1",
+		"111#1",
+	}
+	s := gridSearch(maze)
+
+	r := s.BFS(point{0, 0})
+	if !r.Found || r.Cost != 10 {
+		t.Fatalf("expected 10 steps, got %v %d", r.Found, r.Cost)
+	}
+
+	if len(r.Path()) != 11 {
+		t.Errorf("expected 11 points in the path, got %v", r.Path())
+	}
+}
+
+func TestUnreachable(t *testing.T) {
+	s := gridSearch([]string{
+		"11#1",
+		"1#11",
+	})
+
+	for name, r := range map[string]*Result[point]{
+		"dijkstra": s.Dijkstra(point{0, 0}),
+		"astar":    s.AStar(point{0, 0}),
+		"bfs":      s.BFS(point{0, 0}),
+	} {
+		if r.Found || r.Path() != nil {
+			t.Errorf("%s: found a path when there isn't one", name)
+		}
+	}
+}
+
+func TestCountPaths(t *testing.T) {
+	// Only moving right or down, there are (w+h choose w) paths
+	w, h := 3, 4
+	s := &Search[point]{
+		Neighbours: func(p point, yield func(point, int)) {
+			if p.x < w {
+				yield(point{p.x + 1, p.y}, 1)
+			}
+			if p.y < h {
+				yield(point{p.x, p.y + 1}, 1)
+			}
+		},
+		IsGoal: func(p point) bool {
+			return p == point{w, h}
+		},
+	}
+
+	if n := s.CountPaths(point{0, 0}); n != 35 {
+		t.Errorf("got %d paths, want 35", n)
+	}
+}
+
+func TestQueue(t *testing.T) {
+	var q Queue[string]
+	for i, s := range strings.Fields("e b d a c") {
+		q.Push(s, int(s[0])*10+i)
+	}
+
+	var got []string
+	for q.Len() > 0 {
+		v, _ := q.Pop()
+		got = append(got, v)
+	}
+
+	if fmt.Sprint(got) != "[a b c d e]" {
+		t.Errorf("wrong order %v", got)
+	}
+}
@@ -0,0 +1,289 @@
+// Package server serves the solvers over HTTP, so that other tools can use
+// them without running aoc for each answer. There's one endpoint:
+//
+//	POST /days/{n}/parts/{p}
+//
+// The request body is the puzzle input, and the response is a JSON Response,
+// whether or not it worked.
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/usedbytes/aoc2021/input"
+	"github.com/usedbytes/aoc2021/solver"
+)
+
+// The limits used when a Server doesn't set its own. The default for
+// MaxSolves is the number of CPUs.
+const (
+	DefaultMaxInput = 1 << 20
+	DefaultTimeout  = time.Minute
+)
+
+// Server is an http.Handler which runs the solvers. The zero value uses the
+// registered solvers, and the default limits.
+type Server struct {
+	// Lookup returns the solver for a day, or nil. solver.Get if nil.
+	Lookup func(day int) *solver.Solver
+	// MaxInput is the largest input accepted, in bytes
+	MaxInput int64
+	// Timeout is how long to spend parsing and solving each request.
+	// Solvers which don't pay attention to their context carry on running
+	// in the background after it runs out, until they finish.
+	Timeout time.Duration
+	// MaxSolves is how many requests can be parsing or solving at once,
+	// counting ones which timed out but are still running in the
+	// background. Any more get a 503 straight away, rather than waiting.
+	MaxSolves int
+
+	once  sync.Once
+	slots chan struct{}
+}
+
+// ParseError is where parsing the input went wrong. Line and Column are
+// 1-based, and omitted if they aren't known.
+type ParseError struct {
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// Response is the result of a request. Durations are in nanoseconds.
+type Response struct {
+	Day           int           `json:"day,omitempty"`
+	Part          int           `json:"part,omitempty"`
+	Answer        string        `json:"answer,omitempty"`
+	ParseDuration time.Duration `json:"parse_duration,omitempty"`
+	Duration      time.Duration `json:"duration,omitempty"`
+	Error         string        `json:"error,omitempty"`
+	ParseError    *ParseError   `json:"parse_error,omitempty"`
+}
+
+// Returns the day and part from a path like /days/1/parts/2
+func parsePath(path string) (day, part int, ok bool) {
+	fields := strings.Split(strings.Trim(path, "/"), "/")
+	if len(fields) != 4 || fields[0] != "days" || fields[2] != "parts" {
+		return 0, 0, false
+	}
+
+	day, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, 0, false
+	}
+
+	part, err = strconv.Atoi(fields[3])
+	if err != nil {
+		return 0, 0, false
+	}
+
+	return day, part, true
+}
+
+func reply(w http.ResponseWriter, status int, resp *Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	// There's nothing useful to do if this fails, the client has
+	// probably gone away
+	json.NewEncoder(w).Encode(resp)
+}
+
+// Returns the HTTP status for an error from parsing or solving
+func errorStatus(err error) int {
+	var panicErr *solver.PanicError
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, context.Canceled):
+		return http.StatusServiceUnavailable
+	case errors.As(err, &panicErr):
+		return http.StatusInternalServerError
+	default:
+		return http.StatusUnprocessableEntity
+	}
+}
+
+// Takes a slot to solve in, returning false if they're all in use
+func (s *Server) acquire() bool {
+	s.once.Do(func() {
+		n := s.MaxSolves
+		if n <= 0 {
+			n = runtime.NumCPU()
+		}
+		s.slots = make(chan struct{}, n)
+	})
+
+	select {
+	case s.slots <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Server) release() {
+	<-s.slots
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	day, part, ok := parsePath(r.URL.Path)
+	if !ok {
+		reply(w, http.StatusNotFound, &Response{Error: "not found, try POST /days/{n}/parts/{p}"})
+		return
+	}
+
+	resp := &Response{Day: day, Part: part}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		resp.Error = fmt.Sprintf("method %s not allowed", r.Method)
+		reply(w, http.StatusMethodNotAllowed, resp)
+		return
+	}
+
+	lookup := s.Lookup
+	if lookup == nil {
+		lookup = solver.Get
+	}
+
+	sv := lookup(day)
+	if sv == nil {
+		resp.Error = fmt.Sprintf("no solver for day %d", day)
+		reply(w, http.StatusNotFound, resp)
+		return
+	}
+
+	p := sv.Part(part)
+	if p == nil {
+		resp.Error = fmt.Sprintf("day %d has no part %d", day, part)
+		reply(w, http.StatusNotFound, resp)
+		return
+	}
+
+	maxInput := s.MaxInput
+	if maxInput <= 0 {
+		maxInput = DefaultMaxInput
+	}
+
+	// Read one byte more than allowed, to tell if there was too much
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxInput+1))
+	if err != nil {
+		resp.Error = fmt.Sprintf("reading input: %v", err)
+		reply(w, http.StatusBadRequest, resp)
+		return
+	} else if int64(len(data)) > maxInput {
+		resp.Error = fmt.Sprintf("input is larger than %d bytes", maxInput)
+		reply(w, http.StatusRequestEntityTooLarge, resp)
+		return
+	}
+
+	if !s.acquire() {
+		resp.Error = "too many requests in progress, try again later"
+		reply(w, http.StatusServiceUnavailable, resp)
+		return
+	}
+
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	// The slot is only released when the solve really finishes, which can
+	// be long after the request gives up on it
+	outcomes := make(chan *outcome, 1)
+	go func() {
+		defer s.release()
+		outcomes <- solve(ctx, sv, p, data)
+	}()
+
+	var o *outcome
+	select {
+	case o = <-outcomes:
+	case <-ctx.Done():
+		resp.Error = ctx.Err().Error()
+		reply(w, http.StatusServiceUnavailable, resp)
+		return
+	}
+
+	resp.ParseDuration = o.parseDuration
+	if err := o.parseErr; err != nil {
+		resp.Error = fmt.Sprintf("parsing input: %v", err)
+
+		status := errorStatus(err)
+		if status == http.StatusUnprocessableEntity {
+			status = http.StatusBadRequest
+			resp.ParseError = &ParseError{Message: err.Error()}
+
+			var pe *input.ParseError
+			if errors.As(err, &pe) {
+				resp.ParseError.Line = pe.Line
+				resp.ParseError.Column = pe.Column
+				resp.ParseError.Message = pe.Err.Error()
+			}
+		}
+
+		reply(w, status, resp)
+		return
+	}
+
+	resp.Duration = o.duration
+	if err := o.err; err != nil {
+		resp.Error = err.Error()
+		reply(w, errorStatus(err), resp)
+		return
+	}
+
+	resp.Answer = fmt.Sprint(o.answer)
+	reply(w, http.StatusOK, resp)
+}
+
+// What happened when parsing and solving a request
+type outcome struct {
+	answer        interface{}
+	parseDuration time.Duration
+	duration      time.Duration
+	parseErr      error
+	err           error
+}
+
+// Parses 'data' and solves part 'p' of it, however long that takes
+func solve(ctx context.Context, sv *solver.Solver, p solver.Part, data []byte) *outcome {
+	o := &outcome{}
+
+	var in interface{}
+	start := time.Now()
+	o.parseErr = solver.Recover(func() error {
+		parsed, err := sv.Parse(bytes.NewReader(data))
+		in = parsed
+		return err
+	})
+	o.parseDuration = time.Since(start)
+
+	if o.parseErr != nil {
+		return o
+	}
+
+	start = time.Now()
+	o.err = solver.Recover(func() error {
+		a, err := p(ctx, in)
+		o.answer = a
+		return err
+	})
+	o.duration = time.Since(start)
+
+	return o
+}
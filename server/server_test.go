@@ -0,0 +1,357 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	_ "github.com/usedbytes/aoc2021/01"
+	"github.com/usedbytes/aoc2021/input"
+	"github.com/usedbytes/aoc2021/solver"
+)
+
+// Fake days, so that the tests don't depend on the real ones:
+//
+//	1: sums the numbers in the input, and has no part 2
+//	2: waits until it's cancelled
+//	3: panics
+var fakes = map[int]*solver.Solver{
+	1: {
+		Day: 1,
+		Parse: func(rd io.Reader) (interface{}, error) {
+			return input.Ints(rd)
+		},
+		Parts: [2]solver.Part{
+			func(_ context.Context, in interface{}) (interface{}, error) {
+				sum := 0
+				for _, v := range in.([]int) {
+					sum += v
+				}
+				return sum, nil
+			},
+		},
+	},
+	2: {
+		Day: 2,
+		Parse: func(rd io.Reader) (interface{}, error) {
+			return nil, nil
+		},
+		Parts: [2]solver.Part{
+			func(ctx context.Context, _ interface{}) (interface{}, error) {
+				<-ctx.Done()
+				return nil, ctx.Err()
+			},
+		},
+	},
+	3: {
+		Day: 3,
+		Parse: func(rd io.Reader) (interface{}, error) {
+			return nil, nil
+		},
+		Parts: [2]solver.Part{
+			func(_ context.Context, _ interface{}) (interface{}, error) {
+				panic("oops")
+			},
+		},
+	},
+}
+
+func fakeServer() *httptest.Server {
+	return httptest.NewServer(&Server{
+		Lookup: func(day int) *solver.Solver {
+			return fakes[day]
+		},
+		MaxInput: 100,
+		Timeout:  50 * time.Millisecond,
+	})
+}
+
+func post(t *testing.T, url, body string) (int, *Response) {
+	t.Helper()
+
+	resp, err := http.Post(url, "text/plain", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type is %q, want application/json", ct)
+	}
+
+	var r Response
+	if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
+		t.Fatal(err)
+	}
+
+	return resp.StatusCode, &r
+}
+
+func TestServer(t *testing.T) {
+	srv := fakeServer()
+	defer srv.Close()
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+		want   Response
+	}{
+		{
+			"ok", "/days/1/parts/1", "1\n2\n3\n",
+			http.StatusOK,
+			Response{Day: 1, Part: 1, Answer: "6"},
+		},
+		{
+			"parse error", "/days/1/parts/1", "1\n2\nthree\n",
+			http.StatusBadRequest,
+			Response{Day: 1, Part: 1, ParseError: &ParseError{Line: 3}},
+		},
+		{
+			"too large", "/days/1/parts/1", strings.Repeat("1\n", 51),
+			http.StatusRequestEntityTooLarge,
+			Response{Day: 1, Part: 1},
+		},
+		{
+			"just small enough", "/days/1/parts/1", strings.Repeat("1\n", 50),
+			http.StatusOK,
+			Response{Day: 1, Part: 1, Answer: "50"},
+		},
+		{
+			"timeout", "/days/2/parts/1", "",
+			http.StatusServiceUnavailable,
+			Response{Day: 2, Part: 1},
+		},
+		{
+			"panic", "/days/3/parts/1", "",
+			http.StatusInternalServerError,
+			Response{Day: 3, Part: 1},
+		},
+		{
+			"no day", "/days/4/parts/1", "",
+			http.StatusNotFound,
+			Response{Day: 4, Part: 1},
+		},
+		{
+			"no part", "/days/1/parts/2", "",
+			http.StatusNotFound,
+			Response{Day: 1, Part: 2},
+		},
+		{
+			"bad path", "/days/1", "",
+			http.StatusNotFound,
+			Response{},
+		},
+		{
+			"not a number", "/days/one/parts/1", "",
+			http.StatusNotFound,
+			Response{},
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			status, r := post(t, srv.URL+tc.path, tc.body)
+			if status != tc.status {
+				t.Errorf("status is %d, want %d (error %q)", status, tc.status, r.Error)
+			}
+
+			if r.Day != tc.want.Day || r.Part != tc.want.Part || r.Answer != tc.want.Answer {
+				t.Errorf("got day %d part %d answer %q, want day %d part %d answer %q",
+					r.Day, r.Part, r.Answer, tc.want.Day, tc.want.Part, tc.want.Answer)
+			}
+
+			if (status == http.StatusOK) != (r.Error == "") {
+				t.Errorf("status %d with error %q", status, r.Error)
+			}
+
+			if want := tc.want.ParseError; want != nil {
+				if r.ParseError == nil {
+					t.Fatal("no parse error")
+				}
+				if r.ParseError.Line != want.Line || r.ParseError.Column != want.Column {
+					t.Errorf("parse error at %d:%d, want %d:%d",
+						r.ParseError.Line, r.ParseError.Column, want.Line, want.Column)
+				}
+			} else if r.ParseError != nil {
+				t.Errorf("unexpected parse error %+v", r.ParseError)
+			}
+		})
+	}
+}
+
+func TestMethod(t *testing.T) {
+	srv := fakeServer()
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/days/1/parts/1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("status is %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+
+	if allow := resp.Header.Get("Allow"); allow != "POST" {
+		t.Errorf("Allow is %q, want POST", allow)
+	}
+}
+
+// The zero Server uses the registered days
+func TestRegistered(t *testing.T) {
+	srv := httptest.NewServer(&Server{})
+	defer srv.Close()
+
+	data, err := os.ReadFile("../01/testdata/example.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for part, want := range []string{"7", "5"} {
+		status, r := post(t, fmt.Sprintf("%s/days/1/parts/%d", srv.URL, part+1), string(data))
+		if status != http.StatusOK {
+			t.Fatalf("status is %d: %s", status, r.Error)
+		}
+
+		if r.Answer != want {
+			t.Errorf("part %d answer is %q, want %q", part+1, r.Answer, want)
+		}
+	}
+}
+
+func TestBusy(t *testing.T) {
+	started := make(chan bool)
+	finish := make(chan bool)
+
+	slow := &solver.Solver{
+		Day: 1,
+		Parse: func(rd io.Reader) (interface{}, error) {
+			return nil, nil
+		},
+		Parts: [2]solver.Part{
+			func(_ context.Context, _ interface{}) (interface{}, error) {
+				started <- true
+				<-finish
+				return "done", nil
+			},
+		},
+	}
+
+	srv := httptest.NewServer(&Server{
+		Lookup: func(day int) *solver.Solver {
+			return slow
+		},
+		MaxSolves: 1,
+	})
+	defer srv.Close()
+
+	url := srv.URL + "/days/1/parts/1"
+
+	statuses := make(chan int)
+	go func() {
+		resp, err := http.Post(url, "text/plain", strings.NewReader(""))
+		if err != nil {
+			statuses <- 0
+			return
+		}
+		resp.Body.Close()
+		statuses <- resp.StatusCode
+	}()
+	<-started
+
+	// The only slot is taken
+	if status, r := post(t, url, ""); status != http.StatusServiceUnavailable {
+		t.Errorf("status is %d, want %d (answer %q)", status, http.StatusServiceUnavailable, r.Answer)
+	}
+
+	finish <- true
+	if status := <-statuses; status != http.StatusOK {
+		t.Errorf("first request's status is %d, want %d", status, http.StatusOK)
+	}
+
+	// Now it's free again
+	go func() {
+		<-started
+		finish <- true
+	}()
+	if status, r := post(t, url, ""); status != http.StatusOK {
+		t.Errorf("status is %d, want %d (%s)", status, http.StatusOK, r.Error)
+	}
+}
+
+// A solver which ignores its context keeps its slot after the request times
+// out, until it really finishes
+func TestBusyAfterTimeout(t *testing.T) {
+	finish := make(chan struct{})
+
+	stubborn := &solver.Solver{
+		Day: 1,
+		Parse: func(rd io.Reader) (interface{}, error) {
+			return nil, nil
+		},
+		Parts: [2]solver.Part{
+			func(_ context.Context, _ interface{}) (interface{}, error) {
+				<-finish
+				return "done", nil
+			},
+		},
+	}
+
+	srv := httptest.NewServer(&Server{
+		Lookup: func(day int) *solver.Solver {
+			return stubborn
+		},
+		Timeout:   50 * time.Millisecond,
+		MaxSolves: 1,
+	})
+	defer srv.Close()
+
+	url := srv.URL + "/days/1/parts/1"
+
+	if status, r := post(t, url, ""); status != http.StatusServiceUnavailable || !strings.Contains(r.Error, "deadline") {
+		t.Fatalf("status is %d (%q), want a timeout", status, r.Error)
+	}
+
+	// It's still running, so the slot is still taken
+	if status, r := post(t, url, ""); status != http.StatusServiceUnavailable || !strings.Contains(r.Error, "too many") {
+		t.Errorf("status is %d (%q), want too many requests", status, r.Error)
+	}
+
+	close(finish)
+
+	// Now it's finished the slot is released, but not necessarily before
+	// the next request arrives
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		status, r := post(t, url, "")
+		if status == http.StatusOK {
+			break
+		} else if time.Now().After(deadline) {
+			t.Fatalf("status is %d (%q), want %d", status, r.Error, http.StatusOK)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	err := fmt.Errorf("solving: %w", context.DeadlineExceeded)
+	if got := errorStatus(err); got != http.StatusServiceUnavailable {
+		t.Errorf("wrapped timeout is %d, want %d", got, http.StatusServiceUnavailable)
+	}
+
+	if got := errorStatus(errors.New("no")); got != http.StatusUnprocessableEntity {
+		t.Errorf("plain error is %d, want %d", got, http.StatusUnprocessableEntity)
+	}
+}
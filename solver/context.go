@@ -0,0 +1,48 @@
+package solver
+
+import (
+	"context"
+	"fmt"
+)
+
+// PanicError is returned by WithContext when its function panics
+type PanicError struct {
+	Value interface{}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Recover runs 'f', returning any panic as a *PanicError
+func Recover(f func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{r}
+		}
+	}()
+
+	return f()
+}
+
+// WithContext runs 'f', but returns early with the context's error if 'ctx'
+// is done first. Most solvers don't know about contexts, so 'f' may carry on
+// running in the background until it finishes. If 'f' panics, the panic is
+// returned as a *PanicError.
+func WithContext(ctx context.Context, f func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- Recover(f)
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
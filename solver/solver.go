@@ -0,0 +1,215 @@
+// Package solver is a registry of the solvers for each day, so that they can
+// be run without knowing the types each one uses.
+package solver
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+)
+
+// Part solves one part of a puzzle from the parsed input. Only some parts
+// pay attention to 'ctx', the rest run to completion regardless.
+type Part func(ctx context.Context, in interface{}) (interface{}, error)
+
+// Option is a named, integer-valued setting for a day, which replaces the
+// normal parts when given. For example the number of days to simulate on
+// Day 6.
+type Option struct {
+	Name  string
+	Usage string
+	// Min is the smallest value the option can have
+	Min int
+	Run func(ctx context.Context, in interface{}, value int) (interface{}, error)
+}
+
+// Generator writes a random, valid input for a day to 'w'. What 'size'
+// means depends on the day, and 0 means about the size of a real input.
+type Generator func(w io.Writer, rnd *rand.Rand, size int) error
+
+// Drawing draws a day's puzzle from its parsed input, as a PNG or an
+// animated GIF. 'scale' is the size of each cell in pixels, or 0 for the
+// day's default.
+type Drawing struct {
+	// Format is "png" or "gif"
+	Format string
+	Draw   func(w io.Writer, in interface{}, scale int) error
+}
+
+// Screen shows the frames of an animation, one after another. A term.Screen
+// is one, and tests can just keep the frames.
+type Screen interface {
+	Frame(text string) error
+}
+
+// Animation animates a day's puzzle from its parsed input, as text on a
+// screen
+type Animation struct {
+	// FPS is the day's default frame rate
+	FPS     int
+	Animate func(scr Screen, in interface{}) error
+}
+
+type Solver struct {
+	Day     int
+	Parse   func(rd io.Reader) (interface{}, error)
+	Parts   [2]Part
+	Options []*Option
+	// Generate is nil for days without a generator
+	Generate Generator
+	// Drawing is nil for days which can't be drawn
+	Drawing *Drawing
+	// Animation is nil for days which can't be animated
+	Animation *Animation
+	// Slow marks the parts which take minutes on a real input, so they're
+	// only run when asked for
+	Slow [2]bool
+}
+
+// Part returns the solver for part 'n' (1 or 2), or nil if there isn't one
+func (s *Solver) Part(n int) Part {
+	if n < 1 || n > len(s.Parts) {
+		return nil
+	}
+
+	return s.Parts[n-1]
+}
+
+// IsSlow returns true if part 'n' (1 or 2) is marked as slow
+func (s *Solver) IsSlow(n int) bool {
+	if n < 1 || n > len(s.Slow) {
+		return false
+	}
+
+	return s.Slow[n-1]
+}
+
+// Option returns the option called 'name', or nil if there isn't one
+func (s *Solver) Option(name string) *Option {
+	for _, o := range s.Options {
+		if o.Name == name {
+			return o
+		}
+	}
+
+	return nil
+}
+
+var registry = make(map[int]*Solver)
+
+func wrapPart[T any, R any](part func(context.Context, T) (R, error)) Part {
+	if part == nil {
+		return nil
+	}
+
+	return func(ctx context.Context, in interface{}) (interface{}, error) {
+		return part(ctx, in.(T))
+	}
+}
+
+// IgnoreContext adapts a part which can't be cancelled, for RegisterContext
+func IgnoreContext[T any, R any](part func(T) (R, error)) func(context.Context, T) (R, error) {
+	if part == nil {
+		return nil
+	}
+
+	return func(_ context.Context, in T) (R, error) {
+		return part(in)
+	}
+}
+
+// Register adds the solver for 'day'. 'part2' may be nil, for days which
+// don't have one.
+func Register[T any, R1 any, R2 any](day int, parse func(io.Reader) (T, error), part1 func(T) (R1, error), part2 func(T) (R2, error)) *Solver {
+	return RegisterContext(day, parse, IgnoreContext(part1), IgnoreContext(part2))
+}
+
+// RegisterContext is like Register, but the parts take a context, so that
+// long-running parts can give up when it's cancelled
+func RegisterContext[T any, R1 any, R2 any](day int, parse func(io.Reader) (T, error), part1 func(context.Context, T) (R1, error), part2 func(context.Context, T) (R2, error)) *Solver {
+	if _, ok := registry[day]; ok {
+		panic(fmt.Sprintf("day %d registered twice", day))
+	}
+
+	s := &Solver{
+		Day: day,
+		Parse: func(rd io.Reader) (interface{}, error) {
+			return parse(rd)
+		},
+		Parts: [2]Part{
+			wrapPart(part1),
+			wrapPart(part2),
+		},
+	}
+
+	registry[day] = s
+
+	return s
+}
+
+// AddOption adds a named option to 's'. When given, 'run' is called instead
+// of the normal parts. Values less than 'min' are an error, and never get
+// as far as 'run'.
+func AddOption[T any, R any](s *Solver, name, usage string, min int, run func(T, int) (R, error)) {
+	s.Options = append(s.Options, &Option{
+		Name:  name,
+		Usage: usage,
+		Min:   min,
+		Run: func(_ context.Context, in interface{}, value int) (interface{}, error) {
+			if value < min {
+				return nil, fmt.Errorf("--%s must be at least %d, not %d", name, min, value)
+			}
+			return run(in.(T), value)
+		},
+	})
+}
+
+// SetSlow marks 'parts' of 's' as slow
+func SetSlow(s *Solver, parts ...int) {
+	for _, n := range parts {
+		if n < 1 || n > len(s.Slow) {
+			panic(fmt.Sprintf("day %d has no part %d", s.Day, n))
+		}
+		s.Slow[n-1] = true
+	}
+}
+
+// SetDrawing sets how to draw 's', in 'format' ("png" or "gif")
+func SetDrawing[T any](s *Solver, format string, draw func(w io.Writer, in T, scale int) error) {
+	s.Drawing = &Drawing{
+		Format: format,
+		Draw: func(w io.Writer, in interface{}, scale int) error {
+			return draw(w, in.(T), scale)
+		},
+	}
+}
+
+// SetAnimation sets how to animate 's', at 'fps' frames per second unless
+// told otherwise
+func SetAnimation[T any](s *Solver, fps int, animate func(scr Screen, in T) error) {
+	s.Animation = &Animation{
+		FPS: fps,
+		Animate: func(scr Screen, in interface{}) error {
+			return animate(scr, in.(T))
+		},
+	}
+}
+
+// Get returns the solver for 'day', or nil if there isn't one
+func Get(day int) *Solver {
+	return registry[day]
+}
+
+// All returns all the registered solvers, in order of day
+func All() []*Solver {
+	var solvers []*Solver
+	for _, s := range registry {
+		solvers = append(solvers, s)
+	}
+
+	sort.Slice(solvers, func(i, j int) bool { return solvers[i].Day < solvers[j].Day })
+
+	return solvers
+}
@@ -1,62 +1,33 @@
-package main
+//go:build ignore
 
-import (
-	"bufio"
-	"fmt"
-	"os"
-	"runtime/pprof"
-)
+package dayXX
 
-func doLines(filename string, do func(line string) error) error {
-	f, err := os.Open(filename)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-
-	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if err := do(line); err != nil {
-			return err
-		}
-	}
+import (
+	"io"
 
-	if err := scanner.Err(); err != nil {
-		return err
-	}
+	"github.com/usedbytes/aoc2021/debug"
+	"github.com/usedbytes/aoc2021/input"
+)
 
-	return nil
-}
+func Parse(rd io.Reader) ([]string, error) {
+	var lines []string
 
-func run() error {
-	if err := doLines(os.Args[1], func(line string) error {
-		fmt.Println(line)
+	if err := input.DoLines(rd, func(line string) error {
+		debug.Println(line)
+		lines = append(lines, line)
 
 		return nil
 	}); err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return lines, nil
 }
 
-func main() {
-	profileEnv := os.Getenv("PROFILE")
-	if profileEnv != "" {
-		f, err := os.Create(profileEnv)
-		if err != nil {
-			fmt.Println("ERROR:", err)
-			os.Exit(1)
-		}
-
-		pprof.StartCPUProfile(f)
-		defer pprof.StopCPUProfile()
-	}
+func Part1(lines []string) (int, error) {
+	return 0, nil
+}
 
-	err := run()
-	if err != nil {
-		fmt.Println("ERROR:", err)
-		os.Exit(1)
-	}
+func Part2(lines []string) (int, error) {
+	return 0, nil
 }
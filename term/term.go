@@ -0,0 +1,149 @@
+// Package term animates text in a terminal, redrawing each frame in place
+// with ANSI escape codes.
+//
+// When the output isn't a terminal the escape codes would just be noise, so
+// frames are written one after another instead, as plain text.
+package term
+
+import (
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	clear     = "\033[2J"
+	home      = "\033[H"
+	clearLine = "\033[K"
+	clearDown = "\033[J"
+)
+
+// Screen shows a sequence of frames, each replacing the last
+type Screen struct {
+	w        io.Writer
+	tty      bool
+	interval time.Duration
+
+	// When the next frame is due
+	next    time.Time
+	started bool
+}
+
+// IsTerminal returns true if 'w' is a terminal (and not a file or a pipe)
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// New returns a screen writing to 'w', showing at most 'fps' frames per
+// second. 'fps' <= 0 means as fast as possible.
+func New(w io.Writer, fps int) *Screen {
+	s := &Screen{
+		w:   w,
+		tty: IsTerminal(w),
+	}
+
+	if fps > 0 {
+		s.interval = time.Second / time.Duration(fps)
+	}
+
+	return s
+}
+
+// TTY returns true if frames are drawn in place
+func (s *Screen) TTY() bool {
+	return s.tty
+}
+
+func (s *Screen) draw(text string) error {
+	var sb strings.Builder
+
+	if !s.tty {
+		if s.started {
+			sb.WriteString("\n")
+		}
+		sb.WriteString(text)
+		sb.WriteString("\n")
+	} else {
+		if !s.started {
+			sb.WriteString(clear)
+		}
+		sb.WriteString(home)
+		// Clear the end of each line, in case the last frame was
+		// wider, and everything below, in case it was taller
+		sb.WriteString(strings.ReplaceAll(text, "\n", clearLine+"\n"))
+		sb.WriteString(clearLine + "\n" + clearDown)
+	}
+
+	s.started = true
+
+	_, err := io.WriteString(s.w, sb.String())
+	return err
+}
+
+// Frame shows the next frame of an animation. On a terminal, it waits until
+// the frame is due, so that the animation runs at the right speed.
+func (s *Screen) Frame(text string) error {
+	if s.tty && s.interval > 0 {
+		time.Sleep(time.Until(s.next))
+		s.next = time.Now().Add(s.interval)
+	}
+
+	return s.draw(text)
+}
+
+// Line is a single line of text, redrawn in place at the cursor, for
+// progress updates in between normal output. Nothing is shown when it isn't
+// a terminal.
+type Line struct {
+	w     io.Writer
+	tty   bool
+	shown bool
+}
+
+// NewLine returns a line writing to 'w'
+func NewLine(w io.Writer) *Line {
+	return &Line{
+		w:   w,
+		tty: IsTerminal(w),
+	}
+}
+
+// TTY returns true if the line is shown
+func (l *Line) TTY() bool {
+	return l.tty
+}
+
+// Update replaces the line with 'text', which should fit on one line
+func (l *Line) Update(text string) error {
+	if !l.tty {
+		return nil
+	}
+
+	l.shown = true
+
+	_, err := io.WriteString(l.w, "\r"+text+clearLine)
+	return err
+}
+
+// Clear removes the line, leaving the cursor at the start of it
+func (l *Line) Clear() error {
+	if !l.shown {
+		return nil
+	}
+
+	l.shown = false
+
+	_, err := io.WriteString(l.w, "\r"+clearLine)
+	return err
+}
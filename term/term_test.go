@@ -0,0 +1,70 @@
+package term
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPlain(t *testing.T) {
+	var buf bytes.Buffer
+	s := New(&buf, 1000)
+
+	if s.TTY() {
+		t.Fatal("a buffer isn't a terminal")
+	}
+
+	for _, f := range []string{"ab\ncd", "ef\ngh"} {
+		if err := s.Frame(f); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := "ab\ncd\n\nef\ngh\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestTTY(t *testing.T) {
+	var buf bytes.Buffer
+	s := New(&buf, 0)
+	s.tty = true
+
+	s.Frame("ab\ncd")
+	s.Frame("e")
+
+	want := clear + home + "ab" + clearLine + "\ncd" + clearLine + "\n" + clearDown +
+		home + "e" + clearLine + "\n" + clearDown
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestIsTerminal(t *testing.T) {
+	if IsTerminal(&bytes.Buffer{}) {
+		t.Error("a buffer isn't a terminal")
+	}
+}
+
+func TestLine(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewLine(&buf)
+
+	// Nothing at all when it's not a terminal
+	l.Update("1/2")
+	l.Clear()
+	if buf.Len() != 0 {
+		t.Errorf("wrote %q to a buffer", buf.String())
+	}
+
+	l.tty = true
+	l.Update("1/2")
+	l.Update("2/2")
+	l.Clear()
+	l.Clear()
+
+	want := "\r1/2" + clearLine + "\r2/2" + clearLine + "\r" + clearLine
+	if got := buf.String(); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,116 @@
+// Package visual draws puzzles as images, using the standard image packages:
+// single PNGs, or animated GIFs with a frame for each step of a simulation.
+//
+// Everything is drawn on paletted images, because that's what GIFs need, and
+// because the puzzles only ever have a handful of distinct colours.
+package visual
+
+import (
+	"image"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"io"
+	"time"
+
+	"github.com/usedbytes/aoc2021/grid"
+)
+
+// Gradient returns a palette of 'n' colours, fading from 'from' to 'to'
+func Gradient(from, to color.RGBA, n int) color.Palette {
+	p := make(color.Palette, n)
+
+	lerp := func(a, b uint8, i int) uint8 {
+		if n == 1 {
+			return a
+		}
+		return uint8(int(a) + (int(b)-int(a))*i/(n-1))
+	}
+
+	for i := range p {
+		p[i] = color.RGBA{
+			lerp(from.R, to.R, i),
+			lerp(from.G, to.G, i),
+			lerp(from.B, to.B, i),
+			0xff,
+		}
+	}
+
+	return p
+}
+
+// Canvas returns a blank image big enough for a grid from 'min' to 'max',
+// with each cell 'scale' pixels square. It's filled with palette[0].
+func Canvas(min, max grid.Point, scale int, palette color.Palette) *image.Paletted {
+	r := image.Rect(0, 0, (max.X-min.X+1)*scale, (max.Y-min.Y+1)*scale)
+	return image.NewPaletted(r, palette)
+}
+
+// Cell fills the cell at 'p' (relative to the canvas' top-left cell 'min')
+// with the palette colour 'c'
+func Cell(img *image.Paletted, min, p grid.Point, scale int, c uint8) {
+	x0, y0 := (p.X-min.X)*scale, (p.Y-min.Y)*scale
+	for y := y0; y < y0+scale; y++ {
+		for x := x0; x < x0+scale; x++ {
+			img.SetColorIndex(x, y, c)
+		}
+	}
+}
+
+// Region draws the cells of 'g' from 'min' to 'max', which can be outside of
+// its bounds, choosing each one's palette colour with 'colour'
+func Region[T comparable](g *grid.Grid[T], min, max grid.Point, scale int, palette color.Palette, colour func(v T) uint8) *image.Paletted {
+	img := Canvas(min, max, scale, palette)
+
+	for y := min.Y; y <= max.Y; y++ {
+		for x := min.X; x <= max.X; x++ {
+			p := grid.Point{X: x, Y: y}
+			Cell(img, min, p, scale, colour(g.Get(p)))
+		}
+	}
+
+	return img
+}
+
+// Grid draws the whole of 'g', like Region
+func Grid[T comparable](g *grid.Grid[T], scale int, palette color.Palette, colour func(v T) uint8) *image.Paletted {
+	min, max := g.Bounds()
+	return Region(g, min, max, scale, palette, colour)
+}
+
+// WritePNG writes 'img' to 'w' as a PNG
+func WritePNG(w io.Writer, img image.Image) error {
+	return png.Encode(w, img)
+}
+
+// Animation is a sequence of frames, to be written as an animated GIF. All
+// of the frames should be the same size.
+type Animation struct {
+	g gif.GIF
+}
+
+// Add adds a frame, shown for 'delay' (GIFs only have 10ms resolution)
+func (a *Animation) Add(img *image.Paletted, delay time.Duration) {
+	a.g.Image = append(a.g.Image, img)
+	a.g.Delay = append(a.g.Delay, int(delay/(10*time.Millisecond)))
+}
+
+// Len returns the number of frames
+func (a *Animation) Len() int {
+	return len(a.g.Image)
+}
+
+// WriteGIF writes the animation to 'w', looping forever
+func (a *Animation) WriteGIF(w io.Writer) error {
+	return gif.EncodeAll(w, &a.g)
+}
+
+// Every returns how many steps to skip between frames, so that an animation
+// of 'steps' steps has at most 'frames' frames
+func Every(steps, frames int) int {
+	if steps <= frames {
+		return 1
+	}
+
+	return (steps + frames - 1) / frames
+}
@@ -0,0 +1,101 @@
+package visual
+
+import (
+	"bytes"
+	"image/color"
+	"image/gif"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/usedbytes/aoc2021/grid"
+)
+
+func TestGradient(t *testing.T) {
+	p := Gradient(color.RGBA{0, 0, 0, 0xff}, color.RGBA{200, 100, 0, 0xff}, 3)
+
+	want := []color.RGBA{{0, 0, 0, 0xff}, {100, 50, 0, 0xff}, {200, 100, 0, 0xff}}
+	for i, c := range want {
+		if p[i] != c {
+			t.Errorf("colour %d is %v, want %v", i, p[i], c)
+		}
+	}
+}
+
+func TestGrid(t *testing.T) {
+	g := grid.NewDense(3, 2, 0)
+	g.Set(grid.Point{X: 2, Y: 1}, 1)
+
+	palette := color.Palette{color.Black, color.White}
+	img := Grid(g, 2, palette, func(v int) uint8 { return uint8(v) })
+
+	if b := img.Bounds(); b.Dx() != 6 || b.Dy() != 4 {
+		t.Fatalf("image is %dx%d, want 6x4", b.Dx(), b.Dy())
+	}
+
+	for y := 0; y < 4; y++ {
+		for x := 0; x < 6; x++ {
+			want := uint8(0)
+			if x >= 4 && y >= 2 {
+				want = 1
+			}
+			if got := img.ColorIndexAt(x, y); got != want {
+				t.Errorf("pixel %d,%d is %d, want %d", x, y, got, want)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := WritePNG(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := png.Decode(&buf); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAnimation(t *testing.T) {
+	palette := color.Palette{color.Black, color.White}
+
+	var a Animation
+	for i := 0; i < 3; i++ {
+		g := grid.NewDense(4, 4, 0)
+		g.Set(grid.Point{X: i, Y: i}, 1)
+		a.Add(Grid(g, 1, palette, func(v int) uint8 { return uint8(v) }), 50*time.Millisecond)
+	}
+
+	var buf bytes.Buffer
+	if err := a.WriteGIF(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	g, err := gif.DecodeAll(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(g.Image) != 3 {
+		t.Fatalf("got %d frames, want 3", len(g.Image))
+	}
+
+	for i, d := range g.Delay {
+		if d != 5 {
+			t.Errorf("frame %d has delay %d, want 5", i, d)
+		}
+	}
+}
+
+func TestEvery(t *testing.T) {
+	for _, tc := range []struct {
+		steps, frames, want int
+	}{
+		{10, 100, 1},
+		{100, 100, 1},
+		{101, 100, 2},
+		{1000, 100, 10},
+	} {
+		if got := Every(tc.steps, tc.frames); got != tc.want {
+			t.Errorf("Every(%d, %d) = %d, want %d", tc.steps, tc.frames, got, tc.want)
+		}
+	}
+}